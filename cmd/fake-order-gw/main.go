@@ -0,0 +1,112 @@
+// Command fake-order-gw serves order-gw's OrderService for local development and contract tests.
+// Behavior (latency, gRPC error codes, success ratio, lost status events) comes from flags or a YAML script (see fakegw.Script);
+// send SIGHUP to reload the script. Status changes go to Kafka, as order-gw does, or to an in-memory bus
+// that only logs them.
+//
+//	go run ./cmd/fake-order-gw -success-ratio 0.8 -latency 100ms
+//	go run ./cmd/fake-order-gw -script deployments/fake-order-gw.yaml -bus memory
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/aq2208/gorder-api/internal/adapter/grpc/fakegw"
+	"github.com/aq2208/gorder-api/internal/adapter/kafka"
+	"github.com/aq2208/gorder-api/internal/adapter/memory"
+	gwpb "github.com/aq2208/gorder-api/internal/generated"
+	"github.com/aq2208/gorder-api/internal/usecase"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+func main() {
+	def := fakegw.DefaultScript().Default
+	addr := flag.String("addr", ":50051", "gRPC listen address")
+	scriptPath := flag.String("script", "", "YAML behavior script (overrides the behavior flags)")
+	bus := flag.String("bus", "kafka", "where status changes go: kafka | memory")
+	brokers := flag.String("brokers", "localhost:9094", "Kafka brokers (comma separated)")
+	topic := flag.String("topic", "order.status.changed", "Kafka topic for status changes")
+	flag.DurationVar(&def.Latency, "latency", def.Latency, "delay before answering CreateOrder")
+	flag.DurationVar(&def.Jitter, "jitter", def.Jitter, "random extra delay [0, jitter)")
+	flag.StringVar(&def.ErrorCode, "error-code", def.ErrorCode, "gRPC code to fail with, e.g. UNAVAILABLE")
+	flag.Float64Var(&def.ErrorRatio, "error-ratio", def.ErrorRatio, "share of calls failing with -error-code")
+	flag.Float64Var(&def.SuccessRatio, "success-ratio", def.SuccessRatio, "share of accepted orders reported CONFIRMED")
+	flag.DurationVar(&def.StatusDelay, "status-delay", def.StatusDelay, "delay before publishing the status change")
+	flag.Float64Var(&def.DropStatus, "drop-status-ratio", def.DropStatus, "share of status changes never published")
+	flag.Parse()
+
+	loadScript := func() (fakegw.Script, error) {
+		if *scriptPath == "" {
+			s := fakegw.Script{Default: def}
+			return s, s.Validate()
+		}
+		return fakegw.LoadScript(*scriptPath)
+	}
+	script, err := loadScript()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var publisher fakegw.StatusPublisher
+	switch *bus {
+	case "kafka":
+		p, err := kafka.NewStatusProducer(strings.Split(*brokers, ","), *topic)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer p.Close()
+		publisher = p
+	case "memory":
+		b := memory.NewStatusBus()
+		go b.Run(ctx, func(_ context.Context, ev usecase.OrderStatusChangedMsg) error {
+			log.Printf("status change: order=%s status=%s", ev.OrderID, ev.Status)
+			return nil
+		})
+		publisher = b
+	default:
+		log.Fatalf("unknown -bus %q (kafka | memory)", *bus)
+	}
+
+	srv := fakegw.NewServer(script, publisher)
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			s, err := loadScript()
+			if err != nil {
+				log.Printf("script reload failed, keeping previous: %v", err)
+				continue
+			}
+			srv.SetScript(s)
+			log.Printf("script reloaded")
+		}
+	}()
+
+	lis, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	gs := grpc.NewServer()
+	gwpb.RegisterOrderServiceServer(gs, srv)
+	reflection.Register(gs)
+	go func() {
+		<-ctx.Done()
+		gs.GracefulStop()
+	}()
+
+	log.Printf("fake-order-gw listening on %s (bus=%s)", *addr, *bus)
+	if err := gs.Serve(lis); err != nil {
+		log.Fatal(err)
+	}
+}
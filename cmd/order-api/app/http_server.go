@@ -1 +1,63 @@
 package app
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aq2208/gorder-api/configs"
+	"github.com/aq2208/gorder-api/internal/security"
+)
+
+// HTTPServer wraps http.Server with the optional TLS/mTLS setup from cfg.HTTP.
+type HTTPServer struct {
+	*http.Server
+	tls *security.CertReloader // nil => plain HTTP
+}
+
+// NewHTTPServer builds the API listener. When http.tls.cert_file is set it serves HTTPS, optionally
+// verifying client certificates against http.tls.client_ca_file; cert/CA files are hot reloaded.
+func NewHTTPServer(cfg configs.Config, h http.Handler) (*HTTPServer, func(), error) {
+	srv := &HTTPServer{Server: &http.Server{
+		Addr:         cfg.App.HTTPAddr,
+		Handler:      h,
+		ReadTimeout:  cfg.HTTP.ReadTimeout,
+		WriteTimeout: cfg.HTTP.WriteTimeout,
+		IdleTimeout:  cfg.HTTP.IdleTimeout,
+	}}
+
+	tc := cfg.HTTP.TLS
+	if tc.CertFile == "" {
+		return srv, func() {}, nil
+	}
+	clientAuth, err := security.ParseClientAuth(tc.ClientAuth)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := security.NewCertReloader(tc.CertFile, tc.KeyFile, tc.ClientCAFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := r.Watch(); err != nil {
+		return nil, nil, err
+	}
+	srv.tls = r
+	srv.TLSConfig = r.ServerTLSConfig(clientAuth, "h2", "http/1.1")
+	return srv, func() { _ = r.Close() }, nil
+}
+
+// TLS reports whether the server listens with TLS.
+func (s *HTTPServer) TLS() bool { return s.tls != nil }
+
+// ListenAndServe serves HTTP or HTTPS depending on config; http.ErrServerClosed is not an error.
+func (s *HTTPServer) ListenAndServe() error {
+	var err error
+	if s.tls != nil {
+		err = s.Server.ListenAndServeTLS("", "") // certificates come from TLSConfig
+	} else {
+		err = s.Server.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"time"
+
+	"github.com/aq2208/gorder-api/configs"
+	"github.com/aq2208/gorder-api/internal/adapter/http/middleware"
+	"github.com/aq2208/gorder-api/internal/adapter/queue"
+	"github.com/aq2208/gorder-api/internal/logging"
+)
+
+// TTLSetter is implemented by the Redis and in-memory cache/idempotency adapters.
+type TTLSetter interface {
+	SetTTL(ttl time.Duration)
+}
+
+// Reloadable is the set of components of a process that accept live config changes; nil ones are skipped.
+type Reloadable struct {
+	Cache     TTLSetter
+	Idem      TTLSetter
+	RateLimit *middleware.RateLimiter
+	Queue     *queue.Router
+}
+
+// ApplyConfig pushes the live-reloadable knobs of next into the components.
+// Subscribe it to a configs.Watcher; fields not handled here take effect on restart only.
+func (r Reloadable) ApplyConfig(prev, next configs.Config) {
+	l := logging.New("config")
+
+	if next.App.LogLevel != prev.App.LogLevel {
+		if err := logging.SetLevel(next.App.LogLevel); err != nil {
+			l.Error("apply log level", "level", next.App.LogLevel, "err", err)
+		}
+	}
+	if r.Cache != nil && next.Cache.TTL != prev.Cache.TTL {
+		r.Cache.SetTTL(next.Cache.TTL)
+	}
+	if r.Idem != nil && next.Idempotency.TTL != prev.Idempotency.TTL {
+		r.Idem.SetTTL(next.Idempotency.TTL)
+	}
+	if r.RateLimit != nil && next.HTTP.RateLimit != prev.HTTP.RateLimit {
+		r.RateLimit.SetLimit(next.HTTP.RateLimit.RPS, next.HTTP.RateLimit.Burst)
+	}
+	if r.Queue != nil && next.Rabbit.Prefetch != prev.Rabbit.Prefetch {
+		if err := r.Queue.SetPrefetch(next.Rabbit.Prefetch); err != nil {
+			l.Error("apply rabbitmq prefetch", "prefetch", next.Rabbit.Prefetch, "err", err)
+		}
+	}
+
+	l.Info("runtime config applied",
+		"log_level", next.App.LogLevel,
+		"cache_ttl", next.Cache.TTL,
+		"idempotency_ttl", next.Idempotency.TTL,
+		"rate_limit_rps", next.HTTP.RateLimit.RPS,
+		"prefetch", next.Rabbit.Prefetch)
+}
+
+// Watch starts hot reload of configs/<env>.yaml into r. A failure to watch only disables reloading.
+func Watch(env string, cfg configs.Config, r Reloadable) (stop func()) {
+	w := configs.NewWatcher("configs", env, cfg)
+	w.Subscribe(r.ApplyConfig)
+	if err := w.Start(); err != nil {
+		logging.New("config").Error("config watch disabled", "err", err)
+	}
+	return w.Stop
+}
@@ -1,32 +1,167 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/aq2208/gorder-api/cmd/order-api/app"
-	"github.com/aq2208/gorder-api/configs"
+	"github.com/aq2208/gorder-api/internal"
+	"github.com/aq2208/gorder-api/internal/adapter/grpcapi"
+	"github.com/gin-gonic/gin"
 )
 
 func main() {
-	env := os.Getenv("APP_ENV") // dev | staging | prod
-	if env == "" {
-		env = "dev"
+	// subcommands
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "secret":
+			os.Exit(runSecret(os.Args[2:]))
+		case "config":
+			os.Exit(runConfig(os.Args[2:]))
+		case "reconcile":
+			os.Exit(runReconcile(os.Args[2:]))
+		case "migrate":
+			os.Exit(runMigrate(os.Args[2:]))
+		}
 	}
 
-	cfg, err := configs.Load("configs", env)
-	if err != nil {
+	// The order worker and status consumer normally run as cmd/order-worker and cmd/order-status-consumer;
+	// --combined runs them in this process as well (single-binary deployments, local dev).
+	// APP_ENV=local-mem runs everything in-process on in-memory adapters, no infrastructure needed.
+	combined := flag.Bool("combined", false, "also run the order worker and the order status consumer")
+	flag.Parse()
+
+	if err := serve(*combined); err != nil {
 		log.Fatal(err)
 	}
+}
 
-	app, cleanup, err := app.InitWithConfig(cfg)
+// shutdownTimeout bounds how long in-flight HTTP requests and gRPC calls get to finish on SIGINT/SIGTERM.
+const shutdownTimeout = 10 * time.Second
+
+// serve runs the API (and with combined, the worker and status consumer) until SIGINT/SIGTERM or a
+// server fails, then stops the servers and background loops before the graph cleanup closes connections.
+func serve(combined bool) error {
+	env := app.Env()
+	cfg, err := app.LoadConfig(env)
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+	if env != app.EnvLocalMem && cfg.MySQL.AutoMigrate {
+		if err := autoMigrate(cfg); err != nil {
+			return fmt.Errorf("auto-migrate: %w", err)
+		}
 	}
-	defer cleanup()
 
-	log.Printf("order-api (%s) listening on %s", env, cfg.App.HTTPAddr)
-	if err := app.Router.Run(cfg.App.HTTPAddr); err != nil {
-		log.Fatal(err)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var (
+		router  *gin.Engine
+		grpcAPI *grpcapi.Server
+		reload  app.Reloadable
+		bg      sync.WaitGroup // loops that must stop before the graph cleanup
+	)
+	errc := make(chan error, 3)
+	goBG := func(run func(context.Context)) {
+		bg.Add(1)
+		go func() {
+			defer bg.Done()
+			run(ctx)
+		}()
+	}
+	switch {
+	case env == app.EnvLocalMem:
+		m, cleanup, err := internal.InitializeLocalMem(cfg)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		m.Start(ctx)
+		router, grpcAPI = m.Router, m.GRPC
+		reload = app.Reloadable{Cache: m.Cache, Idem: m.Idem, RateLimit: m.RateLimit}
+	case combined:
+		g, cleanup, err := internal.InitializeCombined(cfg)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		if err := g.Worker.Router.Start(); err != nil {
+			return err
+		}
+		goBG(g.Worker.Reaper.Run)
+		goBG(g.Worker.Webhooks.Run)
+		goBG(g.API.Jobs.Run)
+		goBG(func(ctx context.Context) {
+			if err := g.Status.Consumer.Start(ctx); err != nil && ctx.Err() == nil {
+				errc <- fmt.Errorf("status consumer: %w", err)
+			}
+		})
+		router, grpcAPI = g.API.Router, g.API.GRPC
+		reload = app.Reloadable{Cache: g.API.Cache, Idem: g.API.Idem, RateLimit: g.API.RateLimit, Queue: g.Worker.Router}
+	default:
+		a, cleanup, err := internal.InitializeAPI(cfg)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		goBG(a.Jobs.Run)
+		router, grpcAPI = a.Router, a.GRPC
+		reload = app.Reloadable{Cache: a.Cache, Idem: a.Idem, RateLimit: a.RateLimit}
+	}
+	// deferred calls run last-in first-out: loops stop here, before the graph cleanup above
+	defer bg.Wait()
+	defer stop()
+
+	// hot reload of operational knobs (log level, TTLs, rate limit, prefetch)
+	defer app.Watch(env, cfg, reload)()
+
+	srv, closeTLS, err := app.NewHTTPServer(cfg, router)
+	if err != nil {
+		return err
+	}
+	defer closeTLS()
+
+	// gRPC API on its own port
+	if cfg.GrpcAPI.Enabled {
+		go func() {
+			log.Printf("gRPC API listening on %s (tls=%v)", grpcAPI.Addr(), grpcAPI.TLS())
+			if err := grpcAPI.ListenAndServe(); err != nil {
+				errc <- fmt.Errorf("grpc api: %w", err)
+			}
+		}()
+	}
+	go func() {
+		log.Printf("%s (%s) listening on %s (tls=%v, combined=%v)", cfg.App.Name, env, cfg.App.HTTPAddr, srv.TLS(), combined)
+		if err := srv.ListenAndServe(); err != nil {
+			errc <- fmt.Errorf("http: %w", err)
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		log.Printf("%s: shutting down", cfg.App.Name)
+	case err = <-errc:
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if serr := srv.Shutdown(shutdownCtx); serr != nil {
+		err = errors.Join(err, fmt.Errorf("http shutdown: %w", serr))
+	}
+	if cfg.GrpcAPI.Enabled {
+		grpcAPI.Shutdown(shutdownTimeout)
 	}
+	return err
 }
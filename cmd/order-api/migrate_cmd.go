@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aq2208/gorder-api/cmd/order-api/app"
+	"github.com/aq2208/gorder-api/configs"
+	"github.com/aq2208/gorder-api/internal/adapter/repo"
+)
+
+const migrateUsage = `usage:
+  order-api migrate up
+  order-api migrate down [--steps 1]
+  order-api migrate status
+
+Applies (up) or rolls back (down) the schema migrations embedded in this binary and records them in
+the schema_migrations table. A MySQL advisory lock serializes runs, so replicas started together
+apply each migration once; a run waits up to mysql.migrate_lock_timeout for another to finish.
+Migration 1 is the orders table of the former deployments/migrations.sql and adopts a database
+created from it; it cannot be rolled back.
+status lists applied and pending versions and exits 3 while any are pending.
+With mysql.auto_migrate (on in dev) order-api runs "up" itself before serving.`
+
+// runMigrate implements the "migrate" subcommand.
+func runMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+	fs := flag.NewFlagSet("migrate "+args[0], flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprintln(os.Stderr, migrateUsage) }
+	steps := fs.Int("steps", 1, "number of migrations to roll back (down)")
+	if err := fs.Parse(args[1:]); err != nil || fs.NArg() > 0 {
+		if err == nil {
+			fs.Usage()
+		}
+		return 2
+	}
+	switch {
+	case args[0] != "up" && args[0] != "down" && args[0] != "status":
+		fs.Usage()
+		return 2
+	case args[0] == "down" && *steps < 1:
+		fmt.Fprintln(os.Stderr, "--steps must be at least 1")
+		return 2
+	}
+
+	env := app.Env()
+	if env == app.EnvLocalMem {
+		fmt.Fprintln(os.Stderr, "migrate needs MySQL; not available with APP_ENV=local-mem")
+		return 1
+	}
+	cfg, err := app.LoadConfig(env)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	db, closeDB, err := repo.NewDB(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer closeDB()
+	m, err := repo.NewMigrator(db, cfg.MySQL.MigrateLockTimeout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var done []repo.Migration
+	switch args[0] {
+	case "status":
+		return printMigrationStatus(ctx, m)
+	case "up":
+		done, err = m.Up(ctx)
+	case "down":
+		done, err = m.Down(ctx, *steps)
+	}
+	for _, mig := range done {
+		fmt.Printf("%s %04d_%s\n", args[0], mig.Version, mig.Name)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "migrate %s: %v\n", args[0], err)
+		return 1
+	}
+	if len(done) == 0 {
+		fmt.Println("nothing to do")
+	}
+	return 0
+}
+
+func printMigrationStatus(ctx context.Context, m *repo.Migrator) int {
+	rows, err := m.Status(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "migrate status: %v\n", err)
+		return 1
+	}
+	pending := 0
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED")
+	for _, r := range rows {
+		applied := "pending"
+		switch {
+		case r.Unknown:
+			applied = r.AppliedAt.Format(time.RFC3339) + " (not in this binary)"
+		case r.Applied():
+			applied = r.AppliedAt.Format(time.RFC3339)
+		default:
+			pending++
+		}
+		fmt.Fprintf(tw, "%04d\t%s\t%s\n", r.Version, r.Name, applied)
+	}
+	_ = tw.Flush()
+	if pending > 0 {
+		return 3
+	}
+	return 0
+}
+
+// autoMigrate applies pending migrations before the graph is built (mysql.auto_migrate).
+func autoMigrate(cfg configs.Config) error {
+	db, closeDB, err := repo.NewDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+	m, err := repo.NewMigrator(db, cfg.MySQL.MigrateLockTimeout)
+	if err != nil {
+		return err
+	}
+	done, err := m.Up(context.Background())
+	for _, mig := range done {
+		log.Printf("migrated up %04d_%s", mig.Version, mig.Name)
+	}
+	return err
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/aq2208/gorder-api/cmd/order-api/app"
+	"github.com/aq2208/gorder-api/internal"
+)
+
+const reconcileUsage = `usage:
+  order-api reconcile [--window 24h] [--settle 5m] [--fix]
+
+Compares orders created within the window with order-gw's records (ListOrders) and writes every
+difference to the order_discrepancies table: status_mismatch, amount_mismatch, missing_at_gateway,
+unknown_gateway_status (a status order-gw reports that has no equivalent here). With --fix (or
+reconcile.auto_correct) orders still PROCESSING that order-gw already finalized are moved to
+order-gw's status; all other differences are only reported. Defaults come from the
+reconcile section of the config. Exits 1 on error, 3 when discrepancies were found.`
+
+// runReconcile implements the "reconcile" subcommand (one pass; schedule it externally, e.g. a CronJob).
+func runReconcile(args []string) int {
+	env := app.Env()
+	if env == app.EnvLocalMem {
+		fmt.Fprintln(os.Stderr, "reconcile needs MySQL and order-gw; not available with APP_ENV=local-mem")
+		return 1
+	}
+	cfg, err := app.LoadConfig(env)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	fs := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprintln(os.Stderr, reconcileUsage) }
+	fs.DurationVar(&cfg.Reconcile.Window, "window", cfg.Reconcile.Window, "compare orders created within this window")
+	fs.DurationVar(&cfg.Reconcile.Settle, "settle", cfg.Reconcile.Settle, "skip orders updated more recently than this")
+	fs.BoolVar(&cfg.Reconcile.AutoCorrect, "fix", cfg.Reconcile.AutoCorrect, "auto-correct safe discrepancies")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	g, cleanup, err := internal.InitializeReconciler(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer cleanup()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	start := time.Now()
+	rep, err := g.Job.Execute(ctx)
+	fmt.Printf("run %s: checked=%d discrepancies=%d corrected=%d (%s)\n",
+		rep.RunID, rep.Checked, rep.Discrepancies, rep.Corrected, time.Since(start).Round(time.Millisecond))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "reconcile: %v\n", err)
+		return 1
+	}
+	if rep.Discrepancies > rep.Corrected {
+		return 3
+	}
+	return 0
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aq2208/gorder-api/configs"
+)
+
+const secretUsage = `usage:
+  order-api secret keygen              print a new base64url sealing key
+  order-api secret seal <key.path>     read plaintext from stdin, print the ENC[...] value for <key.path>
+  order-api secret open <key.path>     read an ENC[...] value from stdin, print the plaintext
+
+The sealing key is read from $GORDER_SECRETS_KEY_FILE or $GORDER_SECRETS_KEY.`
+
+// runSecret implements the "secret" subcommand used to maintain sealed:// secret files.
+func runSecret(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, secretUsage)
+		return 2
+	}
+	switch args[0] {
+	case "keygen":
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Println(base64.RawURLEncoding.EncodeToString(key))
+		return 0
+	case "seal", "open":
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, secretUsage)
+			return 2
+		}
+		key, err := configs.SecretsKey()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		in, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		value := strings.TrimRight(string(in), "\r\n")
+		var out string
+		if args[0] == "seal" {
+			out, err = configs.SealValue(key, args[1], value)
+		} else {
+			out, err = configs.OpenSealedValue(key, args[1], value)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Println(out)
+		return 0
+	}
+	fmt.Fprintln(os.Stderr, secretUsage)
+	return 2
+}
@@ -0,0 +1,40 @@
+// Command order-status-consumer consumes order status changes from Kafka and applies them to MySQL
+// (and the Redis cache). Replicas share the work through the Kafka consumer group.
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/aq2208/gorder-api/cmd/order-api/app"
+	"github.com/aq2208/gorder-api/internal"
+)
+
+func main() {
+	env := app.Env()
+	cfg, err := app.LoadConfig(env)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	s, cleanup, err := internal.InitializeStatusConsumer(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer cleanup()
+
+	// hot reload of log level and cache TTL
+	defer app.Watch(env, cfg, app.Reloadable{Cache: s.Cache})()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("order-status-consumer (%s) consuming %s as %s", env, cfg.Kafka.Topic, cfg.Kafka.GroupID)
+	if err := s.Consumer.Start(ctx); err != nil && ctx.Err() == nil {
+		log.Fatal(err)
+	}
+	log.Printf("order-status-consumer: shutting down")
+}
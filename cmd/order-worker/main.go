@@ -0,0 +1,58 @@
+// Command order-worker consumes order.created commands from RabbitMQ and submits them to order-gw.
+// It scales independently of the API; run as many replicas as the queue needs. Each replica also runs
+// the stuck-order reaper, but only the one holding the Redis lease scans, and sends due webhook
+// deliveries (claimed per row, so all replicas share the work).
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/aq2208/gorder-api/cmd/order-api/app"
+	"github.com/aq2208/gorder-api/internal"
+)
+
+func main() {
+	env := app.Env()
+	cfg, err := app.LoadConfig(env)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	w, cleanup, err := internal.InitializeOrderWorker(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer cleanup()
+
+	if err := w.Router.Start(); err != nil {
+		log.Fatal(err)
+	}
+
+	// hot reload of log level and prefetch
+	defer app.Watch(env, cfg, app.Reloadable{Queue: w.Router})()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	reaped := make(chan struct{})
+	go func() {
+		defer close(reaped)
+		w.Reaper.Run(ctx)
+	}()
+	delivered := make(chan struct{})
+	go func() {
+		defer close(delivered)
+		w.Webhooks.Run(ctx)
+	}()
+
+	log.Printf("order-worker (%s) consuming %s (prefetch=%d)", env, cfg.Rabbit.Queue, cfg.Rabbit.Prefetch)
+	<-ctx.Done()
+	log.Printf("order-worker: shutting down")
+	<-reaped    // lease released before connections close
+	<-delivered // delivery loop stopped before connections close
+
+}
@@ -1,6 +1,7 @@
 package configs
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -11,7 +12,12 @@ import (
 	"github.com/knadh/koanf/v2"
 )
 
+// Config is the full application config. Every key in the YAML files and ORDERAPI_ env overlay must map
+// to a field here (see checkUnknownKeys). Fields tagged secret are masked by Redacted.
 type Config struct {
+	// Env is the environment the config was loaded for (configs/<env>.yaml); not a config key.
+	Env string `koanf:"-"`
+
 	App struct {
 		Name     string `koanf:"name"`
 		HTTPAddr string `koanf:"http_addr"`
@@ -22,18 +28,42 @@ type Config struct {
 		ReadTimeout  time.Duration `koanf:"read_timeout"`
 		WriteTimeout time.Duration `koanf:"write_timeout"`
 		IdleTimeout  time.Duration `koanf:"idle_timeout"`
+		// Per-client request rate limit on /v1 (rps <= 0 disables); reloadable at runtime
+		RateLimit struct {
+			RPS   float64 `koanf:"rps"`
+			Burst int     `koanf:"burst"`
+		} `koanf:"rate_limit"`
+		// Cache-Control on GET /v1/orders/:id. CONFIRMED and FAILED orders no longer change; anything else
+		// (a CANCELLED order can still be confirmed by order-gw) gets pending_max_age, 0 meaning clients
+		// revalidate every time, which a matching If-None-Match answers with a bodiless 304.
+		Caching struct {
+			FinalMaxAge   time.Duration `koanf:"final_max_age"`
+			PendingMaxAge time.Duration `koanf:"pending_max_age"`
+		} `koanf:"caching"`
+		// HTTPS listener; plain HTTP when cert_file is empty. Files are reloaded on change.
+		TLS struct {
+			CertFile     string `koanf:"cert_file"`
+			KeyFile      string `koanf:"key_file"`
+			ClientCAFile string `koanf:"client_ca_file"` // CA for client certificates (mTLS)
+			ClientAuth   string `koanf:"client_auth"`    // none | request | verify_if_given | require
+		} `koanf:"tls"`
 	} `koanf:"http"`
 
 	MySQL struct {
-		DSN             string        `koanf:"dsn"`
+		DSN             string        `koanf:"dsn" secret:"userinfo"`
 		MaxOpenConns    int           `koanf:"max_open_conns"`
 		MaxIdleConns    int           `koanf:"max_idle_conns"`
 		ConnMaxLifetime time.Duration `koanf:"conn_max_lifetime"`
+		// AutoMigrate applies pending schema migrations when order-api starts (dev); elsewhere run
+		// `order-api migrate up` as a deploy step. MigrateLockTimeout bounds the wait for another
+		// replica's run.
+		AutoMigrate        bool          `koanf:"auto_migrate"`
+		MigrateLockTimeout time.Duration `koanf:"migrate_lock_timeout"`
 	} `koanf:"mysql"`
 
 	Redis struct {
 		Addr     string `koanf:"addr"`
-		Password string `koanf:"password"`
+		Password string `koanf:"password" secret:"true"`
 	} `koanf:"redis"`
 
 	Idempotency struct {
@@ -44,50 +74,155 @@ type Config struct {
 		TTL time.Duration `koanf:"ttl"`
 	} `koanf:"cache"`
 
+	// POST /v1/orders:batch
+	Batch struct {
+		MaxItems int    `koanf:"max_items"`
+		Mode     string `koanf:"mode"` // atomic | per_item; used when the request does not choose
+	} `koanf:"batch"`
+
 	Rabbit struct {
-		URL        string `koanf:"url"`
-		Exchange   string `koanf:"exchange"`
-		RoutingKey string `koanf:"routing_key"`
+		URL        string `koanf:"url" secret:"userinfo"`
+		Exchange   string `koanf:"exchange"`    // topic exchange order.created commands are published to
+		RoutingKey string `koanf:"routing_key"` // routing key of order.created commands
+		Queue      string `koanf:"queue"`       // queue bound to exchange/routing_key and consumed by the worker
+		Prefetch   int    `koanf:"prefetch"`    // consumer prefetch; reloadable at runtime
 	} `koanf:"rabbitmq"`
 
+	// Kafka consumer for order status changes published by order-gw
 	Kafka struct {
-		Brokers     []string `koanf:"brokers"`
-		TopicEvents string   `koanf:"topic_events"`
+		Brokers []string `koanf:"brokers"`
+		Topic   string   `koanf:"topic"`
+		GroupID string   `koanf:"group_id"`
 	} `koanf:"kafka"`
 
+	// Order status push: SSE /v1/orders/:id/events and the ?wait= long-poll on GET /v1/orders/:id
+	Events struct {
+		Heartbeat time.Duration `koanf:"heartbeat"` // SSE keep-alive comment interval
+		MaxWait   time.Duration `koanf:"max_wait"`  // upper bound for ?wait=
+		Retention time.Duration `koanf:"retention"` // per-order history kept for Last-Event-ID resume
+	} `koanf:"events"`
+
+	// Stuck-order reaper, run by the order worker; only the replica holding the lease scans
+	Reaper struct {
+		Enabled     bool          `koanf:"enabled"`
+		Interval    time.Duration `koanf:"interval"`     // time between scans
+		SLA         time.Duration `koanf:"sla"`          // PROCESSING without an update for longer than this is stuck
+		MaxAttempts int           `koanf:"max_attempts"` // re-dispatches before the order is moved to FAILED
+		BatchSize   int           `koanf:"batch_size"`   // orders handled per scan
+		LeaseTTL    time.Duration `koanf:"lease_ttl"`    // leader lease; must be longer than interval
+	} `koanf:"reaper"`
+
+	// Outbound webhooks; deliveries are sent by every order worker replica (rows are claimed in MySQL)
+	Webhooks struct {
+		Enabled     bool          `koanf:"enabled"`
+		Interval    time.Duration `koanf:"interval"`     // poll for due deliveries
+		BatchSize   int           `koanf:"batch_size"`   // deliveries sent per poll
+		Timeout     time.Duration `koanf:"timeout"`      // per POST
+		MaxAttempts int           `koanf:"max_attempts"` // attempts before a delivery is failed
+		BackoffBase time.Duration `koanf:"backoff_base"` // delay after the first failure, doubled per attempt
+		BackoffMax  time.Duration `koanf:"backoff_max"`
+		ClaimTTL    time.Duration `koanf:"claim_ttl"`  // claimed deliveries are hidden from other workers; > batch_size * timeout
+		AllowHTTP   bool          `koanf:"allow_http"` // accept http:// subscription URLs (local development only)
+		// AllowPrivate accepts IP-literal subscription URLs and delivers to loopback, private and
+		// link-local addresses (local development only); otherwise those are refused to prevent SSRF.
+		AllowPrivate bool `koanf:"allow_private"`
+	} `koanf:"webhooks"`
+
+	// Bulk import/export jobs, run by the API replicas; uploads and results are files under dir
+	Jobs struct {
+		Enabled        bool          `koanf:"enabled"`          // run queued jobs in this process
+		Dir            string        `koanf:"dir"`              // shared by all API replicas (e.g. one volume)
+		Interval       time.Duration `koanf:"interval"`         // poll for queued jobs
+		ClaimTTL       time.Duration `koanf:"claim_ttl"`        // a running job not saved for this long is taken over
+		MaxUploadBytes int64         `koanf:"max_upload_bytes"` // import file size limit
+		PageSize       int           `koanf:"page_size"`        // rows per export query (and Parquet row group)
+	} `koanf:"jobs"`
+
+	// Reconciliation against order-gw (`order-api reconcile`, e.g. from a CronJob)
+	Reconcile struct {
+		Window      time.Duration `koanf:"window"`       // orders created this far back are compared
+		Settle      time.Duration `koanf:"settle"`       // orders updated more recently are skipped (still in flight)
+		PageSize    int           `koanf:"page_size"`    // orders per ListOrders call
+		AutoCorrect bool          `koanf:"auto_correct"` // fix safe cases (PROCESSING here, final at order-gw)
+	} `koanf:"reconcile"`
+
 	Security struct {
-		JWTSecret string        `koanf:"jwt_secret"`
+		JWTSecret string        `koanf:"jwt_secret" secret:"true"`
 		Issuer    string        `koanf:"issuer"`
 		Audience  string        `koanf:"audience"`
-		TTL       time.Duration `koanf:"ttl"`
+		TTL       time.Duration `koanf:"ttl"` // access token lifetime, e.g. 30m
 	} `koanf:"security"`
 
 	CryptoConfig struct {
 		KeyID     string `koanf:"key_id"`
-		AES256B64 string `koanf:"aes256_b64url"`
+		AES256B64 string `koanf:"aes256_b64url" secret:"true"`
 		RSAPubPEM string `koanf:"rsa_pub_pem"`
-		RSAPriPEM string `koanf:"rsa_pri_pem"`
+		RSAPriPEM string `koanf:"rsa_pri_pem" secret:"true"`
+		// Replay protection: max |now - ts| accepted for signed requests (default 5m)
+		ReplayWindow time.Duration `koanf:"replay_window"`
+		// Oldest accepted envelope version: 1 = legacy (no AAD), 2 = AAD-bound. Raise to 2 once all clients migrated.
+		MinEnvelopeVersion int `koanf:"min_envelope_version"`
+		// Per-client keys. keys_dir layout: <dir>/<client_id>/<kid>/{aes256.key,rsa_pub.pem[,rsa_pri.pem]}
+		KeysDir string            `koanf:"keys_dir"`
+		Clients []CryptoClientKey `koanf:"clients"`
+		// Let clients without keys of their own use the key above (logged and counted per use)
+		LegacyKeyFallback bool `koanf:"legacy_key_fallback"`
+		// Server key used to sign encrypted responses (required)
+		ServerKeyID         string `koanf:"server_key_id"`
+		ServerSigAlg        string `koanf:"server_sig_alg"` // RS256 (default) | PS256 | EdDSA
+		ServerRSAPriPEM     string `koanf:"server_rsa_pri_pem" secret:"true"`
+		ServerRSAPriPEMFile string `koanf:"server_rsa_pri_pem_file"`
+		// Dev and local-mem only: sign responses with rsa_pri_pem instead. Clients hold that key too,
+		// so such signatures prove nothing.
+		ServerKeyFromLegacy bool `koanf:"server_key_from_legacy"`
 	} `koanf:"crypto"`
 
+	// Public gRPC API (OrderAPI, health, reflection) on its own port; TLS follows http.tls
+	GrpcAPI struct {
+		Enabled bool   `koanf:"enabled"`
+		Addr    string `koanf:"addr"`
+	} `koanf:"grpc_api"`
+
 	GrpcServer struct {
 		Target     string        `koanf:"target"`  // e.g. "order-gw:50051" or "localhost:50051"
 		Timeout    time.Duration `koanf:"timeout"` // dial timeout (e.g., 5s)
 		UseTLS     bool          `koanf:"use_tls"`
 		CACertPath string        `koanf:"ca_cert_path"` // optional: custom CA
 		ServerName string        `koanf:"server_name"`  // optional: override server name for TLS
+		// optional mTLS: client certificate presented to order-gw (reloaded on change)
+		ClientCertPath string `koanf:"client_cert_path"`
+		ClientKeyPath  string `koanf:"client_key_path"`
 		// Optional advanced:
 		MaxRecvBytes int `koanf:"max_recv_bytes"` // e.g., 16<<20
 		MaxSendBytes int `koanf:"max_send_bytes"` // e.g., 16<<20
 	} `koanf:"grpc_server"`
+}
 
-	KafkaBroker struct {
-		KafkaBrokers []string `koanf:"brokers"`
-		KafkaTopic   string   `koanf:"topic"`
-		KafkaGroupID string   `koanf:"group_id"`
-	} `koanf:"kafka"`
+// CryptoClientKey is one (client, kid) key pair. Inline values win over *_file paths.
+type CryptoClientKey struct {
+	ClientID      string `koanf:"client_id"`
+	KeyID         string `koanf:"kid"`
+	SigAlg        string `koanf:"sig_alg"` // RS256 (default) | PS256 | EdDSA
+	AES256B64     string `koanf:"aes256_b64url" secret:"true"`
+	AES256File    string `koanf:"aes256_file"`
+	RSAPubPEM     string `koanf:"rsa_pub_pem"`
+	RSAPubPEMFile string `koanf:"rsa_pub_pem_file"`
+	RSAPriPEMFile string `koanf:"rsa_pri_pem_file"` // optional; only needed by the /_test signing helpers
 }
 
+// Load reads base.yaml, <envName>.yaml and ORDERAPI_ overrides from pathDir, resolves secret
+// references and validates the result, reporting every problem at once.
 func Load(pathDir, envName string) (Config, error) {
+	return load(pathDir, envName, true)
+}
+
+// Check is Load for `order-api config check`: unless resolveSecrets is set, secret references are
+// only checked for syntax and left unresolved, so it runs without access to the secrets.
+func Check(pathDir, envName string, resolveSecrets bool) (Config, error) {
+	return load(pathDir, envName, resolveSecrets)
+}
+
+func load(pathDir, envName string, resolve bool) (Config, error) {
 	k := koanf.New(".")
 	// 1) base
 	if err := k.Load(file.Provider(fmt.Sprintf("%s/base.yaml", pathDir)), yaml.Parser()); err != nil {
@@ -107,25 +242,32 @@ func Load(pathDir, envName string) (Config, error) {
 		return Config{}, fmt.Errorf("env overlay: %w", err)
 	}
 
+	// 4) reject keys that map to no Config field (typos, stale settings)
+	var errs []error
+	if err := checkUnknownKeys(k); err != nil {
+		errs = append(errs, err)
+	}
+
+	// 5) resolve secret references (env://, file://, sealed://, ...), or only check their syntax
+	if resolve {
+		errs = append(errs, resolveSecrets(k))
+	} else {
+		errs = append(errs, checkSecretRefs(k))
+	}
+
 	var cfg Config
 	if err := k.Unmarshal("", &cfg); err != nil {
-		return Config{}, fmt.Errorf("unmarshal: %w", err)
+		return Config{}, errors.Join(append(errs, fmt.Errorf("unmarshal: %w", err))...)
 	}
-	if err := cfg.Validate(); err != nil {
+	cfg.Env = envName
+	if err := errors.Join(append(errs, cfg.Validate())...); err != nil {
 		return Config{}, err
 	}
 	return cfg, nil
 }
 
-func (c Config) Validate() error {
-	if c.App.HTTPAddr == "" {
-		return fmt.Errorf("app.http_addr required")
-	}
-	if c.MySQL.DSN == "" {
-		return fmt.Errorf("mysql.dsn required")
-	}
-	if len(c.Kafka.Brokers) == 0 {
-		return fmt.Errorf("kafka.brokers required (can be dummy for now)")
-	}
-	return nil
+// TestHelpers reports whether the unauthenticated /_test crypto helpers may be served: only in dev and
+// local-mem, since they sign and decrypt for any client.
+func (c Config) TestHelpers() bool {
+	return c.Env == "dev" || c.Env == "local-mem"
 }
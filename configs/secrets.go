@@ -0,0 +1,273 @@
+package configs
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+)
+
+// Secret references let config values point at secrets instead of containing them:
+//
+//	jwt_secret: "env://JWT_SECRET"                          # environment variable
+//	dsn:        "file:///run/secrets/mysql_dsn"             # file contents (trailing newline trimmed)
+//	rsa_pri_pem: "sealed://configs/secrets.prod.yaml#crypto.rsa_pri_pem"
+//
+// sealed:// reads a sops-style YAML file whose values are ENC[AES256_GCM,data:..,iv:..,tag:..] blobs,
+// decrypted with the local key from $GORDER_SECRETS_KEY_FILE (or base64url in $GORDER_SECRETS_KEY).
+// References are resolved at startup (and again by each hot reload, which only validates them): a
+// rotated secret takes effect on restart, since reloads apply only the operational knobs (see Watcher).
+
+// SecretProvider resolves the part of a reference after "<scheme>://".
+type SecretProvider interface {
+	Resolve(ref string) (string, error)
+}
+
+type SecretProviderFunc func(ref string) (string, error)
+
+func (f SecretProviderFunc) Resolve(ref string) (string, error) { return f(ref) }
+
+// SecretRefChecker is optionally implemented by a SecretProvider to check a reference's syntax
+// without resolving it (`order-api config check` without access to the secrets).
+type SecretRefChecker interface {
+	CheckRef(ref string) error
+}
+
+// builtinProvider is a SecretProvider with a syntax check.
+type builtinProvider struct {
+	resolve func(ref string) (string, error)
+	check   func(ref string) error
+}
+
+func (p builtinProvider) Resolve(ref string) (string, error) { return p.resolve(ref) }
+func (p builtinProvider) CheckRef(ref string) error          { return p.check(ref) }
+
+var (
+	secretMu        sync.RWMutex
+	secretProviders = map[string]SecretProvider{
+		"env":    builtinProvider{resolveEnvSecret, checkEnvRef},
+		"file":   builtinProvider{resolveFileSecret, func(string) error { return nil }},
+		"sealed": builtinProvider{resolveSealedSecret, checkSealedRef},
+	}
+	secretRefRe = regexp.MustCompile(`^([a-z][a-z0-9+.-]*)://(.+)$`)
+	envNameRe   = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// RegisterSecretProvider adds or replaces the provider for scheme (e.g. "vault").
+func RegisterSecretProvider(scheme string, p SecretProvider) {
+	secretMu.Lock()
+	defer secretMu.Unlock()
+	secretProviders[scheme] = p
+}
+
+// resolveSecrets replaces every string value that is a reference with a registered scheme and
+// reports every reference that failed. Values with unknown schemes (e.g. amqp://) are left untouched.
+func resolveSecrets(k *koanf.Koanf) error {
+	var errs []error
+	eachSecretRef(k, func(key, scheme, ref string, p SecretProvider) {
+		v, err := p.Resolve(ref)
+		if err == nil {
+			err = k.Set(key, v)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("secret %s (%s://): %w", key, scheme, err))
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// checkSecretRefs checks the syntax of every reference and leaves it unresolved. References of
+// providers without a SecretRefChecker are accepted.
+func checkSecretRefs(k *koanf.Koanf) error {
+	var errs []error
+	eachSecretRef(k, func(key, scheme, ref string, p SecretProvider) {
+		c, ok := p.(SecretRefChecker)
+		if !ok {
+			return
+		}
+		if err := c.CheckRef(ref); err != nil {
+			errs = append(errs, fmt.Errorf("secret %s (%s://): %w", key, scheme, err))
+		}
+	})
+	return errors.Join(errs...)
+}
+
+func eachSecretRef(k *koanf.Koanf, fn func(key, scheme, ref string, p SecretProvider)) {
+	secretMu.RLock()
+	defer secretMu.RUnlock()
+	for _, key := range k.Keys() {
+		s, ok := k.Get(key).(string)
+		if !ok {
+			continue
+		}
+		m := secretRefRe.FindStringSubmatch(s)
+		if m == nil {
+			continue
+		}
+		if p, ok := secretProviders[m[1]]; ok {
+			fn(key, m[1], m[2], p)
+		}
+	}
+}
+
+// isSecretRef reports whether s is a reference with a registered scheme.
+func isSecretRef(s string) bool {
+	m := secretRefRe.FindStringSubmatch(s)
+	if m == nil {
+		return false
+	}
+	secretMu.RLock()
+	defer secretMu.RUnlock()
+	_, ok := secretProviders[m[1]]
+	return ok
+}
+
+func checkEnvRef(name string) error {
+	if !envNameRe.MatchString(name) {
+		return fmt.Errorf("%q is not an environment variable name", name)
+	}
+	return nil
+}
+
+func resolveEnvSecret(name string) (string, error) {
+	v, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("env %s not set", name)
+	}
+	return v, nil
+}
+
+func resolveFileSecret(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+// --- sealed:// (sops-style encrypted values, local AES-256 key) ---
+
+const (
+	SecretsKeyEnv     = "GORDER_SECRETS_KEY"
+	SecretsKeyFileEnv = "GORDER_SECRETS_KEY_FILE"
+)
+
+var sealedRe = regexp.MustCompile(`^ENC\[AES256_GCM,data:([^,]*),iv:([^,]+),tag:([^\]]+)\]$`)
+
+// resolveSealedSecret handles "<file>#<dotted.key>".
+func resolveSealedSecret(ref string) (string, error) {
+	if err := checkSealedRef(ref); err != nil {
+		return "", err
+	}
+	path, key, _ := strings.Cut(ref, "#")
+	sk, err := SecretsKey()
+	if err != nil {
+		return "", err
+	}
+	k := koanf.New(".")
+	if err := k.Load(file.Provider(path), yaml.Parser()); err != nil {
+		return "", err
+	}
+	raw := k.String(key)
+	if raw == "" {
+		return "", fmt.Errorf("key %q not found in %s", key, path)
+	}
+	return OpenSealedValue(sk, key, raw)
+}
+
+func checkSealedRef(ref string) error {
+	path, key, ok := strings.Cut(ref, "#")
+	if !ok || path == "" || key == "" {
+		return errors.New(`sealed reference must be "<file>#<key>"`)
+	}
+	return nil
+}
+
+// SecretsKey loads the local sealing key (32 bytes, base64url).
+func SecretsKey() ([]byte, error) {
+	enc := os.Getenv(SecretsKeyEnv)
+	if enc == "" {
+		path := os.Getenv(SecretsKeyFileEnv)
+		if path == "" {
+			return nil, fmt.Errorf("sealed secrets need %s or %s", SecretsKeyFileEnv, SecretsKeyEnv)
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		enc = strings.TrimSpace(string(b))
+	}
+	key, err := base64.RawURLEncoding.DecodeString(enc)
+	if err != nil {
+		return nil, fmt.Errorf("decode secrets key: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("secrets key must be 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+// SealValue encrypts plaintext for the config key path; the path is bound as AAD so values cannot be swapped.
+func SealValue(key []byte, path, plaintext string) (string, error) {
+	aead, err := secretsAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	iv := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nil, iv, []byte(plaintext), []byte(path))
+	n := len(sealed) - aead.Overhead()
+	std := base64.StdEncoding
+	return fmt.Sprintf("ENC[AES256_GCM,data:%s,iv:%s,tag:%s]",
+		std.EncodeToString(sealed[:n]), std.EncodeToString(iv), std.EncodeToString(sealed[n:])), nil
+}
+
+// OpenSealedValue reverses SealValue. The value must be sealed: plaintext or a malformed ENC[...] is an error.
+func OpenSealedValue(key []byte, path, value string) (string, error) {
+	m := sealedRe.FindStringSubmatch(value)
+	if m == nil {
+		if strings.HasPrefix(value, "ENC[") {
+			return "", fmt.Errorf("%s: malformed ENC value", path)
+		}
+		return "", fmt.Errorf("%s: value is not sealed (want ENC[AES256_GCM,...])", path)
+	}
+	std := base64.StdEncoding
+	ct, err1 := std.DecodeString(m[1])
+	iv, err2 := std.DecodeString(m[2])
+	tag, err3 := std.DecodeString(m[3])
+	if err := errors.Join(err1, err2, err3); err != nil {
+		return "", fmt.Errorf("malformed ENC value: %w", err)
+	}
+	aead, err := secretsAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	if len(iv) != aead.NonceSize() {
+		return "", errors.New("malformed ENC value: bad iv")
+	}
+	pt, err := aead.Open(nil, iv, append(ct, tag...), []byte(path))
+	if err != nil {
+		return "", fmt.Errorf("decrypt %s: %w", path, err)
+	}
+	return string(pt), nil
+}
+
+func secretsAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,128 @@
+package configs
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/knadh/koanf/v2"
+)
+
+func TestResolveSecrets(t *testing.T) {
+	dir := t.TempDir()
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(SecretsKeyEnv, base64.RawURLEncoding.EncodeToString(key))
+	t.Setenv("GORDER_TEST_JWT", "from-env")
+
+	seal := func(path, pt string) string {
+		v, err := SealValue(key, path, pt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	sealedFile := filepath.Join(dir, "secrets.yaml")
+	write(t, sealedFile, strings.Join([]string{
+		"security:",
+		"  jwt_secret: " + seal("security.jwt_secret", "from-sealed"),
+		"  swapped: " + seal("security.jwt_secret", "for-another-key"),
+		"  plain: not-sealed",
+		"  truncated: ENC[AES256_GCM,data:AAAA,iv:AAAA]",
+		"",
+	}, "\n"))
+	write(t, filepath.Join(dir, "dsn"), "user:pw@tcp(db)/orders\n")
+
+	for _, tc := range []struct {
+		name, value string
+		want        string // resolved value; empty means resolution must fail
+	}{
+		{"env", "env://GORDER_TEST_JWT", "from-env"},
+		{"env not set", "env://GORDER_TEST_UNSET", ""},
+		{"file, newline trimmed", "file://" + filepath.Join(dir, "dsn"), "user:pw@tcp(db)/orders"},
+		{"missing file", "file://" + filepath.Join(dir, "nope"), ""},
+		{"sealed", "sealed://" + sealedFile + "#security.jwt_secret", "from-sealed"},
+		{"sealed for another key", "sealed://" + sealedFile + "#security.swapped", ""},
+		{"plaintext behind sealed://", "sealed://" + sealedFile + "#security.plain", ""},
+		{"malformed ENC value", "sealed://" + sealedFile + "#security.truncated", ""},
+		{"sealed key not in file", "sealed://" + sealedFile + "#security.nope", ""},
+		{"sealed without #key", "sealed://" + sealedFile, ""},
+		{"sealed with empty key", "sealed://" + sealedFile + "#", ""},
+		{"sealed with empty file", "sealed://#security.jwt_secret", ""},
+		{"unknown scheme left alone", "amqp://guest:guest@mq:5672/", "amqp://guest:guest@mq:5672/"},
+		{"plain value left alone", "s3cr3t", "s3cr3t"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			k := koanf.New(".")
+			if err := k.Set("security.jwt_secret", tc.value); err != nil {
+				t.Fatal(err)
+			}
+			err := resolveSecrets(k)
+			if tc.want == "" {
+				if err == nil {
+					t.Fatalf("resolved %q to %q, want error", tc.value, k.String("security.jwt_secret"))
+				}
+				if !strings.Contains(err.Error(), "security.jwt_secret") {
+					t.Fatalf("error %q does not name the key", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := k.String("security.jwt_secret"); got != tc.want {
+				t.Fatalf("resolved to %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestResolveSecretsWithoutSecretsKey(t *testing.T) {
+	t.Setenv(SecretsKeyEnv, "")
+	t.Setenv(SecretsKeyFileEnv, "")
+	k := koanf.New(".")
+	_ = k.Set("db.dsn", "sealed://configs/secrets.prod.yaml#db.dsn")
+	if err := resolveSecrets(k); err == nil || !strings.Contains(err.Error(), SecretsKeyEnv) {
+		t.Fatalf("resolveSecrets = %v, want missing key error", err)
+	}
+}
+
+// config check validates reference syntax without the secrets being reachable.
+func TestCheckSecretRefs(t *testing.T) {
+	for _, tc := range []struct {
+		name, value string
+		ok          bool
+	}{
+		{"env", "env://JWT_SECRET", true},
+		{"env, not a variable name", "env://JWT-SECRET", false},
+		{"env with a path", "env://secrets/jwt", false},
+		{"file is never checked", "file:///run/secrets/nope", true},
+		{"sealed, unreachable file", "sealed://configs/secrets.prod.yaml#db.dsn", true},
+		{"sealed without #key", "sealed://configs/secrets.prod.yaml", false},
+		{"sealed with empty key", "sealed://configs/secrets.prod.yaml#", false},
+		{"unknown scheme", "amqp://mq:5672/", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			k := koanf.New(".")
+			_ = k.Set("security.jwt_secret", tc.value)
+			if err := checkSecretRefs(k); (err == nil) != tc.ok {
+				t.Fatalf("checkSecretRefs(%q) = %v, want ok=%v", tc.value, err, tc.ok)
+			}
+			if got := k.String("security.jwt_secret"); got != tc.value {
+				t.Fatalf("value changed to %q", got)
+			}
+		})
+	}
+}
+
+func write(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
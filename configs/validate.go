@@ -0,0 +1,284 @@
+package configs
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/knadh/koanf/v2"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// knownKeys returns the dotted koanf paths of t: leaves and the structs containing them.
+// Slices of structs are leaves here; their elements are checked by checkUnknownKeys.
+func knownKeys(t reflect.Type, prefix string, out map[string]reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("koanf")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		key := prefix + tag
+		out[key] = f.Type
+		if f.Type.Kind() == reflect.Struct && f.Type != durationType {
+			knownKeys(f.Type, key+".", out)
+		}
+	}
+}
+
+// checkUnknownKeys rejects loaded keys (YAML or ORDERAPI_ env) that do not map to a Config field.
+func checkUnknownKeys(k *koanf.Koanf) error {
+	known := map[string]reflect.Type{}
+	knownKeys(reflect.TypeOf(Config{}), "", known)
+
+	var unknown []string
+	for _, key := range k.Keys() {
+		t, ok := known[key]
+		if !ok {
+			unknown = append(unknown, key)
+			continue
+		}
+		if t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Struct {
+			continue
+		}
+		elems, _ := k.Get(key).([]interface{})
+		elemKeys := map[string]reflect.Type{}
+		knownKeys(t.Elem(), "", elemKeys)
+		for i, e := range elems {
+			m, _ := e.(map[string]interface{})
+			for ek := range m {
+				if _, ok := elemKeys[ek]; !ok {
+					unknown = append(unknown, fmt.Sprintf("%s[%d].%s", key, i, ek))
+				}
+			}
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown config keys: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// Validate checks every section and reports all problems at once (errors.Join).
+func (c Config) Validate() error {
+	var errs []error
+	req := func(v, key string) {
+		if strings.TrimSpace(v) == "" {
+			errs = append(errs, fmt.Errorf("%s required", key))
+		}
+	}
+	nonNeg := func(v int64, key string) {
+		if v < 0 {
+			errs = append(errs, fmt.Errorf("%s must be >= 0", key))
+		}
+	}
+	oneOf := func(v, key string, allowed ...string) {
+		for _, a := range allowed {
+			if v == a {
+				return
+			}
+		}
+		errs = append(errs, fmt.Errorf("%s: %q is not one of %s", key, v, strings.Join(allowed, "|")))
+	}
+	pair := func(a, b, keyA, keyB string) {
+		if (a == "") != (b == "") {
+			errs = append(errs, fmt.Errorf("%s and %s must be set together", keyA, keyB))
+		}
+	}
+
+	// app
+	req(c.App.HTTPAddr, "app.http_addr")
+	if c.App.LogLevel != "" {
+		var l slog.Level
+		if err := l.UnmarshalText([]byte(c.App.LogLevel)); err != nil {
+			errs = append(errs, fmt.Errorf("app.log_level: %w", err))
+		}
+	}
+
+	// http
+	nonNeg(int64(c.HTTP.ReadTimeout), "http.read_timeout")
+	nonNeg(int64(c.HTTP.WriteTimeout), "http.write_timeout")
+	nonNeg(int64(c.HTTP.IdleTimeout), "http.idle_timeout")
+	if c.HTTP.RateLimit.RPS < 0 {
+		errs = append(errs, errors.New("http.rate_limit.rps must be >= 0"))
+	}
+	nonNeg(int64(c.HTTP.RateLimit.Burst), "http.rate_limit.burst")
+	nonNeg(int64(c.HTTP.Caching.FinalMaxAge), "http.caching.final_max_age")
+	nonNeg(int64(c.HTTP.Caching.PendingMaxAge), "http.caching.pending_max_age")
+	tls := c.HTTP.TLS
+	pair(tls.CertFile, tls.KeyFile, "http.tls.cert_file", "http.tls.key_file")
+	oneOf(tls.ClientAuth, "http.tls.client_auth", "", "none", "request", "verify_if_given", "require")
+	if tls.CertFile == "" && (tls.ClientCAFile != "" || (tls.ClientAuth != "" && tls.ClientAuth != "none")) {
+		errs = append(errs, errors.New("http.tls.client_ca_file/client_auth need http.tls.cert_file"))
+	}
+	if (tls.ClientAuth == "verify_if_given" || tls.ClientAuth == "require") && tls.ClientCAFile == "" {
+		errs = append(errs, fmt.Errorf("http.tls.client_auth %q needs http.tls.client_ca_file", tls.ClientAuth))
+	}
+
+	// mysql
+	req(c.MySQL.DSN, "mysql.dsn")
+	nonNeg(int64(c.MySQL.MaxOpenConns), "mysql.max_open_conns")
+	nonNeg(int64(c.MySQL.MaxIdleConns), "mysql.max_idle_conns")
+	nonNeg(int64(c.MySQL.ConnMaxLifetime), "mysql.conn_max_lifetime")
+	if c.MySQL.MigrateLockTimeout < time.Second {
+		errs = append(errs, errors.New("mysql.migrate_lock_timeout must be at least 1s"))
+	}
+	if c.MySQL.MaxOpenConns > 0 && c.MySQL.MaxIdleConns > c.MySQL.MaxOpenConns {
+		errs = append(errs, errors.New("mysql.max_idle_conns must be <= mysql.max_open_conns"))
+	}
+
+	// redis, ttls
+	req(c.Redis.Addr, "redis.addr")
+	nonNeg(int64(c.Idempotency.TTL), "idempotency.ttl")
+	nonNeg(int64(c.Cache.TTL), "cache.ttl")
+
+	// batch
+	if c.Batch.MaxItems <= 0 {
+		errs = append(errs, errors.New("batch.max_items must be > 0"))
+	}
+	oneOf(c.Batch.Mode, "batch.mode", "atomic", "per_item")
+
+	// rabbitmq
+	req(c.Rabbit.URL, "rabbitmq.url")
+	if c.Rabbit.URL != "" && !isSecretRef(c.Rabbit.URL) { // unresolved under config check
+		if u, err := url.Parse(c.Rabbit.URL); err != nil || (u.Scheme != "amqp" && u.Scheme != "amqps") {
+			errs = append(errs, errors.New("rabbitmq.url must be an amqp:// or amqps:// URL"))
+		}
+	}
+	req(c.Rabbit.Exchange, "rabbitmq.exchange")
+	req(c.Rabbit.RoutingKey, "rabbitmq.routing_key")
+	req(c.Rabbit.Queue, "rabbitmq.queue")
+	nonNeg(int64(c.Rabbit.Prefetch), "rabbitmq.prefetch")
+
+	// kafka
+	if len(c.Kafka.Brokers) == 0 {
+		errs = append(errs, errors.New("kafka.brokers required"))
+	}
+	for i, b := range c.Kafka.Brokers {
+		req(b, fmt.Sprintf("kafka.brokers[%d]", i))
+	}
+	req(c.Kafka.Topic, "kafka.topic")
+	req(c.Kafka.GroupID, "kafka.group_id")
+
+	// events
+	if c.Events.Heartbeat <= 0 || c.Events.MaxWait <= 0 || c.Events.Retention <= 0 {
+		errs = append(errs, errors.New("events.heartbeat, events.max_wait and events.retention must be > 0"))
+	}
+
+	// reaper
+	if r := c.Reaper; r.Enabled {
+		if r.Interval <= 0 || r.SLA <= 0 {
+			errs = append(errs, errors.New("reaper.interval and reaper.sla must be > 0"))
+		}
+		if r.MaxAttempts < 0 {
+			errs = append(errs, errors.New("reaper.max_attempts must be >= 0"))
+		}
+		if r.BatchSize <= 0 {
+			errs = append(errs, errors.New("reaper.batch_size must be > 0"))
+		}
+		if r.LeaseTTL <= r.Interval {
+			errs = append(errs, errors.New("reaper.lease_ttl must be longer than reaper.interval"))
+		}
+	}
+
+	// webhooks
+	if w := c.Webhooks; w.Enabled {
+		if w.Interval <= 0 || w.Timeout <= 0 || w.BackoffBase <= 0 || w.BackoffMax < w.BackoffBase {
+			errs = append(errs, errors.New("webhooks.interval, timeout and backoff_base must be > 0 and backoff_max >= backoff_base"))
+		}
+		if w.BatchSize <= 0 || w.MaxAttempts <= 0 {
+			errs = append(errs, errors.New("webhooks.batch_size and webhooks.max_attempts must be > 0"))
+		}
+		if w.ClaimTTL <= time.Duration(w.BatchSize)*w.Timeout {
+			errs = append(errs, errors.New("webhooks.claim_ttl must be longer than batch_size * timeout"))
+		}
+	}
+
+	// jobs
+	req(c.Jobs.Dir, "jobs.dir")
+	if c.Jobs.MaxUploadBytes <= 0 || c.Jobs.PageSize <= 0 {
+		errs = append(errs, errors.New("jobs.max_upload_bytes and jobs.page_size must be > 0"))
+	}
+	if j := c.Jobs; j.Enabled && (j.Interval <= 0 || j.ClaimTTL <= j.Interval) {
+		errs = append(errs, errors.New("jobs.interval must be > 0 and jobs.claim_ttl longer than it"))
+	}
+
+	// reconcile
+	if c.Reconcile.Window <= 0 {
+		errs = append(errs, errors.New("reconcile.window must be > 0"))
+	}
+	nonNeg(int64(c.Reconcile.Settle), "reconcile.settle")
+	if c.Reconcile.PageSize <= 0 {
+		errs = append(errs, errors.New("reconcile.page_size must be > 0"))
+	}
+
+	// security
+	req(c.Security.JWTSecret, "security.jwt_secret")
+	req(c.Security.Issuer, "security.issuer")
+	req(c.Security.Audience, "security.audience")
+	if c.Security.TTL < time.Second { // also catches a bare number, which decodes as nanoseconds
+		errs = append(errs, errors.New("security.ttl must be a duration of at least 1s (e.g. 30m)"))
+	}
+
+	// crypto
+	cc := c.CryptoConfig
+	nonNeg(int64(cc.ReplayWindow), "crypto.replay_window")
+	if cc.MinEnvelopeVersion < 0 || cc.MinEnvelopeVersion > 2 {
+		errs = append(errs, errors.New("crypto.min_envelope_version must be 1 or 2"))
+	}
+	oneOf(cc.ServerSigAlg, "crypto.server_sig_alg", "", "RS256", "PS256", "EdDSA")
+	switch {
+	case cc.ServerRSAPriPEM != "" && cc.ServerRSAPriPEMFile != "":
+		errs = append(errs, errors.New("crypto.server_rsa_pri_pem and server_rsa_pri_pem_file are mutually exclusive"))
+	case cc.ServerKeyFromLegacy && !c.TestHelpers():
+		errs = append(errs, errors.New("crypto.server_key_from_legacy is only allowed in dev and local-mem"))
+	case cc.ServerRSAPriPEM == "" && cc.ServerRSAPriPEMFile == "" && !(cc.ServerKeyFromLegacy && cc.RSAPriPEM != ""):
+		errs = append(errs, errors.New("crypto.server_rsa_pri_pem or server_rsa_pri_pem_file required"))
+	}
+	seen := map[string]bool{}
+	for i, ck := range cc.Clients {
+		p := fmt.Sprintf("crypto.clients[%d]", i)
+		req(ck.ClientID, p+".client_id")
+		req(ck.KeyID, p+".kid")
+		oneOf(ck.SigAlg, p+".sig_alg", "", "RS256", "PS256", "EdDSA")
+		if ck.AES256B64 == "" && ck.AES256File == "" {
+			errs = append(errs, fmt.Errorf("%s: aes256_b64url or aes256_file required", p))
+		}
+		if ck.RSAPubPEM == "" && ck.RSAPubPEMFile == "" {
+			errs = append(errs, fmt.Errorf("%s: rsa_pub_pem or rsa_pub_pem_file required", p))
+		}
+		id := ck.ClientID + "/" + ck.KeyID
+		if seen[id] {
+			errs = append(errs, fmt.Errorf("%s: duplicate key %s", p, id))
+		}
+		seen[id] = true
+	}
+
+	// grpc_api
+	if c.GrpcAPI.Enabled {
+		req(c.GrpcAPI.Addr, "grpc_api.addr")
+		if c.GrpcAPI.Addr == c.App.HTTPAddr {
+			errs = append(errs, errors.New("grpc_api.addr must differ from app.http_addr"))
+		}
+	}
+
+	// grpc_server (order-gw)
+	gs := c.GrpcServer
+	req(gs.Target, "grpc_server.target")
+	nonNeg(int64(gs.Timeout), "grpc_server.timeout")
+	nonNeg(int64(gs.MaxRecvBytes), "grpc_server.max_recv_bytes")
+	nonNeg(int64(gs.MaxSendBytes), "grpc_server.max_send_bytes")
+	pair(gs.ClientCertPath, gs.ClientKeyPath, "grpc_server.client_cert_path", "grpc_server.client_key_path")
+	if !gs.UseTLS && (gs.CACertPath != "" || gs.ClientCertPath != "") {
+		errs = append(errs, errors.New("grpc_server.ca_cert_path/client_cert_path need grpc_server.use_tls"))
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,111 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// prodConfig is prod.yaml as `config check` sees it: references unresolved.
+func prodConfig(t *testing.T) Config {
+	t.Helper()
+	c, err := Check(".", "prod", false)
+	if err != nil {
+		t.Fatalf("prod config: %v", err)
+	}
+	return c
+}
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		set  func(c *Config)
+		want string // substring of the error; empty means valid
+	}{
+		{"prod as committed", func(*Config) {}, ""},
+		{"missing dsn", func(c *Config) { c.MySQL.DSN = " " }, "mysql.dsn required"},
+		{"bad log level", func(c *Config) { c.App.LogLevel = "verbose" }, "app.log_level"},
+		{"token ttl as bare number", func(c *Config) { c.Security.TTL = 1800 }, "security.ttl"},
+		{"negative timeout", func(c *Config) { c.HTTP.ReadTimeout = -time.Second }, "http.read_timeout must be >= 0"},
+		{"idle above open conns", func(c *Config) { c.MySQL.MaxOpenConns, c.MySQL.MaxIdleConns = 5, 10 }, "max_idle_conns must be <="},
+		{"rabbit url not amqp", func(c *Config) { c.Rabbit.URL = "http://mq" }, "rabbitmq.url"},
+		{"rabbit url as reference", func(c *Config) { c.Rabbit.URL = "file:///run/secrets/amqp" }, ""},
+		{"unknown batch mode", func(c *Config) { c.Batch.Mode = "best_effort" }, "batch.mode"},
+		{"cert without key", func(c *Config) { c.HTTP.TLS.CertFile = "tls.crt" }, "must be set together"},
+		{"client auth without CA", func(c *Config) {
+			c.HTTP.TLS.CertFile, c.HTTP.TLS.KeyFile, c.HTTP.TLS.ClientAuth = "tls.crt", "tls.key", "require"
+		}, "needs http.tls.client_ca_file"},
+		{"client auth without server cert", func(c *Config) { c.HTTP.TLS.ClientAuth = "request" }, "need http.tls.cert_file"},
+		{"reaper lease shorter than interval", func(c *Config) {
+			c.Reaper.Enabled, c.Reaper.Interval, c.Reaper.LeaseTTL = true, time.Minute, 30*time.Second
+		}, "reaper.lease_ttl"},
+		{"envelope version 3", func(c *Config) { c.CryptoConfig.MinEnvelopeVersion = 3 }, "min_envelope_version"},
+		{"unknown server alg", func(c *Config) { c.CryptoConfig.ServerSigAlg = "HS256" }, "server_sig_alg"},
+		{"no server key", func(c *Config) { c.CryptoConfig.ServerRSAPriPEMFile = "" }, "server_rsa_pri_pem or server_rsa_pri_pem_file required"},
+		{"two server keys", func(c *Config) { c.CryptoConfig.ServerRSAPriPEM = "-----BEGIN" }, "mutually exclusive"},
+		{"legacy server key outside dev", func(c *Config) { c.CryptoConfig.ServerKeyFromLegacy = true }, "only allowed in dev"},
+		{"duplicate client key", func(c *Config) {
+			k := CryptoClientKey{ClientID: "svc-order-gw", KeyID: "v1", AES256File: "aes", RSAPubPEMFile: "pub"}
+			c.CryptoConfig.Clients = []CryptoClientKey{k, k}
+		}, "duplicate key svc-order-gw/v1"},
+		{"grpc api on the http port", func(c *Config) { c.GrpcAPI.Enabled, c.GrpcAPI.Addr = true, c.App.HTTPAddr }, "grpc_api.addr must differ"},
+		{"order-gw client cert without tls", func(c *Config) {
+			c.GrpcServer.UseTLS, c.GrpcServer.CACertPath = false, "ca.crt"
+		}, "need grpc_server.use_tls"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := prodConfig(t)
+			tc.set(&c)
+			err := c.Validate()
+			if tc.want == "" {
+				if err != nil {
+					t.Fatalf("Validate = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("Validate = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	c := prodConfig(t)
+	c.MySQL.DSN, c.Redis.Addr, c.Batch.MaxItems = "", "", 0
+	err := c.Validate()
+	for _, want := range []string{"mysql.dsn", "redis.addr", "batch.max_items"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate = %v, want it to mention %s", err, want)
+		}
+	}
+}
+
+func TestLoadRejectsUnknownKeys(t *testing.T) {
+	dir := t.TempDir()
+	base, err := os.ReadFile("base.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	write(t, filepath.Join(dir, "base.yaml"), string(base))
+	write(t, filepath.Join(dir, "typo.yaml"), strings.Join([]string{
+		"redis:",
+		"  adress: redis:6379",
+		"crypto:",
+		"  clients:",
+		"    - client_id: svc-order-gw",
+		"      kid: v1",
+		"      aes_file: aes",
+		"",
+	}, "\n"))
+	t.Setenv("ORDERAPI_HTTP__RATE__LIMIT", "5")
+
+	_, err = Check(dir, "typo", false)
+	for _, want := range []string{"redis.adress", "crypto.clients[0].aes_file", "http.rate.limit"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Check = %v, want it to reject %s", err, want)
+		}
+	}
+}
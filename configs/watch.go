@@ -0,0 +1,108 @@
+package configs
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/aq2208/gorder-api/internal/logging"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var configReloads = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "config_reloads_total",
+		Help: "Config reload attempts by result (applied | rejected).",
+	},
+	[]string{"result"},
+)
+
+// Watcher reloads the config when base.yaml or the env overlay changes. A new config is only swapped in
+// when Load (and therefore Validate) succeeds; subscribers are then called with the old and new values.
+// Only knobs that components can apply live are expected to change this way (log level, TTLs,
+// rate limits, prefetch); everything else still needs a restart.
+type Watcher struct {
+	pathDir, envName string
+
+	mu      sync.RWMutex
+	current Config
+	subs    []func(old, new Config)
+
+	files []*file.File
+}
+
+func NewWatcher(pathDir, envName string, initial Config) *Watcher {
+	return &Watcher{pathDir: pathDir, envName: envName, current: initial}
+}
+
+// Current returns the config in effect.
+func (w *Watcher) Current() Config {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.current
+}
+
+// Subscribe registers fn to be called after every applied reload.
+func (w *Watcher) Subscribe(fn func(old, new Config)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subs = append(w.subs, fn)
+}
+
+// Start watches the config files; a missing env overlay is skipped, as in Load.
+func (w *Watcher) Start() error {
+	for _, path := range []string{
+		fmt.Sprintf("%s/base.yaml", w.pathDir),
+		fmt.Sprintf("%s/%s.yaml", w.pathDir, w.envName),
+	} {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		f := file.Provider(path)
+		if err := f.Watch(func(_ interface{}, err error) {
+			if err != nil {
+				logging.New("config").Error("config watch error", "file", path, "err", err)
+				return
+			}
+			_ = w.Reload()
+		}); err != nil {
+			w.Stop()
+			return fmt.Errorf("watch %s: %w", path, err)
+		}
+		w.files = append(w.files, f)
+	}
+	return nil
+}
+
+// Reload re-runs Load and, if it succeeds, swaps the config and notifies subscribers.
+func (w *Watcher) Reload() error {
+	l := logging.New("config")
+	cfg, err := Load(w.pathDir, w.envName)
+	if err != nil {
+		configReloads.WithLabelValues("rejected").Inc()
+		l.Error("config reload rejected; keeping previous config", "env", w.envName, "err", err)
+		return err
+	}
+
+	w.mu.Lock()
+	old := w.current
+	w.current = cfg
+	subs := append([]func(old, new Config){}, w.subs...)
+	w.mu.Unlock()
+
+	for _, fn := range subs {
+		fn(old, cfg)
+	}
+	configReloads.WithLabelValues("applied").Inc()
+	l.Info("config reloaded", "env", w.envName)
+	return nil
+}
+
+func (w *Watcher) Stop() {
+	for _, f := range w.files {
+		_ = f.Unwatch()
+	}
+	w.files = nil
+}
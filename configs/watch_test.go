@@ -0,0 +1,65 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWatcherReload(t *testing.T) {
+	dir := t.TempDir()
+	base, err := os.ReadFile("base.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	overlay, err := os.ReadFile("dev.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	write(t, filepath.Join(dir, "dev.yaml"), string(overlay))
+	setBase := func(from, to string) {
+		t.Helper()
+		if !strings.Contains(string(base), from) {
+			t.Fatalf("base.yaml has no %q", from)
+		}
+		write(t, filepath.Join(dir, "base.yaml"), strings.Replace(string(base), from, to, 1))
+	}
+	write(t, filepath.Join(dir, "base.yaml"), string(base))
+
+	initial, err := Load(dir, "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := NewWatcher(dir, "dev", initial)
+	var calls []string
+	w.Subscribe(func(old, new Config) { calls = append(calls, old.App.LogLevel+"->"+new.App.LogLevel) })
+
+	for _, tc := range []struct {
+		name, from, to string
+		applied        bool
+		wantLevel      string
+	}{
+		{"valid change is applied", "log_level: info", "log_level: debug", true, "debug"},
+		{"invalid value keeps the previous config", "log_level: info", "log_level: loud", false, "debug"},
+		{"unknown key keeps the previous config", "log_level: info", "log_levle: warn", false, "debug"},
+		{"later valid change is applied", "log_level: info", "log_level: warn", true, "warn"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			before := len(calls)
+			setBase(tc.from, tc.to)
+			if err := w.Reload(); (err == nil) != tc.applied {
+				t.Fatalf("Reload = %v, want applied=%v", err, tc.applied)
+			}
+			if got := w.Current().App.LogLevel; got != tc.wantLevel {
+				t.Fatalf("log level = %q, want %q", got, tc.wantLevel)
+			}
+			if notified := len(calls) > before; notified != tc.applied {
+				t.Fatalf("subscribers notified = %v, want %v (calls %v)", notified, tc.applied, calls)
+			}
+		})
+	}
+	if got := strings.Join(calls, ","); got != "info->debug,debug->warn" {
+		t.Fatalf("subscriber calls = %s", got)
+	}
+}
@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/aq2208/gorder-api/configs"
+	"github.com/aq2208/gorder-api/internal/security"
+	"github.com/aq2208/gorder-api/internal/usecase"
+	"github.com/google/wire"
+	"github.com/redis/go-redis/v9"
+)
+
+// ProviderSet provides the Redis-backed cache, idempotency store, replay nonce store, reaper lease and
+// order status events.
+var ProviderSet = wire.NewSet(
+	NewRedisClient,
+	ProvideCache,
+	ProvideIdempotencyStore,
+	NewRedisNonceStore,
+	ProvideReaperLease,
+	ProvideOrderEvents,
+	wire.Bind(new(usecase.OrderCache), new(*RedisCache)),
+	wire.Bind(new(usecase.IdempotencyStore), new(*RedisIdempotencyStore)),
+	wire.Bind(new(security.NonceStore), new(*RedisNonceStore)),
+	wire.Bind(new(usecase.LeaderLease), new(*RedisLeaderLease)),
+	wire.Bind(new(usecase.OrderEventStream), new(*RedisOrderEvents)),
+	wire.Bind(new(usecase.OrderEventPublisher), new(*RedisOrderEvents)),
+)
+
+// NewRedisClient connects to cfg.Redis and pings it.
+func NewRedisClient(cfg configs.Config) (*redis.Client, func(), error) {
+	rdb := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.Addr,
+		Password: cfg.Redis.Password,
+		DB:       0,
+	})
+	if err := rdb.Ping(context.Background()).Err(); err != nil {
+		_ = rdb.Close()
+		return nil, nil, err
+	}
+	return rdb, func() { _ = rdb.Close() }, nil
+}
+
+func ProvideCache(rdb *redis.Client, cfg configs.Config) *RedisCache {
+	return NewRedisCache(rdb, cfg.Cache.TTL)
+}
+
+func ProvideIdempotencyStore(rdb *redis.Client, cfg configs.Config) *RedisIdempotencyStore {
+	return NewRedisIdempotencyStore(rdb, cfg.Idempotency.TTL)
+}
+
+func ProvideReaperLease(rdb *redis.Client, cfg configs.Config) *RedisLeaderLease {
+	return NewRedisLeaderLease(rdb, "order-reaper", cfg.Reaper.LeaseTTL)
+}
+
+func ProvideOrderEvents(rdb *redis.Client, cfg configs.Config) (*RedisOrderEvents, func()) {
+	e := NewRedisOrderEvents(rdb, cfg.Events.Retention)
+	return e, func() { _ = e.Close() }
+}
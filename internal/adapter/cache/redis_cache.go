@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	"github.com/aq2208/gorder-api/internal/usecase"
@@ -10,22 +11,24 @@ import (
 
 type RedisCache struct {
 	rdb *redis.Client
-	ttl time.Duration
+	ttl atomic.Int64 // time.Duration; 0 = no expiry
 }
 
 func NewRedisCache(rdb *redis.Client, ttl time.Duration) *RedisCache {
-	return &RedisCache{rdb: rdb, ttl: ttl}
+	c := &RedisCache{rdb: rdb}
+	c.SetTTL(ttl)
+	return c
 }
 
-func (r RedisCache) SetStatus(ctx context.Context, orderID string, status string) error {
+// SetTTL changes the expiry used for subsequent writes (config hot reload).
+func (r *RedisCache) SetTTL(ttl time.Duration) { r.ttl.Store(int64(ttl)) }
+
+func (r *RedisCache) SetStatus(ctx context.Context, orderID string, status string) error {
 	key := "order:status:" + orderID
-	if r.ttl > 0 {
-		return r.rdb.Set(ctx, key, status, time.Duration(r.ttl)*time.Second).Err()
-	}
-	return r.rdb.Set(ctx, key, status, 0).Err()
+	return r.rdb.Set(ctx, key, status, time.Duration(r.ttl.Load())).Err()
 }
 
-func (r RedisCache) GetStatus(ctx context.Context, orderID string) (string, error) {
+func (r *RedisCache) GetStatus(ctx context.Context, orderID string) (string, error) {
 	//TODO implement me
 	panic("implement me")
 }
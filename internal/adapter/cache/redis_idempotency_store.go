@@ -3,6 +3,7 @@ package cache
 import (
 	"context"
 	"errors"
+	"sync/atomic"
 	"time"
 
 	"github.com/aq2208/gorder-api/internal/usecase"
@@ -11,19 +12,28 @@ import (
 
 type RedisIdempotencyStore struct {
 	rdb *redis.Client
-	ttl time.Duration
+	ttl atomic.Int64 // time.Duration
 }
 
 func NewRedisIdempotencyStore(rdb *redis.Client, ttl time.Duration) *RedisIdempotencyStore {
-	return &RedisIdempotencyStore{rdb: rdb, ttl: ttl}
+	s := &RedisIdempotencyStore{rdb: rdb}
+	s.SetTTL(ttl)
+	return s
 }
 
+// SetTTL changes the lifetime of locks/mappings written from now on (config hot reload).
+func (s *RedisIdempotencyStore) SetTTL(ttl time.Duration) { s.ttl.Store(int64(ttl)) }
+
 func (s *RedisIdempotencyStore) TryLock(ctx context.Context, key string) (bool, error) {
-	return s.rdb.SetNX(ctx, "idemp:"+key, "1", s.ttl).Result()
+	return s.rdb.SetNX(ctx, "idemp:"+key, "1", time.Duration(s.ttl.Load())).Result()
+}
+
+func (s *RedisIdempotencyStore) Unlock(ctx context.Context, key string) error {
+	return s.rdb.Del(ctx, "idemp:"+key).Err()
 }
 
 func (s *RedisIdempotencyStore) Remember(ctx context.Context, key, value string) error {
-	return s.rdb.Set(ctx, "idemp:map:"+key, value, s.ttl).Err()
+	return s.rdb.Set(ctx, "idemp:map:"+key, value, time.Duration(s.ttl.Load())).Err()
 }
 
 func (s *RedisIdempotencyStore) Recall(ctx context.Context, key string) (string, bool, error) {
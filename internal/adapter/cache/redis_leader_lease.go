@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/aq2208/gorder-api/internal/usecase"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisLeaderLease is a single-key lease: SET NX PX with a per-instance token. Renew and release only
+// touch the key while it still holds our token, so an instance that lost the lease (GC pause, network
+// split) cannot extend or delete the new leader's.
+type RedisLeaderLease struct {
+	rdb   *redis.Client
+	key   string
+	token string
+	ttl   time.Duration
+}
+
+func NewRedisLeaderLease(rdb *redis.Client, name string, ttl time.Duration) *RedisLeaderLease {
+	host, _ := os.Hostname()
+	return &RedisLeaderLease{rdb: rdb, key: "leader:" + name, token: host + "/" + uuid.NewString(), ttl: ttl}
+}
+
+var renewLease = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+var releaseLease = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+func (l *RedisLeaderLease) Acquire(ctx context.Context) (bool, error) {
+	ok, err := l.rdb.SetNX(ctx, l.key, l.token, l.ttl).Result()
+	if err != nil || ok {
+		return ok, err
+	}
+	n, err := renewLease.Run(ctx, l.rdb, []string{l.key}, l.token, l.ttl.Milliseconds()).Int()
+	return n == 1, err
+}
+
+func (l *RedisLeaderLease) Release(ctx context.Context) error {
+	return releaseLease.Run(ctx, l.rdb, []string{l.key}, l.token).Err()
+}
+
+var _ usecase.LeaderLease = (*RedisLeaderLease)(nil)
@@ -0,0 +1,24 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/aq2208/gorder-api/internal/security"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisNonceStore records request nonces with SETNX + TTL.
+type RedisNonceStore struct {
+	rdb *redis.Client
+}
+
+func NewRedisNonceStore(rdb *redis.Client) *RedisNonceStore {
+	return &RedisNonceStore{rdb: rdb}
+}
+
+func (s *RedisNonceStore) Claim(ctx context.Context, scope, nonce string, ttl time.Duration) (bool, error) {
+	return s.rdb.SetNX(ctx, "replay:nonce:"+scope+":"+nonce, "1", ttl).Result()
+}
+
+var _ security.NonceStore = (*RedisNonceStore)(nil)
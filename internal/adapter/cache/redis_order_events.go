@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aq2208/gorder-api/internal/logging"
+	"github.com/aq2208/gorder-api/internal/usecase"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	orderEventsChannel = "order:events"  // pub/sub fan-out to every replica
+	orderEventsPrefix  = "order:events:" // + order id: stream with the retained history
+	orderEventsMaxLen  = 100             // per order; a handful of changes is typical
+	subscriberBuffer   = 16              // events buffered per watcher before drops
+)
+
+// RedisOrderEvents keeps each order's status changes in a capped stream (the stream IDs are the event
+// IDs used for Last-Event-ID resume) and publishes them on one pub/sub channel. Each replica holds a
+// single subscription, opened on the first Subscribe, and fans events out to its local watchers.
+// A watcher that does not keep up loses events; it can catch up via Since.
+type RedisOrderEvents struct {
+	rdb       *redis.Client
+	retention time.Duration
+
+	mu     sync.Mutex
+	pubsub *redis.PubSub
+	subs   map[string]map[chan usecase.OrderEvent]struct{}
+}
+
+func NewRedisOrderEvents(rdb *redis.Client, retention time.Duration) *RedisOrderEvents {
+	return &RedisOrderEvents{rdb: rdb, retention: retention, subs: map[string]map[chan usecase.OrderEvent]struct{}{}}
+}
+
+func (e *RedisOrderEvents) PublishStatus(ctx context.Context, orderID, status string) (usecase.OrderEvent, error) {
+	now := time.Now()
+	key := orderEventsPrefix + orderID
+	id, err := e.rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: key,
+		MaxLen: orderEventsMaxLen,
+		Approx: true,
+		Values: map[string]any{"status": status, "at": now.UnixMilli()},
+	}).Result()
+	if err != nil {
+		return usecase.OrderEvent{}, err
+	}
+	ev := usecase.OrderEvent{ID: id, OrderID: orderID, Status: status, At: now}
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return ev, err
+	}
+	_, err = e.rdb.Pipelined(ctx, func(p redis.Pipeliner) error {
+		if e.retention > 0 {
+			p.Expire(ctx, key, e.retention)
+		}
+		p.Publish(ctx, orderEventsChannel, payload)
+		return nil
+	})
+	return ev, err
+}
+
+func (e *RedisOrderEvents) Since(ctx context.Context, orderID, lastID string) ([]usecase.OrderEvent, error) {
+	start := "-"
+	if lastID != "" {
+		start = "(" + lastID
+	}
+	msgs, err := e.rdb.XRange(ctx, orderEventsPrefix+orderID, start, "+").Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]usecase.OrderEvent, 0, len(msgs))
+	for _, m := range msgs {
+		status, _ := m.Values["status"].(string)
+		ms, _ := strconv.ParseInt(toString(m.Values["at"]), 10, 64)
+		out = append(out, usecase.OrderEvent{ID: m.ID, OrderID: orderID, Status: status, At: time.UnixMilli(ms)})
+	}
+	return out, nil
+}
+
+func (e *RedisOrderEvents) Subscribe(ctx context.Context, orderID string) (<-chan usecase.OrderEvent, func()) {
+	ch := make(chan usecase.OrderEvent, subscriberBuffer)
+
+	e.mu.Lock()
+	if e.pubsub == nil {
+		e.pubsub = e.rdb.Subscribe(context.Background(), orderEventsChannel)
+		go e.dispatch(e.pubsub)
+	}
+	if e.subs[orderID] == nil {
+		e.subs[orderID] = map[chan usecase.OrderEvent]struct{}{}
+	}
+	e.subs[orderID][ch] = struct{}{}
+	e.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			e.mu.Lock()
+			delete(e.subs[orderID], ch)
+			if len(e.subs[orderID]) == 0 {
+				delete(e.subs, orderID)
+			}
+			e.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+func (e *RedisOrderEvents) dispatch(ps *redis.PubSub) {
+	l := logging.New("order-events")
+	for msg := range ps.Channel() {
+		var ev usecase.OrderEvent
+		if err := json.Unmarshal([]byte(msg.Payload), &ev); err != nil {
+			l.Warn("bad order event", "err", err)
+			continue
+		}
+		e.mu.Lock()
+		for ch := range e.subs[ev.OrderID] {
+			select {
+			case ch <- ev:
+			default:
+				l.Warn("slow watcher, event dropped", "order_id", ev.OrderID, "event_id", ev.ID)
+			}
+		}
+		e.mu.Unlock()
+	}
+}
+
+// Close ends the shared subscription (if one was opened).
+func (e *RedisOrderEvents) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.pubsub == nil {
+		return nil
+	}
+	return e.pubsub.Close()
+}
+
+func toString(v any) string {
+	s, _ := v.(string)
+	return s
+}
+
+var _ usecase.OrderEventStream = (*RedisOrderEvents)(nil)
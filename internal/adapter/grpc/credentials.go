@@ -0,0 +1,32 @@
+package grpc
+
+import (
+	"github.com/aq2208/gorder-api/configs"
+	"github.com/aq2208/gorder-api/internal/security"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// TransportCredentials builds the order-gw transport security from config: plaintext, TLS (system roots
+// or ca_cert_path), or mTLS when client_cert_path/client_key_path are set. Certificate files are watched and
+// reloaded; the returned stop func ends the watch.
+func TransportCredentials(cfg configs.Config) (credentials.TransportCredentials, func(), error) {
+	gc := cfg.GrpcServer
+	if !gc.UseTLS {
+		return insecure.NewCredentials(), func() {}, nil
+	}
+	r, err := security.NewCertReloader(gc.ClientCertPath, gc.ClientKeyPath, gc.CACertPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	stop := func() {}
+	if gc.ClientCertPath != "" || gc.CACertPath != "" {
+		if err := r.Watch(); err != nil {
+			return nil, nil, err
+		}
+		stop = func() { _ = r.Close() }
+	}
+	return credentials.NewTLS(r.ClientTLSConfig(gc.ServerName)), stop, nil
+}
+
+var ErrBadCACert = security.ErrBadCAPEM
@@ -0,0 +1,134 @@
+package fakegw
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+	"google.golang.org/grpc/codes"
+)
+
+// Behavior is how the fake answers one CreateOrder call.
+//
+//	latency/jitter   delay before answering (jitter adds a uniform random [0, jitter))
+//	error_code       gRPC code returned for error_ratio of calls, e.g. UNAVAILABLE, DEADLINE_EXCEEDED
+//	success_ratio    share of accepted orders later reported CONFIRMED; the rest are FAILED
+//	status_delay     delay between accepting an order and publishing its status change
+//	drop_status_ratio share of status changes recorded but never published (lost events, for reconciliation)
+type Behavior struct {
+	Latency      time.Duration `koanf:"latency"`
+	Jitter       time.Duration `koanf:"jitter"`
+	ErrorCode    string        `koanf:"error_code"`
+	ErrorRatio   float64       `koanf:"error_ratio"`
+	SuccessRatio float64       `koanf:"success_ratio"`
+	StatusDelay  time.Duration `koanf:"status_delay"`
+	DropStatus   float64       `koanf:"drop_status_ratio"`
+}
+
+// Match selects requests; empty fields match anything.
+type Match struct {
+	UserID   string `koanf:"user_id"`
+	Currency string `koanf:"currency"`
+	MinCents int64  `koanf:"min_cents"`
+	MaxCents int64  `koanf:"max_cents"`
+}
+
+type Rule struct {
+	Match    Match    `koanf:"match"`
+	Behavior Behavior `koanf:"behavior"`
+}
+
+// Script is the default behavior plus rules tried in order; the first matching rule wins.
+//
+//	default: {latency: 50ms, success_ratio: 0.9, status_delay: 500ms}
+//	rules:
+//	  - match: {currency: JPY}
+//	    behavior: {error_code: INVALID_ARGUMENT, error_ratio: 1}
+//	  - match: {min_cents: 1000000}
+//	    behavior: {latency: 3s, success_ratio: 0}
+type Script struct {
+	Default Behavior `koanf:"default"`
+	Rules   []Rule   `koanf:"rules"`
+}
+
+// DefaultScript accepts and confirms everything after a short delay.
+func DefaultScript() Script {
+	return Script{Default: Behavior{Latency: 20 * time.Millisecond, SuccessRatio: 1, StatusDelay: 500 * time.Millisecond}}
+}
+
+// LoadScript reads a YAML script. Unset default fields keep DefaultScript's values.
+func LoadScript(path string) (Script, error) {
+	k := koanf.New(".")
+	if err := k.Load(file.Provider(path), yaml.Parser()); err != nil {
+		return Script{}, err
+	}
+	s := DefaultScript()
+	if err := k.Unmarshal("", &s); err != nil {
+		return Script{}, err
+	}
+	if err := s.Validate(); err != nil {
+		return Script{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s Script) Validate() error {
+	check := func(where string, b Behavior) error {
+		if b.ErrorCode != "" {
+			if _, err := parseCode(b.ErrorCode); err != nil {
+				return fmt.Errorf("%s: %w", where, err)
+			}
+		}
+		if b.ErrorRatio < 0 || b.ErrorRatio > 1 || b.SuccessRatio < 0 || b.SuccessRatio > 1 || b.DropStatus < 0 || b.DropStatus > 1 {
+			return fmt.Errorf("%s: ratios must be within [0, 1]", where)
+		}
+		return nil
+	}
+	if err := check("default", s.Default); err != nil {
+		return err
+	}
+	for i, r := range s.Rules {
+		if err := check(fmt.Sprintf("rules[%d]", i), r.Behavior); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s Script) behaviorFor(userID, currency string, cents int64) Behavior {
+	for _, r := range s.Rules {
+		m := r.Match
+		if (m.UserID == "" || m.UserID == userID) &&
+			(m.Currency == "" || m.Currency == currency) &&
+			(m.MinCents == 0 || cents >= m.MinCents) &&
+			(m.MaxCents == 0 || cents <= m.MaxCents) {
+			return r.Behavior
+		}
+	}
+	return s.Default
+}
+
+func (b Behavior) delay() time.Duration {
+	d := b.Latency
+	if b.Jitter > 0 {
+		d += rand.N(b.Jitter)
+	}
+	return d
+}
+
+// parseCode accepts gRPC code names as in the spec (UNAVAILABLE) or Go style (Unavailable).
+func parseCode(name string) (codes.Code, error) {
+	var c codes.Code
+	if err := c.UnmarshalJSON([]byte(`"` + name + `"`)); err != nil {
+		for i := codes.OK; i <= codes.Unauthenticated; i++ {
+			if i.String() == name {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("unknown gRPC code %q", name)
+	}
+	return c, nil
+}
@@ -0,0 +1,186 @@
+// Package fakegw is a scriptable stand-in for order-gw: it serves the generated OrderService and, like
+// the real gateway, reports each accepted order's outcome as an OrderStatusChangedMsg.
+package fakegw
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	gwpb "github.com/aq2208/gorder-api/internal/generated"
+	"github.com/aq2208/gorder-api/internal/logging"
+	"github.com/aq2208/gorder-api/internal/usecase"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// StatusPublisher is where status changes go: kafka.StatusProducer or memory.StatusBus.
+type StatusPublisher interface {
+	Publish(ctx context.Context, ev usecase.OrderStatusChangedMsg) error
+}
+
+type Server struct {
+	gwpb.UnimplementedOrderServiceServer
+
+	script    atomic.Pointer[Script]
+	publisher StatusPublisher
+
+	mu     sync.RWMutex
+	orders map[string]*gwpb.Order // accepted orders, served by GetOrder/ListOrders
+}
+
+func NewServer(script Script, publisher StatusPublisher) *Server {
+	s := &Server{publisher: publisher, orders: map[string]*gwpb.Order{}}
+	s.SetScript(script)
+	return s
+}
+
+// SetScript swaps the behavior for subsequent calls.
+func (s *Server) SetScript(script Script) { s.script.Store(&script) }
+
+func (s *Server) CreateOrder(ctx context.Context, req *gwpb.CreateOrderRequest) (*gwpb.CreateOrderResponse, error) {
+	l := logging.New("fake-order-gw").With("order_id", req.GetOrderId())
+	if req.GetOrderId() == "" || req.GetAmountCents() <= 0 || req.GetCurrency() == "" {
+		return nil, status.Error(codes.InvalidArgument, "order_id, amount_cents and currency are required")
+	}
+	b := s.script.Load().behaviorFor(req.GetUserId(), req.GetCurrency(), req.GetAmountCents())
+
+	select {
+	case <-time.After(b.delay()):
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+
+	if b.ErrorCode != "" && rand.Float64() < b.ErrorRatio {
+		code, _ := parseCode(b.ErrorCode)
+		l.Info("scripted error", "code", code)
+		return nil, status.Errorf(code, "fake-order-gw: scripted %s", code)
+	}
+
+	// a retried CreateOrder (e.g. from the reaper) gets no second outcome, like order-gw: a final status
+	// is published again, one still pending is published when due
+	if prev, isNew := s.record(req); !isNew {
+		if prev.GetStatus() != "ACCEPTED" {
+			s.publish(l, usecase.OrderStatusChangedMsg{
+				OrderID:  prev.GetOrderId(),
+				UserID:   prev.GetUserId(),
+				Cents:    prev.GetAmountCents(),
+				Currency: prev.GetCurrency(),
+				Status:   prev.GetStatus(),
+			})
+		}
+		return &gwpb.CreateOrderResponse{Status: "ACCEPTED"}, nil
+	}
+
+	ev := usecase.OrderStatusChangedMsg{
+		OrderID:  req.GetOrderId(),
+		UserID:   req.GetUserId(),
+		Cents:    req.GetAmountCents(),
+		Currency: req.GetCurrency(),
+		Status:   "CONFIRMED",
+	}
+	if rand.Float64() >= b.SuccessRatio {
+		ev.Status = "FAILED"
+	}
+	time.AfterFunc(b.StatusDelay, func() {
+		s.setStatus(ev.OrderID, ev.Status)
+		if rand.Float64() < b.DropStatus {
+			l.Info("status change dropped", "status", ev.Status)
+			return
+		}
+		s.publish(l, ev)
+	})
+
+	return &gwpb.CreateOrderResponse{Status: "ACCEPTED"}, nil
+}
+
+func (s *Server) publish(l *slog.Logger, ev usecase.OrderStatusChangedMsg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.publisher.Publish(ctx, ev); err != nil {
+		l.Error("publish status change", "err", err)
+		return
+	}
+	l.Info("status change published", "status", ev.Status)
+}
+
+// record stores a newly accepted order as ACCEPTED. For a known order id it returns a copy of the
+// stored order and false.
+func (s *Server) record(req *gwpb.CreateOrderRequest) (*gwpb.Order, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if o, ok := s.orders[req.GetOrderId()]; ok {
+		return clone(o), false
+	}
+	s.orders[req.GetOrderId()] = &gwpb.Order{
+		OrderId:       req.GetOrderId(),
+		UserId:        req.GetUserId(),
+		AmountCents:   req.GetAmountCents(),
+		Currency:      req.GetCurrency(),
+		Status:        "ACCEPTED",
+		UpdatedAtUnix: time.Now().Unix(),
+	}
+	return nil, true
+}
+
+func (s *Server) setStatus(id, st string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if o, ok := s.orders[id]; ok {
+		o.Status = st
+		o.UpdatedAtUnix = time.Now().Unix()
+	}
+}
+
+func (s *Server) GetOrder(ctx context.Context, req *gwpb.GetOrderRequest) (*gwpb.GetOrderResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	o, ok := s.orders[req.GetOrderId()]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "order %s not found", req.GetOrderId())
+	}
+	return &gwpb.GetOrderResponse{Order: clone(o)}, nil
+}
+
+// ListOrders looks up order_ids, or pages (by order id) through orders updated since updated_since_unix.
+func (s *Server) ListOrders(ctx context.Context, req *gwpb.ListOrdersRequest) (*gwpb.ListOrdersResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	resp := &gwpb.ListOrdersResponse{}
+	if len(req.GetOrderIds()) > 0 {
+		for _, id := range req.GetOrderIds() {
+			if o, ok := s.orders[id]; ok {
+				resp.Orders = append(resp.Orders, clone(o))
+			}
+		}
+		return resp, nil
+	}
+
+	size := int(req.GetPageSize())
+	if size <= 0 || size > 1000 {
+		size = 100
+	}
+	ids := make([]string, 0, len(s.orders))
+	for id, o := range s.orders {
+		if o.GetUpdatedAtUnix() >= req.GetUpdatedSinceUnix() && id > req.GetPageToken() {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	if len(ids) > size {
+		ids = ids[:size]
+		resp.NextPageToken = ids[size-1]
+	}
+	for _, id := range ids {
+		resp.Orders = append(resp.Orders, clone(s.orders[id]))
+	}
+	return resp, nil
+}
+
+func clone(o *gwpb.Order) *gwpb.Order { return proto.Clone(o).(*gwpb.Order) }
@@ -7,7 +7,9 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 
+	domain "github.com/aq2208/gorder-api/internal/entity"
 	gwpb "github.com/aq2208/gorder-api/internal/generated"
+	"github.com/aq2208/gorder-api/internal/usecase"
 )
 
 // OrderGWClient implements the port used by your order_created_handler.
@@ -50,6 +52,52 @@ func (c *OrderGWClient) CreateOrder(ctx context.Context, orderID, userID string,
 	return err
 }
 
+// ListOrders fetches order-gw's records for ids (unknown ids are absent from the result).
+func (c *OrderGWClient) ListOrders(ctx context.Context, ids []string) (map[string]usecase.GatewayOrder, error) {
+	if _, ok := ctx.Deadline(); !ok && c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	if c.ua != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "user-agent", c.ua)
+	}
+
+	resp, err := c.cli.ListOrders(ctx, &gwpb.ListOrdersRequest{OrderIds: ids, PageSize: int32(len(ids))})
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]usecase.GatewayOrder, len(resp.GetOrders()))
+	for _, o := range resp.GetOrders() {
+		out[o.GetOrderId()] = usecase.GatewayOrder{
+			OrderID:     o.GetOrderId(),
+			Status:      gatewayStatus(o.GetStatus()),
+			RawStatus:   o.GetStatus(),
+			AmountCents: o.GetAmountCents(),
+			Currency:    o.GetCurrency(),
+		}
+	}
+	return out, nil
+}
+
+// gatewayStatus maps order-gw's vocabulary to ours: ACCEPTED is still in flight, CONFIRMED is
+// CONFIRMED and only the explicit failure statuses are FAILED. Anything else (empty, or a status
+// order-gw added later) is GatewayStatusUnknown, which reconcile reports but never acts on.
+func gatewayStatus(s string) string {
+	switch s {
+	case "ACCEPTED", "PENDING", "PROCESSING":
+		return string(domain.StatusProcessing)
+	case "CONFIRMED":
+		return string(domain.StatusConfirmed)
+	case "REJECTED", "DECLINED", "FAILED":
+		return string(domain.StatusFailed)
+	default:
+		return usecase.GatewayStatusUnknown
+	}
+}
+
+var _ usecase.OrderGatewayReader = (*OrderGWClient)(nil)
+
 // Ensure interface match at compile time (optional)
 var _ interface {
 	CreateOrder(ctx context.Context, orderID, userID string, cents int64, currency string) error
@@ -0,0 +1,70 @@
+package grpc
+
+import (
+	"time"
+
+	"github.com/aq2208/gorder-api/configs"
+	"github.com/aq2208/gorder-api/internal/usecase"
+	"github.com/google/wire"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+)
+
+// ProviderSet provides the order-gw client and binds its read side. Bind it to the worker's gateway
+// port (queue.OrderGateway) in the injector.
+var ProviderSet = wire.NewSet(
+	NewOrderGWConn,
+	ProvideOrderGWClient,
+	wire.Bind(new(usecase.OrderGatewayReader), new(*OrderGWClient)),
+)
+
+// NewOrderGWConn creates the (lazily connecting) order-gw client conn from cfg.GrpcServer.
+// The cleanup closes the conn and stops the TLS file watch.
+func NewOrderGWConn(cfg configs.Config) (*grpc.ClientConn, func(), error) {
+	dialTimeout := cfg.GrpcServer.Timeout
+	if dialTimeout <= 0 {
+		dialTimeout = 5 * time.Second
+	}
+
+	opts := []grpc.DialOption{
+		grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff: backoff.Config{
+				BaseDelay:  200 * time.Millisecond,
+				Multiplier: 1.6,
+				Jitter:     0.2,
+				MaxDelay:   5 * time.Second,
+			},
+			MinConnectTimeout: dialTimeout,
+		}),
+	}
+
+	// TLS / mTLS vs. insecure
+	creds, stopTLS, err := TransportCredentials(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts = append(opts, grpc.WithTransportCredentials(creds))
+
+	// (optional) message size knobs
+	if n := cfg.GrpcServer.MaxRecvBytes; n > 0 {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(n)))
+	}
+	if n := cfg.GrpcServer.MaxSendBytes; n > 0 {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(n)))
+	}
+
+	conn, err := grpc.NewClient(cfg.GrpcServer.Target, opts...)
+	if err != nil {
+		stopTLS()
+		return nil, nil, err
+	}
+	cleanup := func() {
+		_ = conn.Close()
+		stopTLS()
+	}
+	return conn, cleanup, nil
+}
+
+func ProvideOrderGWClient(conn *grpc.ClientConn) *OrderGWClient {
+	return NewOrderGWClientFromConn(conn, 8*time.Second, "go-order-api/worker")
+}
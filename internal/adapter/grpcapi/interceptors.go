@@ -0,0 +1,209 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aq2208/gorder-api/internal/adapter/http/middleware"
+	pb "github.com/aq2208/gorder-api/internal/generated/orderapipb"
+	"github.com/aq2208/gorder-api/internal/logging"
+	"github.com/aq2208/gorder-api/internal/usecase"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	grpcRequests = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_server_requests_total",
+			Help: "Total number of gRPC API calls",
+		},
+		[]string{"method", "code"},
+	)
+
+	grpcDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "grpc_server_request_duration_ms",
+			Help:    "Duration of gRPC API calls in ms (streams: until the stream ends)",
+			Buckets: []float64{5, 10, 25, 50, 100, 200, 400, 800, 1600},
+		},
+		[]string{"method"},
+	)
+)
+
+// methodPerms lists the permissions each OrderAPI method requires. Methods of other services are
+// rejected unless they are public (health, reflection).
+var methodPerms = map[string][]string{
+	pb.OrderAPI_CreateOrder_FullMethodName: {usecase.PermOrdersWrite},
+	pb.OrderAPI_GetOrder_FullMethodName:    {usecase.PermOrdersRead},
+	pb.OrderAPI_ListOrders_FullMethodName:  {usecase.PermOrdersRead},
+	pb.OrderAPI_CancelOrder_FullMethodName: {usecase.PermOrdersWrite},
+	pb.OrderAPI_WatchOrder_FullMethodName:  {usecase.PermOrdersRead},
+}
+
+func isPublic(method string) bool {
+	return strings.HasPrefix(method, "/grpc.health.v1.") || strings.HasPrefix(method, "/grpc.reflection.")
+}
+
+// Auth authenticates "authorization: Bearer <jwt>" metadata with the HTTP API's token rules, checks the
+// method's permissions and the caller's rate limit, and stores the principal for the use cases.
+type Auth struct {
+	authz *middleware.Authz
+	rl    *middleware.RateLimiter
+}
+
+func NewAuth(authz *middleware.Authz, rl *middleware.RateLimiter) *Auth {
+	return &Auth{authz: authz, rl: rl}
+}
+
+func (a *Auth) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if isPublic(method) {
+		return ctx, nil
+	}
+	perms, ok := methodPerms[method]
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "unknown method")
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	var raw string
+	if v := md.Get("authorization"); len(v) > 0 {
+		raw, ok = strings.CutPrefix(v[0], "Bearer ")
+	}
+	if !ok || raw == "" {
+		return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+	p, err := a.authz.Authenticate(raw)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	for _, perm := range perms {
+		if !p.Has(perm) {
+			return nil, status.Error(codes.PermissionDenied, "missing required permissions")
+		}
+	}
+	if ok, wait := a.rl.Allow("client:" + p.ClientID); !ok {
+		_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(int(math.Ceil(wait.Seconds())))))
+		return nil, status.Error(codes.ResourceExhausted, "rate_limited")
+	}
+	return usecase.WithPrincipal(ctx, p), nil
+}
+
+func (a *Auth) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := a.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (a *Auth) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &ctxStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// ctxStream replaces a stream's context (interceptors cannot change it otherwise).
+type ctxStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *ctxStream) Context() context.Context { return s.ctx }
+
+// LoggingUnary logs every call with its code and duration and injects a request-scoped logger
+// (x-request-id metadata, or a new id), like the HTTP Logging middleware. Message bodies are not logged.
+func LoggingUnary() grpc.UnaryServerInterceptor {
+	base := logging.New("grpc")
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, done := observe(ctx, base, info.FullMethod)
+		resp, err := handler(ctx, req)
+		done(err)
+		return resp, err
+	}
+}
+
+func LoggingStream() grpc.StreamServerInterceptor {
+	base := logging.New("grpc")
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, done := observe(ss.Context(), base, info.FullMethod)
+		err := handler(srv, &ctxStream{ServerStream: ss, ctx: ctx})
+		done(err)
+		return err
+	}
+}
+
+// observe starts the log/metrics record of one call; the returned func finishes it.
+func observe(ctx context.Context, base *slog.Logger, method string) (context.Context, func(error)) {
+	start := time.Now()
+	reqID := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get("x-request-id"); len(v) > 0 {
+			reqID = v[0]
+		}
+	}
+	if reqID == "" {
+		reqID = uuid.NewString()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs("x-request-id", reqID))
+
+	l := base.With("req_id", reqID, "method", method)
+	if p, ok := peer.FromContext(ctx); ok {
+		l = l.With("remote", p.Addr.String())
+	}
+	return logging.WithCtx(ctx, l), func(err error) {
+		code := status.Code(err)
+		grpcRequests.WithLabelValues(method, code.String()).Inc()
+		grpcDuration.WithLabelValues(method).Observe(float64(time.Since(start).Milliseconds()))
+
+		attrs := []any{"code", code.String(), "duration_ms", time.Since(start).Milliseconds()}
+		switch code {
+		case codes.OK:
+			l.Info("grpc call", attrs...)
+		case codes.Internal, codes.Unknown, codes.DataLoss:
+			l.Error("grpc call", append(attrs, "error", err.Error())...)
+		default:
+			l.Warn("grpc call", append(attrs, "error", err.Error())...)
+		}
+	}
+}
+
+// RecoveryUnary turns a handler panic into INTERNAL instead of crashing the process.
+func RecoveryUnary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer recoverTo(ctx, info.FullMethod, &err)
+		return handler(ctx, req)
+	}
+}
+
+func RecoveryStream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer recoverTo(ss.Context(), info.FullMethod, &err)
+		return handler(srv, ss)
+	}
+}
+
+func recoverTo(ctx context.Context, method string, err *error) {
+	if r := recover(); r != nil {
+		logging.FromCtx(ctx).Error("grpc panic", "method", method, "panic", fmt.Sprint(r), "stack", string(debug.Stack()))
+		*err = status.Error(codes.Internal, "internal error")
+	}
+}
@@ -0,0 +1,166 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	domain "github.com/aq2208/gorder-api/internal/entity"
+	pb "github.com/aq2208/gorder-api/internal/generated/orderapipb"
+	"github.com/aq2208/gorder-api/internal/usecase"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// OrderAPI implements the OrderAPI gRPC service on the same use cases as the HTTP handlers. The caller
+// has already been authenticated by the interceptors (see Auth).
+type OrderAPI struct {
+	pb.UnimplementedOrderAPIServer
+	create *usecase.CreateOrder
+	get    *usecase.GetOrder
+	list   *usecase.ListOrders
+	cancel *usecase.CancelOrder
+	watch  *usecase.WatchOrder
+}
+
+func NewOrderAPI(create *usecase.CreateOrder, get *usecase.GetOrder, list *usecase.ListOrders,
+	cancel *usecase.CancelOrder, watch *usecase.WatchOrder) *OrderAPI {
+	return &OrderAPI{create: create, get: get, list: list, cancel: cancel, watch: watch}
+}
+
+func (s *OrderAPI) CreateOrder(ctx context.Context, req *pb.CreateOrderRequest) (*pb.CreateOrderResponse, error) {
+	out, err := s.create.Execute(ctx, usecase.CreateOrderInput{
+		UserID:         req.GetUserId(),
+		IdempotencyKey: req.GetIdempotencyKey(),
+		AmountCents:    req.GetAmountCents(),
+		Currency:       req.GetCurrency(),
+		ItemsJSON:      req.GetItemsJson(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.CreateOrderResponse{OrderId: out.OrderID, Status: out.Status}, nil
+}
+
+func (s *OrderAPI) GetOrder(ctx context.Context, req *pb.GetOrderRequest) (*pb.Order, error) {
+	rec, err := s.get.Execute(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toOrder(rec), nil
+}
+
+func (s *OrderAPI) ListOrders(ctx context.Context, req *pb.ListOrdersRequest) (*pb.ListOrdersResponse, error) {
+	out, err := s.list.Execute(ctx, usecase.ListOrdersInput{
+		UserID:    req.GetUserId(),
+		Status:    req.GetStatus(),
+		PageSize:  int(req.GetPageSize()),
+		PageToken: req.GetPageToken(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp := &pb.ListOrdersResponse{NextPageToken: out.NextPageToken}
+	for i := range out.Orders {
+		resp.Orders = append(resp.Orders, toOrder(&out.Orders[i]))
+	}
+	return resp, nil
+}
+
+func (s *OrderAPI) CancelOrder(ctx context.Context, req *pb.CancelOrderRequest) (*pb.Order, error) {
+	rec, err := s.cancel.ExecuteIf(ctx, req.GetId(), usecase.MatchETags(req.GetEtag()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toOrder(rec), nil
+}
+
+// WatchOrder mirrors the SSE stream: the current state (or, with last_event_id, the missed events),
+// then live changes until a final status. A dropped subscription ends the stream with UNAVAILABLE so
+// the client resumes with the last event id it saw.
+func (s *OrderAPI) WatchOrder(req *pb.WatchOrderRequest, stream pb.OrderAPI_WatchOrderServer) error {
+	ctx := stream.Context()
+	w, err := s.watch.Watch(ctx, req.GetId(), req.GetLastEventId())
+	if err != nil {
+		return toStatus(err)
+	}
+	defer w.Close()
+
+	order := w.Order
+	if w.Backlog == nil {
+		snap := &pb.OrderEvent{EventId: w.LastID, OrderId: order.ID, Status: order.Status, At: timestamppb.New(order.UpdatedAt)}
+		if err := stream.Send(snap); err != nil {
+			return err
+		}
+	}
+	for _, ev := range w.Backlog {
+		if err := stream.Send(toEvent(ev)); err != nil {
+			return err
+		}
+	}
+	// the state was read after subscribing, so a final order has nothing more to send
+	if domain.Status(order.Status).IsFinal() {
+		return nil
+	}
+
+	for {
+		select {
+		case ev, ok := <-w.Events:
+			if !ok {
+				if ctx.Err() != nil {
+					return status.FromContextError(ctx.Err()).Err()
+				}
+				return status.Error(codes.Unavailable, "watch interrupted, resume with last_event_id")
+			}
+			if err := stream.Send(toEvent(ev)); err != nil {
+				return err
+			}
+			if domain.Status(ev.Status).IsFinal() {
+				return nil
+			}
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+}
+
+func toOrder(rec *usecase.OrderRecord) *pb.Order {
+	return &pb.Order{
+		Id:            rec.ID,
+		UserId:        rec.UserID,
+		Status:        rec.Status,
+		AmountCents:   rec.AmountCents,
+		Currency:      rec.Currency,
+		ItemsJson:     rec.ItemsJSON,
+		FailureReason: rec.FailureReason,
+		CreatedAt:     timestamppb.New(rec.CreatedAt),
+		UpdatedAt:     timestamppb.New(rec.UpdatedAt),
+		Etag:          rec.ETag(),
+	}
+}
+
+func toEvent(ev usecase.OrderEvent) *pb.OrderEvent {
+	return &pb.OrderEvent{EventId: ev.ID, OrderId: ev.OrderID, Status: ev.Status, At: timestamppb.New(ev.At)}
+}
+
+// toStatus maps use case errors to gRPC codes, as the HTTP handlers map them to status codes.
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrForbidden):
+		return status.Error(codes.PermissionDenied, "forbidden")
+	case errors.Is(err, usecase.ErrNotFound):
+		return status.Error(codes.NotFound, "not found")
+	case errors.Is(err, usecase.ErrValidation), errors.Is(err, usecase.ErrInvalidAmount),
+		errors.Is(err, usecase.ErrInvalidListQuery):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrDuplicate):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, usecase.ErrNotCancellable):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, usecase.ErrPreconditionFailed), errors.Is(err, usecase.ErrVersionConflict):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return status.FromContextError(err).Err()
+	}
+	return status.Error(codes.Internal, err.Error())
+}
@@ -0,0 +1,11 @@
+package grpcapi
+
+import "github.com/google/wire"
+
+// ProviderSet provides the gRPC API server; the use cases, Authz and the rate limiter come from the
+// HTTP and use case sets.
+var ProviderSet = wire.NewSet(
+	NewOrderAPI,
+	NewAuth,
+	NewServer,
+)
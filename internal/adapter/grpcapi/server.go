@@ -0,0 +1,88 @@
+package grpcapi
+
+import (
+	"crypto/tls"
+	"net"
+	"time"
+
+	"github.com/aq2208/gorder-api/configs"
+	pb "github.com/aq2208/gorder-api/internal/generated/orderapipb"
+	"github.com/aq2208/gorder-api/internal/security"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// Server is the gRPC API listener on grpc_api.addr: OrderAPI, grpc.health.v1 and server reflection.
+type Server struct {
+	*grpc.Server
+	Health *health.Server
+	addr   string
+	tls    bool
+}
+
+// NewServer builds the server. When http.tls.cert_file is set it serves TLS with the same (hot
+// reloaded) certificate as the HTTP API; callers are authenticated by token only, not by client
+// certificate. The cleanup stops the server gracefully and the certificate watch.
+func NewServer(cfg configs.Config, api *OrderAPI, auth *Auth) (*Server, func(), error) {
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(LoggingUnary(), RecoveryUnary(), auth.Unary()),
+		grpc.ChainStreamInterceptor(LoggingStream(), RecoveryStream(), auth.Stream()),
+	}
+	stopTLS := func() {}
+	if tc := cfg.HTTP.TLS; tc.CertFile != "" {
+		r, err := security.NewCertReloader(tc.CertFile, tc.KeyFile, "")
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := r.Watch(); err != nil {
+			return nil, nil, err
+		}
+		stopTLS = func() { _ = r.Close() }
+		opts = append(opts, grpc.Creds(credentials.NewTLS(r.ServerTLSConfig(tls.NoClientCert, "h2"))))
+	}
+
+	s := &Server{Server: grpc.NewServer(opts...), Health: health.NewServer(), addr: cfg.GrpcAPI.Addr, tls: cfg.HTTP.TLS.CertFile != ""}
+	pb.RegisterOrderAPIServer(s.Server, api)
+	healthpb.RegisterHealthServer(s.Server, s.Health)
+	reflection.Register(s.Server)
+	s.Health.SetServingStatus(pb.OrderAPI_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+
+	cleanup := func() {
+		s.Shutdown(5 * time.Second)
+		stopTLS()
+	}
+	return s, cleanup, nil
+}
+
+func (s *Server) Addr() string { return s.addr }
+
+// TLS reports whether the server listens with TLS.
+func (s *Server) TLS() bool { return s.tls }
+
+// ListenAndServe serves on the configured address until Shutdown.
+func (s *Server) ListenAndServe() error {
+	lis, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(lis)
+}
+
+// Shutdown reports NOT_SERVING, then waits up to timeout for running calls (open WatchOrder streams
+// included) before closing the remaining connections.
+func (s *Server) Shutdown(timeout time.Duration) {
+	s.Health.Shutdown()
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		s.Stop()
+	}
+}
@@ -0,0 +1,177 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aq2208/gorder-api/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// JobHandler serves /v1/jobs: bulk order imports and exports of the calling client.
+type JobHandler struct {
+	uc *usecase.ManageJobs
+}
+
+func NewJobHandler(uc *usecase.ManageJobs) *JobHandler {
+	return &JobHandler{uc: uc}
+}
+
+type exportReq struct {
+	Format string `json:"format" binding:"required"`
+	Filter struct {
+		UserID      string    `json:"user_id"`
+		Status      string    `json:"status"`
+		CreatedFrom time.Time `json:"created_from"`
+		CreatedTo   time.Time `json:"created_to"`
+	} `json:"filter"`
+}
+
+type jobResp struct {
+	ID         string         `json:"id"`
+	Kind       string         `json:"kind"`
+	Format     string         `json:"format"`
+	Status     string         `json:"status"`
+	Total      int            `json:"total"`
+	Processed  int            `json:"processed"`
+	Succeeded  int            `json:"succeeded,omitempty"`
+	Failed     int            `json:"failed,omitempty"`
+	Progress   float64        `json:"progress"`
+	Error      string         `json:"error,omitempty"`
+	Filter     map[string]any `json:"filter,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	StartedAt  *time.Time     `json:"started_at,omitempty"`
+	FinishedAt *time.Time     `json:"finished_at,omitempty"`
+}
+
+// Import handles POST /v1/jobs/import?format=csv|ndjson; the (decrypted) request body is the file.
+func (h *JobHandler) Import(c *gin.Context) {
+	j, err := h.uc.SubmitImport(c.Request.Context(), c.Query("format"), c.Request.Body)
+	if err != nil {
+		jobError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, toJobResp(*j))
+}
+
+// Export handles POST /v1/jobs/export with {format: csv|ndjson|parquet, filter: {...}}.
+func (h *JobHandler) Export(c *gin.Context) {
+	var req exportReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad_request"})
+		return
+	}
+	f := usecase.OrderFilter{UserID: req.Filter.UserID, Status: req.Filter.Status,
+		CreatedFrom: req.Filter.CreatedFrom, CreatedTo: req.Filter.CreatedTo}
+	j, err := h.uc.SubmitExport(c.Request.Context(), req.Format, f)
+	if err != nil {
+		jobError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, toJobResp(*j))
+}
+
+// List handles GET /v1/jobs?limit=50.
+func (h *JobHandler) List(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	jobs, err := h.uc.List(c.Request.Context(), limit)
+	if err != nil {
+		jobError(c, err)
+		return
+	}
+	out := make([]jobResp, 0, len(jobs))
+	for _, j := range jobs {
+		out = append(out, toJobResp(j))
+	}
+	c.JSON(http.StatusOK, gin.H{"jobs": out})
+}
+
+// Get returns a job's status and progress.
+func (h *JobHandler) Get(c *gin.Context) {
+	j, err := h.uc.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		jobError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toJobResp(*j))
+}
+
+// Result downloads a completed export.
+func (h *JobHandler) Result(c *gin.Context) {
+	j, rc, err := h.uc.Result(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		jobError(c, err)
+		return
+	}
+	defer rc.Close()
+	ct, ext := "application/x-ndjson", "ndjson"
+	switch j.Format {
+	case usecase.FormatCSV:
+		ct, ext = "text/csv", "csv"
+	case usecase.FormatParquet:
+		ct, ext = "application/vnd.apache.parquet", "parquet"
+	}
+	c.DataFromReader(http.StatusOK, -1, ct, rc, map[string]string{
+		"Content-Disposition": `attachment; filename="orders-` + j.ID + "." + ext + `"`,
+	})
+}
+
+// Errors downloads an import's error report: one {line, error, message} object per rejected row.
+func (h *JobHandler) Errors(c *gin.Context) {
+	_, rc, err := h.uc.ErrorReport(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		jobError(c, err)
+		return
+	}
+	defer rc.Close()
+	c.DataFromReader(http.StatusOK, -1, "application/x-ndjson", rc, nil)
+}
+
+func toJobResp(j usecase.Job) jobResp {
+	out := jobResp{
+		ID: j.ID, Kind: j.Kind, Format: j.Format, Status: j.Status,
+		Total: j.Total, Processed: j.Processed, Succeeded: j.Succeeded, Failed: j.Failed,
+		Progress: j.Progress(), Error: j.Error, CreatedAt: j.CreatedAt,
+	}
+	if j.Kind == usecase.JobExport {
+		out.Filter = map[string]any{}
+		f := j.Filter
+		for k, v := range map[string]string{"user_id": f.UserID, "tenant_id": f.TenantID, "status": f.Status} {
+			if v != "" {
+				out.Filter[k] = v
+			}
+		}
+		if !f.CreatedFrom.IsZero() {
+			out.Filter["created_from"] = f.CreatedFrom
+		}
+		if !f.CreatedTo.IsZero() {
+			out.Filter["created_to"] = f.CreatedTo
+		}
+	}
+	if !j.StartedAt.IsZero() {
+		out.StartedAt = &j.StartedAt
+	}
+	if !j.FinishedAt.IsZero() {
+		out.FinishedAt = &j.FinishedAt
+	}
+	return out
+}
+
+func jobError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+	case errors.Is(err, usecase.ErrJobNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not_found"})
+	case errors.Is(err, usecase.ErrJobNotFinished):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrUploadTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrInvalidJob):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/aq2208/gorder-api/configs"
+	"github.com/aq2208/gorder-api/internal/adapter/http/middleware"
 	"github.com/aq2208/gorder-api/internal/security"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -34,18 +35,29 @@ func (h *TokenHandler) IssueToken(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid client"})
 		return
 	}
+	if certID, ok := middleware.CertClientID(c); ok && certID != clientID {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid client"})
+		return
+	}
 
 	perms := cl.Perms
 	now := time.Now()
 	claims := jwt.MapClaims{
-		"iss":      h.cfg.Security.Issuer,                            // issuer
-		"aud":      h.cfg.Security.Audience,                          // audience
-		"iat":      now.Unix(),                                       // issued at
-		"nbf":      now.Unix(),                                       // not before
-		"exp":      now.Add(h.cfg.Security.TTL * time.Minute).Unix(), // expire
+		"iss":      h.cfg.Security.Issuer,              // issuer
+		"aud":      h.cfg.Security.Audience,            // audience
+		"iat":      now.Unix(),                         // issued at
+		"nbf":      now.Unix(),                         // not before
+		"exp":      now.Add(h.cfg.Security.TTL).Unix(), // expire
 		"clientID": clientID,
 		"perms":    perms,
 	}
+	// optional end-user / tenant binding
+	if cl.UserID != "" {
+		claims["sub"] = cl.UserID
+	}
+	if cl.Tenant != "" {
+		claims["tenant"] = cl.Tenant
+	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	signed, err := token.SignedString([]byte(h.cfg.Security.JWTSecret))
 	if err != nil {
@@ -56,6 +68,6 @@ func (h *TokenHandler) IssueToken(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"access_token": signed,
 		"token_type":   "Bearer",
-		"expires_in":   h.cfg.Security.TTL,
+		"expires_in":   int64(h.cfg.Security.TTL / time.Second),
 	})
 }
@@ -1,11 +1,13 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/aq2208/gorder-api/configs"
+	"github.com/aq2208/gorder-api/internal/usecase"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -27,52 +29,90 @@ func (a *Authz) Require(requiredPerms ...string) gin.HandlerFunc {
 		}
 
 		raw := strings.TrimPrefix(auth, "Bearer ")
-		token, err := jwt.Parse(raw, func(t *jwt.Token) (any, error) {
-			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(a.cfg.Security.JWTSecret), nil
-		}, jwt.WithLeeway(30*time.Second)) // small clock skew
-
-		if err != nil || !token.Valid {
-			unauth(c, "invalid_token", "invalid jwt")
-			return
-		}
-
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			unauth(c, "invalid_token", "claims parsing error")
+		p, err := a.Authenticate(raw)
+		if err != nil {
+			unauth(c, "invalid_token", err.Error())
 			return
 		}
 
-		if claims["iss"] != a.cfg.Security.Issuer || claims["aud"] != a.cfg.Security.Audience {
-			unauth(c, "invalid_token", "iss/aud mismatch")
+		// mTLS: the token must have been issued to the client holding the certificate
+		if certID, ok := CertClientID(c); ok && certID != p.ClientID {
+			unauth(c, "invalid_token", "token not bound to client certificate")
 			return
 		}
 
-		perms := extractPerms(claims)
-		if !hasAll(perms, requiredPerms) {
+		if !hasAll(p.Perms, requiredPerms) {
 			forbidden(c, "insufficient_scope", "missing required permissions")
 			return
 		}
 
+		// expose the caller to the use cases (ownership / tenant policy)
+		c.Set(principalKey, p)
+		c.Request = c.Request.WithContext(usecase.WithPrincipal(c.Request.Context(), p))
+
 		c.Next()
 	}
 }
 
-func extractPerms(claims jwt.MapClaims) map[string]string {
-	out := map[string]string{}
+// Authenticate verifies an access token issued by /v1/token and returns its caller. Transports other
+// than HTTP (the gRPC API) use it with their own credential extraction.
+func (a *Authz) Authenticate(raw string) (usecase.Principal, error) {
+	token, err := jwt.Parse(raw, func(t *jwt.Token) (any, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return []byte(a.cfg.Security.JWTSecret), nil
+	}, jwt.WithLeeway(30*time.Second)) // small clock skew
+	if err != nil || !token.Valid {
+		return usecase.Principal{}, errors.New("invalid jwt")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return usecase.Principal{}, errors.New("claims parsing error")
+	}
+	if claims["iss"] != a.cfg.Security.Issuer || claims["aud"] != a.cfg.Security.Audience {
+		return usecase.Principal{}, errors.New("iss/aud mismatch")
+	}
+
+	return usecase.Principal{
+		ClientID: stringClaim(claims, "clientID"),
+		Subject:  stringClaim(claims, "sub"),
+		Tenant:   stringClaim(claims, "tenant"),
+		Perms:    extractPerms(claims),
+	}, nil
+}
+
+const principalKey = "principal"
+
+// PrincipalFrom returns the caller authenticated by Require.
+func PrincipalFrom(c *gin.Context) (usecase.Principal, bool) {
+	v, ok := c.Get(principalKey)
+	if !ok {
+		return usecase.Principal{}, false
+	}
+	p, ok := v.(usecase.Principal)
+	return p, ok
+}
+
+func extractPerms(claims jwt.MapClaims) map[string]struct{} {
+	out := map[string]struct{}{}
 	if arr, ok := claims["perms"].([]any); ok {
 		for _, v := range arr {
 			if s, ok := v.(string); ok && s != "" {
-				out[s] = ""
+				out[s] = struct{}{}
 			}
 		}
 	}
 	return out
 }
 
-func hasAll(have map[string]string, req []string) bool {
+func stringClaim(claims jwt.MapClaims, name string) string {
+	s, _ := claims[name].(string)
+	return s
+}
+
+func hasAll(have map[string]struct{}, req []string) bool {
 	for _, r := range req {
 		if _, ok := have[r]; !ok {
 			return false
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/aq2208/gorder-api/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+const certClientKey = "cert_client_id"
+
+// ClientCert maps a verified mTLS client certificate to a registry client. Requests without a verified
+// certificate pass through untouched; a verified certificate that matches no client is rejected.
+// Authz then requires the bearer token to belong to the same client.
+func ClientCert() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		st := c.Request.TLS
+		if st == nil || len(st.VerifiedChains) == 0 || len(st.VerifiedChains[0]) == 0 {
+			c.Next()
+			return
+		}
+		cl, ok := security.ClientByCert(st.VerifiedChains[0][0])
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "unknown_client_certificate"})
+			return
+		}
+		c.Set(certClientKey, cl.ID)
+		c.Next()
+	}
+}
+
+// CertClientID returns the client identified by the mTLS certificate, if any.
+func CertClientID(c *gin.Context) (string, bool) {
+	return c.GetString(certClientKey), c.GetString(certClientKey) != ""
+}
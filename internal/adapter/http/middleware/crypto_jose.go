@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"net/http"
+	"strings"
+
+	"github.com/aq2208/gorder-api/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// MediaTypeJOSE is the content type of a compact JWE request body.
+	MediaTypeJOSE = "application/jose"
+	// HeaderJWSSignature carries a detached JWS ("<header>..<signature>") over the raw JSON body.
+	HeaderJWSSignature = "X-JWS-Signature"
+)
+
+func isJOSEContentType(ct string) bool { return ct == MediaTypeJOSE }
+
+// openDetachedJWS verifies a plaintext JSON body against the detached JWS in X-JWS-Signature.
+// The protected header must carry kid, iat, nonce, htm and htu.
+func (cv *CryptoVerify) openDetachedJWS(c *gin.Context, clientID string, rawBody []byte) *opened {
+	jws := strings.TrimSpace(c.GetHeader(HeaderJWSSignature))
+	key, h, payload := cv.verifyJWS(c, clientID, jws, rawBody)
+	if key == nil {
+		return nil
+	}
+	return &opened{
+		plaintext: payload,
+		key:       key,
+		env:       requestEnvelope(c, security.LatestEnvelopeVersion, clientID, key),
+		ts:        h.Iat,
+		nonce:     h.Nonce,
+	}
+}
+
+// openJWE decrypts a compact JWE addressed to the server key. The plaintext must be a compact JWS
+// signed by the client (sign-then-encrypt), otherwise the sender would be unauthenticated.
+func (cv *CryptoVerify) openJWE(c *gin.Context, clientID string, rawBody []byte) *opened {
+	if cv.signer == nil {
+		c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, gin.H{"error": "JWE not available (no server key)"})
+		return nil
+	}
+	_, inner, err := security.DecryptJWE(cv.signer, string(bytes.TrimSpace(rawBody)))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "decryption failed"})
+		return nil
+	}
+	key, h, payload := cv.verifyJWS(c, clientID, string(inner), nil)
+	if key == nil {
+		return nil
+	}
+	return &opened{
+		plaintext: payload,
+		key:       key,
+		env:       requestEnvelope(c, security.LatestEnvelopeVersion, clientID, key),
+		ts:        h.Iat,
+		nonce:     h.Nonce,
+	}
+}
+
+// verifyJWS resolves the client key by kid and checks the signature and route binding. htm and htu are
+// required: JOSE requests count as the latest envelope version, which binds method and path.
+// detached is the payload for a detached JWS, nil for an attached one. On failure it aborts and returns a nil key.
+func (cv *CryptoVerify) verifyJWS(c *gin.Context, clientID, jws string, detached []byte) (*security.ClientKey, security.JOSEHeader, []byte) {
+	h, err := security.PeekJWSHeader(jws)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid JWS"})
+		return nil, h, nil
+	}
+	key, err := cv.lookupKey(c, clientID, h.Kid)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unknown key", "detail": err.Error()})
+		return nil, h, nil
+	}
+	h, payload, err := security.VerifyJWS(key.Service, jws, detached)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "signature verification failed"})
+		return nil, h, nil
+	}
+	if h.Htm == "" || h.Htu == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "JWS must bind htm and htu"})
+		return nil, h, nil
+	}
+	if !strings.EqualFold(h.Htm, c.Request.Method) || h.Htu != c.Request.URL.Path {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "JWS bound to a different route"})
+		return nil, h, nil
+	}
+	return key, h, payload
+}
+
+// writeJOSE is the /_test helper side: format=jws returns the body plus a detached JWS to send in
+// X-JWS-Signature; format=jwe returns a compact JWE to send with Content-Type: application/jose.
+func (cv *CryptoVerify) writeJOSE(c *gin.Context, format string, key *security.ClientKey, env security.EnvelopeContext, plaintext []byte) {
+	ts, nonce, err := freshNonce()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "nonce failed", "detail": err.Error()})
+		return
+	}
+	h := security.JOSEHeader{Kid: key.KeyID, Iat: ts, Nonce: nonce, Htm: strings.ToUpper(env.Method), Htu: env.Path}
+	jws, err := security.SignJWS(key.Service, h, plaintext)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "sign failed (need client private key)", "detail": err.Error()})
+		return
+	}
+
+	if format == "jws" {
+		c.JSON(http.StatusOK, gin.H{"payload": string(plaintext), "jws": security.DetachJWS(jws)})
+		return
+	}
+
+	var pub *rsa.PublicKey
+	if cv.signer != nil {
+		pub, _ = cv.signer.PublicKey().(*rsa.PublicKey)
+	}
+	if pub == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "JWE needs an RSA server key"})
+		return
+	}
+	jwe, err := security.EncryptJWE(pub, security.JOSEHeader{Cty: "JWT"}, []byte(jws))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "encrypt failed", "detail": err.Error()})
+		return
+	}
+	c.Data(http.StatusOK, MediaTypeJOSE, []byte(jwe))
+}
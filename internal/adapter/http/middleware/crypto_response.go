@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/aq2208/gorder-api/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// HeaderEncryptResponse opts a single request into the encrypted response envelope ("true"/"1").
+	HeaderEncryptResponse = "X-Encrypt-Response"
+	// HeaderResponseEncrypted marks a response body as an EncryptedResponse.
+	HeaderResponseEncrypted = "X-Response-Encrypted"
+	// MediaTypeEncrypted can be sent in Accept instead of the header.
+	MediaTypeEncrypted = "application/vnd.gorder.encrypted+json"
+)
+
+// EncryptedResponse mirrors EncryptedRequest: the body is AES-256-GCM encrypted with the caller's key (kid)
+// and signed with the server's private key (sig_kid). It uses the same envelope version as the request.
+type EncryptedResponse struct {
+	Version     int    `json:"v,omitempty"`
+	Alg         string `json:"alg"`
+	KeyID       string `json:"kid"`
+	Data        string `json:"data"`
+	Timestamp   int64  `json:"ts"`
+	Nonce       string `json:"nonce"`
+	SignerKeyID string `json:"sig_kid"`
+	Signature   string `json:"signature"`
+}
+
+// wantsEncryptedResponse: per-request header / Accept negotiation, or forced per client in the registry.
+func wantsEncryptedResponse(c *gin.Context, clientID string) bool {
+	if v := strings.ToLower(c.GetHeader(HeaderEncryptResponse)); v == "true" || v == "1" {
+		return true
+	}
+	if strings.Contains(c.GetHeader("Accept"), MediaTypeEncrypted) {
+		return true
+	}
+	cl, ok := security.Clients[clientID]
+	return ok && cl.EncryptResponses
+}
+
+// responseBuffer holds the handler's response so it can be sealed before anything reaches the client.
+type responseBuffer struct {
+	gin.ResponseWriter
+	status int
+	buf    bytes.Buffer
+}
+
+func (w *responseBuffer) WriteHeader(code int)              { w.status = code }
+func (w *responseBuffer) WriteHeaderNow()                   {}
+func (w *responseBuffer) Write(b []byte) (int, error)       { return w.buf.Write(b) }
+func (w *responseBuffer) WriteString(s string) (int, error) { return w.buf.WriteString(s) }
+func (w *responseBuffer) Status() int                       { return w.status }
+func (w *responseBuffer) Size() int                         { return w.buf.Len() }
+func (w *responseBuffer) Written() bool                     { return w.buf.Len() > 0 }
+
+// Unwrap lets http.ResponseController reach the connection (long-poll extends the write deadline).
+func (w *responseBuffer) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// sealResponse replaces the buffered plaintext response with an EncryptedResponse.
+func (cv *CryptoVerify) sealResponse(c *gin.Context, out gin.ResponseWriter, rb *responseBuffer, key *security.ClientKey, env security.EnvelopeContext) {
+	c.Writer = out
+
+	sealed, err := cv.seal(key, env, rb.buf.Bytes())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "response encryption failed"})
+		return
+	}
+	c.Header(HeaderResponseEncrypted, "aes-256-gcm")
+	c.JSON(rb.status, sealed)
+}
+
+func (cv *CryptoVerify) seal(key *security.ClientKey, env security.EnvelopeContext, plaintext []byte) (EncryptedResponse, error) {
+	aad := env.ResponseAAD()
+	ct, err := key.Service.EncryptWithAAD(plaintext, aad)
+	if err != nil {
+		return EncryptedResponse{}, err
+	}
+	ts, nonce, err := freshNonce()
+	if err != nil {
+		return EncryptedResponse{}, err
+	}
+	sig, err := cv.signer.Sign(security.SigningInputAAD(env.Version, ts, nonce, aad, ct))
+	if err != nil {
+		return EncryptedResponse{}, err
+	}
+	resp := EncryptedResponse{
+		Alg:         string(cv.signer.Alg),
+		KeyID:       key.KeyID,
+		Data:        base64.StdEncoding.EncodeToString(ct),
+		Timestamp:   ts,
+		Nonce:       nonce,
+		SignerKeyID: cv.signer.KeyID,
+		Signature:   base64.StdEncoding.EncodeToString(sig),
+	}
+	if env.Version >= security.EnvelopeV2 {
+		resp.Version = env.Version
+	}
+	return resp, nil
+}
+
+// DecryptResponse body: EncryptedResponse - verifies the server signature and returns the plaintext (test helper).
+// Query: client_id like EncryptAndSign; for v2 responses also method + path of the original request.
+func (cv *CryptoVerify) DecryptResponse() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var resp EncryptedResponse
+		if err := c.ShouldBindJSON(&resp); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON", "detail": err.Error()})
+			return
+		}
+		if cv.signer == nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server signing key not configured"})
+			return
+		}
+		key, env, err := cv.helperEnvelope(c, resp.KeyID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid key/envelope", "detail": err.Error()})
+			return
+		}
+		if env.Version, err = security.NormalizeEnvelopeVersion(resp.Version, security.EnvelopeV1); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported envelope version"})
+			return
+		}
+		ct, err := base64.StdEncoding.DecodeString(resp.Data)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ciphertext encoding"})
+			return
+		}
+		sig, err := base64.StdEncoding.DecodeString(resp.Signature)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid signature encoding"})
+			return
+		}
+		aad := env.ResponseAAD()
+		if err := cv.signer.Verify(security.SigningInputAAD(env.Version, resp.Timestamp, resp.Nonce, aad, ct), sig); err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "signature verification failed"})
+			return
+		}
+		pt, err := key.Service.DecryptWithAAD(ct, aad)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "decryption failed"})
+			return
+		}
+		if json.Valid(pt) {
+			c.Data(http.StatusOK, "application/json", pt)
+			return
+		}
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", pt)
+	}
+}
@@ -2,32 +2,83 @@ package middleware
 
 import (
 	"bytes"
+	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/aq2208/gorder-api/internal/logging"
 	"github.com/aq2208/gorder-api/internal/security"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var legacyKeyUses = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "crypto_legacy_key_uses_total",
+		Help: "Requests verified with the legacy service-wide key because the client has no keys of its own",
+	},
+	[]string{"client"},
 )
 
 type CryptoVerify struct {
-	cs security.CryptoService
+	keys       *security.KeyStore
+	replay     *security.ReplayGuard
+	signer     *security.ServerSigner // optional; required for encrypted responses
+	minVersion int                    // oldest accepted envelope version
+}
+
+// --- Options ---
+
+type CryptoOption func(*CryptoVerify)
+
+// WithMinEnvelopeVersion rejects envelopes older than v (e.g. 2 once every client sends AAD-bound envelopes).
+func WithMinEnvelopeVersion(v int) CryptoOption {
+	return func(cv *CryptoVerify) { cv.minVersion = v }
 }
 
-func NewCryptoVerify(cs security.CryptoService) *CryptoVerify {
-	return &CryptoVerify{cs: cs}
+func NewCryptoVerify(keys *security.KeyStore, replay *security.ReplayGuard, signer *security.ServerSigner, opts ...CryptoOption) *CryptoVerify {
+	cv := &CryptoVerify{keys: keys, replay: replay, signer: signer, minVersion: security.EnvelopeV1}
+	for _, opt := range opts {
+		opt(cv)
+	}
+	return cv
+}
+
+// lookupKey resolves the caller's key, logging and counting every use of the legacy fallback key.
+func (cv *CryptoVerify) lookupKey(c *gin.Context, clientID, kid string) (*security.ClientKey, error) {
+	key, err := cv.keys.Lookup(clientID, kid)
+	if err == nil && key.ClientID == security.LegacyClient {
+		legacyKeyUses.WithLabelValues(clientID).Inc()
+		logging.From(c).Warn("client has no keys of its own, using the legacy key", "client_id", clientID)
+	}
+	return key, err
 }
 
 type EncryptedRequest struct {
-	Data      string `json:"data"`      // base64 encoded ciphertext (nonce||ct)
-	Signature string `json:"signature"` // base64 encoded RSA signature of ciphertext
+	Version   int    `json:"v,omitempty"`   // envelope version; absent = 1 (legacy, no AAD)
+	Alg       string `json:"alg,omitempty"` // optional; must match the key's configured algorithm
+	KeyID     string `json:"kid,omitempty"` // key id within the caller's keys; optional if the client has one key
+	Data      string `json:"data"`          // base64 encoded ciphertext (nonce||ct)
+	Timestamp int64  `json:"ts"`            // unix seconds; must be within the freshness window
+	Nonce     string `json:"nonce"`         // unique per request; reuse is rejected
+	Signature string `json:"signature"`     // base64 encoded signature, see security.SigningInputAAD
 }
 
 type TextRequest struct {
 	Text string `json:"text"`
 }
 
+// CryptoVerify authenticates and decrypts the request body, then hands the plaintext JSON to the handler.
+// Accepted encodings (see crypto_jose.go for the JOSE ones):
+//   - Content-Type: application/jose        -> compact JWE (RSA-OAEP-256/A256GCM) wrapping a signed JWS
+//   - application/json + X-JWS-Signature    -> plaintext JSON body with a detached JWS (header value)
+//   - anything else                         -> the EncryptedRequest {data, signature} envelope
 func (cv *CryptoVerify) CryptoVerify() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// --- Read raw body ---
@@ -38,49 +89,157 @@ func (cv *CryptoVerify) CryptoVerify() gin.HandlerFunc {
 		}
 		defer c.Request.Body.Close()
 
-		// --- Parse outer wrapper ---
-		var encReq EncryptedRequest
-		if err := json.Unmarshal(rawBody, &encReq); err != nil {
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid encrypted request format"})
-			return
+		// client from the JWT; its keys are looked up by kid
+		clientID := ""
+		if p, ok := PrincipalFrom(c); ok {
+			clientID = p.ClientID
 		}
 
-		// --- Decode Base64 fields ---
-		ciphertext, err := base64.StdEncoding.DecodeString(encReq.Data)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid ciphertext encoding"})
-			return
-		}
-		sig, err := base64.StdEncoding.DecodeString(encReq.Signature)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid signature encoding"})
-			return
+		// the encoding follows the content type; a signature header never falls through to the envelope
+		var in *opened
+		detached := c.GetHeader(HeaderJWSSignature) != ""
+		switch ct := c.ContentType(); {
+		case isJOSEContentType(ct) && detached:
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": HeaderJWSSignature + " is not used with " + MediaTypeJOSE})
+		case isJOSEContentType(ct):
+			in = cv.openJWE(c, clientID, rawBody)
+		case detached && ct == "application/json":
+			in = cv.openDetachedJWS(c, clientID, rawBody)
+		case detached:
+			c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, gin.H{"error": "a detached JWS signs an application/json body"})
+		default:
+			in = cv.openEnvelope(c, clientID, rawBody)
 		}
-
-		// --- Verify RSA-SHA256 signature ---
-		if err := cv.cs.Verify(ciphertext, sig); err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "signature verification failed"})
-			return
+		if in == nil {
+			return // already aborted
 		}
 
-		// --- Decrypt AES256-GCM ciphertext ---
-		plaintext, err := cv.cs.Decrypt(ciphertext)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "decryption failed"})
-			return
+		// --- Replay protection (only after the signature proved ts/nonce authentic) ---
+		if cv.replay != nil {
+			if err := cv.replay.Check(c.Request.Context(), clientID, in.ts, in.nonce); err != nil {
+				switch {
+				case errors.Is(err, security.ErrStaleRequest):
+					c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "stale or missing timestamp/nonce"})
+				case errors.Is(err, security.ErrReplayed):
+					c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "replayed request"})
+				case errors.Is(err, security.ErrBadNonce):
+					c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid nonce"})
+				default:
+					c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "replay check unavailable"})
+				}
+				return
+			}
 		}
 
 		// --- Replace the request body with decrypted plaintext ---
-		c.Request.Body = io.NopCloser(bytes.NewReader(plaintext))
-		c.Request.ContentLength = int64(len(plaintext))
+		c.Request.Body = io.NopCloser(bytes.NewReader(in.plaintext))
+		c.Request.ContentLength = int64(len(in.plaintext))
 		c.Request.Header.Set("Content-Type", "application/json")
 
+		// --- Opt-in encrypted response: buffer the handler output and seal it afterwards ---
+		if !wantsEncryptedResponse(c, clientID) {
+			c.Next()
+			return
+		}
+		if cv.signer == nil {
+			c.AbortWithStatusJSON(http.StatusNotAcceptable, gin.H{"error": "encrypted responses not available"})
+			return
+		}
+		out := c.Writer
+		rb := &responseBuffer{ResponseWriter: out, status: http.StatusOK}
+		c.Writer = rb
+
 		// Continue to the next handler
 		c.Next()
+
+		cv.sealResponse(c, out, rb, in.key, in.env)
 	}
 }
 
-// EncryptAndSign body: raw JSON (any shape) - returns: { data: Base64(nonce||ciphertext), signature: Base64(RSA-SHA256(ciphertext)) }
+// opened is a verified + decrypted request, whatever encoding it arrived in.
+type opened struct {
+	plaintext []byte
+	key       *security.ClientKey
+	env       security.EnvelopeContext // used to bind an encrypted response
+	ts        int64
+	nonce     string
+}
+
+// openEnvelope handles the EncryptedRequest format. On failure it aborts and returns nil.
+func (cv *CryptoVerify) openEnvelope(c *gin.Context, clientID string, rawBody []byte) *opened {
+	// --- Parse outer wrapper ---
+	var encReq EncryptedRequest
+	if err := json.Unmarshal(rawBody, &encReq); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid encrypted request format"})
+		return nil
+	}
+	version, err := security.NormalizeEnvelopeVersion(encReq.Version, cv.minVersion)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "unsupported envelope version"})
+		return nil
+	}
+	// the nonce is a field of the signing input; a missing one is left to the replay check
+	if encReq.Nonce != "" && security.CheckNonce(encReq.Nonce) != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid nonce"})
+		return nil
+	}
+
+	// --- Decode Base64 fields ---
+	ciphertext, err := base64.StdEncoding.DecodeString(encReq.Data)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid ciphertext encoding"})
+		return nil
+	}
+	sig, err := base64.StdEncoding.DecodeString(encReq.Signature)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid signature encoding"})
+		return nil
+	}
+
+	// --- Resolve the caller's key (client from the JWT, kid from the envelope) ---
+	key, err := cv.lookupKey(c, clientID, encReq.KeyID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unknown key", "detail": err.Error()})
+		return nil
+	}
+	cs := key.Service
+	// the algorithm is a property of the key; never let the client downgrade it
+	if encReq.Alg != "" && security.SigAlg(encReq.Alg) != cs.SigAlg() {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "algorithm mismatch"})
+		return nil
+	}
+
+	env := requestEnvelope(c, version, clientID, key)
+	aad := env.RequestAAD()
+
+	// --- Verify signature (covers ts + nonce, and the AAD for v2) ---
+	if err := cs.Verify(security.SigningInputAAD(version, encReq.Timestamp, encReq.Nonce, aad, ciphertext), sig); err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "signature verification failed"})
+		return nil
+	}
+
+	// --- Decrypt AES256-GCM ciphertext ---
+	plaintext, err := cs.DecryptWithAAD(ciphertext, aad)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "decryption failed"})
+		return nil
+	}
+	return &opened{plaintext: plaintext, key: key, env: env, ts: encReq.Timestamp, nonce: encReq.Nonce}
+}
+
+func requestEnvelope(c *gin.Context, version int, clientID string, key *security.ClientKey) security.EnvelopeContext {
+	return security.EnvelopeContext{
+		Version:  version,
+		Method:   c.Request.Method,
+		Path:     c.Request.URL.Path,
+		ClientID: clientID,
+		KeyID:    key.KeyID,
+	}
+}
+
+// EncryptAndSign body: raw JSON (any shape) - returns an EncryptedRequest for it.
+// Query: client_id, kid (key selection); v=2 with method + path produces an AAD-bound envelope;
+// format=jws (detached JWS) or format=jwe (compact JWE) produces the JOSE encodings.
 func (cv *CryptoVerify) EncryptAndSign() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		plaintext, err := io.ReadAll(c.Request.Body)
@@ -89,26 +248,11 @@ func (cv *CryptoVerify) EncryptAndSign() gin.HandlerFunc {
 			return
 		}
 
-		ct, err := cv.cs.Encrypt(plaintext)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "encrypt failed", "detail": err.Error()})
-			return
-		}
-
-		sig, err := cv.cs.Sign(ct)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "sign failed (need RSA private key)", "detail": err.Error()})
-			return
-		}
-
-		c.JSON(http.StatusOK, gin.H{
-			"data":      base64.StdEncoding.EncodeToString(ct),
-			"signature": base64.StdEncoding.EncodeToString(sig),
-		})
+		cv.writeEnvelope(c, plaintext)
 	}
 }
 
-// EncryptAndSignText body: { "text": "..." } - returns: { data, signature }
+// EncryptAndSignText body: { "text": "..." } - returns an EncryptedRequest (same query params as EncryptAndSign)
 func (cv *CryptoVerify) EncryptAndSignText() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req TextRequest
@@ -119,21 +263,82 @@ func (cv *CryptoVerify) EncryptAndSignText() gin.HandlerFunc {
 
 		plaintext := []byte(req.Text)
 
-		ct, err := cv.cs.Encrypt(plaintext)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "encrypt failed", "detail": err.Error()})
-			return
-		}
+		cv.writeEnvelope(c, plaintext)
+	}
+}
 
-		sig, err := cv.cs.Sign(ct)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "sign failed (need RSA private key)", "detail": err.Error()})
-			return
-		}
+// helperEnvelope reads the key + envelope context for the /_test helpers from the query string.
+func (cv *CryptoVerify) helperEnvelope(c *gin.Context, kid string) (*security.ClientKey, security.EnvelopeContext, error) {
+	clientID := c.Query("client_id")
+	key, err := cv.lookupKey(c, clientID, kid)
+	if err != nil {
+		return nil, security.EnvelopeContext{}, err
+	}
+	v, _ := strconv.Atoi(c.Query("v"))
+	v, err = security.NormalizeEnvelopeVersion(v, security.EnvelopeV1)
+	if err != nil {
+		return nil, security.EnvelopeContext{}, err
+	}
+	return key, security.EnvelopeContext{
+		Version:  v,
+		Method:   c.Query("method"),
+		Path:     c.Query("path"),
+		ClientID: clientID,
+		KeyID:    key.KeyID,
+	}, nil
+}
+
+// writeEnvelope encrypts + signs plaintext with a fresh timestamp and nonce, as a client would.
+// ?format=jws|jwe produces the JOSE encodings instead (see writeJOSE).
+func (cv *CryptoVerify) writeEnvelope(c *gin.Context, plaintext []byte) {
+	key, env, err := cv.helperEnvelope(c, c.Query("kid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid key/envelope", "detail": err.Error()})
+		return
+	}
+	if f := c.Query("format"); f == "jws" || f == "jwe" {
+		cv.writeJOSE(c, f, key, env, plaintext)
+		return
+	}
+	cs := key.Service
+	aad := env.RequestAAD()
+
+	ct, err := cs.EncryptWithAAD(plaintext, aad)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "encrypt failed", "detail": err.Error()})
+		return
+	}
+
+	ts, nonce, err := freshNonce()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "nonce failed", "detail": err.Error()})
+		return
+	}
+
+	sig, err := cs.Sign(security.SigningInputAAD(env.Version, ts, nonce, aad, ct))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "sign failed (need client private key)", "detail": err.Error()})
+		return
+	}
+
+	req := EncryptedRequest{
+		KeyID:     key.KeyID,
+		Data:      base64.StdEncoding.EncodeToString(ct),
+		Timestamp: ts,
+		Nonce:     nonce,
+		Signature: base64.StdEncoding.EncodeToString(sig),
+	}
+	if env.Version >= security.EnvelopeV2 {
+		req.Version = env.Version
+		req.Alg = string(cs.SigAlg())
+	}
+	c.JSON(http.StatusOK, req)
+}
 
-		c.JSON(http.StatusOK, gin.H{
-			"data":      base64.StdEncoding.EncodeToString(ct),
-			"signature": base64.StdEncoding.EncodeToString(sig),
-		})
+func freshNonce() (int64, string, error) {
+	nb := make([]byte, 16)
+	if _, err := rand.Read(nb); err != nil {
+		return 0, "", err
 	}
+	return time.Now().Unix(), base64.RawURLEncoding.EncodeToString(nb), nil
 }
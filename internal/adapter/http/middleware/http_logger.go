@@ -76,15 +76,19 @@ func redactJSON(raw []byte) []byte {
 	return b
 }
 
-func readCapped(rc io.ReadCloser, n int) (body []byte, truncated bool) {
-	defer rc.Close()
+// readCapped reads up to n bytes of the body for logging; rest still yields the whole, unmodified body.
+func readCapped(rc io.ReadCloser, n int) (body []byte, truncated bool, rest io.ReadCloser) {
 	var buf bytes.Buffer
 	_, _ = io.CopyN(&buf, rc, int64(n+1)) // read up to n+1
 	b := buf.Bytes()
+	rest = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(b), rc), rc}
 	if len(b) > n {
-		return b[:n], true
+		return bytes.Clone(b[:n]), true, rest
 	}
-	return b, false
+	return b, false, rest
 }
 
 // Logging returns a Gin middleware that logs request/response and injects a slog.Logger into the context.
@@ -112,14 +116,14 @@ func Logging(base *slog.Logger) gin.HandlerFunc {
 		var reqBodyLogged string
 		ct := c.GetHeader("Content-Type")
 		if strings.Contains(ct, "application/json") && c.Request.Body != nil {
-			body, truncated := readCapped(c.Request.Body, reqBodyLimit)
+			body, truncated, rest := readCapped(c.Request.Body, reqBodyLimit)
 			body = redactJSON(body)
 			if truncated {
 				body = append(body, []byte("...truncated...")...)
 			}
 			reqBodyLogged = string(body)
-			// restore body for next handlers
-			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+			// restore the original body (not the redacted, capped copy) for next handlers
+			c.Request.Body = rest
 		}
 
 		// capture response
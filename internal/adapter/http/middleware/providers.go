@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"github.com/aq2208/gorder-api/configs"
+	"github.com/aq2208/gorder-api/internal/security"
+	"github.com/google/wire"
+)
+
+var ProviderSet = wire.NewSet(
+	NewAuthz,
+	ProvideCryptoVerify,
+	ProvideRateLimiter,
+)
+
+func ProvideCryptoVerify(cfg configs.Config, keys *security.KeyStore, replay *security.ReplayGuard, signer *security.ServerSigner) *CryptoVerify {
+	return NewCryptoVerify(keys, replay, signer, WithMinEnvelopeVersion(cfg.CryptoConfig.MinEnvelopeVersion))
+}
+
+func ProvideRateLimiter(cfg configs.Config) *RateLimiter {
+	return NewRateLimiter(cfg.HTTP.RateLimit.RPS, cfg.HTTP.RateLimit.Burst)
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimiter is a per-caller token bucket. Callers are keyed by JWT client, else mTLS client, else IP.
+// Limits can be changed at runtime with SetLimit; rps <= 0 disables limiting.
+type RateLimiter struct {
+	mu      sync.Mutex
+	rps     float64
+	burst   float64
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+const maxIdleBuckets = 10000
+
+func NewRateLimiter(rps float64, burst int) *RateLimiter {
+	l := &RateLimiter{buckets: map[string]*bucket{}}
+	l.SetLimit(rps, burst)
+	return l
+}
+
+// SetLimit updates the limits for all callers; existing buckets are clamped to the new burst.
+func (l *RateLimiter) SetLimit(rps float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if burst <= 0 {
+		burst = int(math.Max(1, math.Ceil(rps)))
+	}
+	l.rps, l.burst = rps, float64(burst)
+	for _, b := range l.buckets {
+		b.tokens = math.Min(b.tokens, l.burst)
+	}
+}
+
+// Allow takes a token from key's bucket; when it is empty it returns false and the time until the next
+// token. Transports other than HTTP (the gRPC API) key callers as "client:<id>" to share the budget.
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	return l.allow(key, time.Now())
+}
+
+func (l *RateLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rps <= 0 {
+		return true, 0
+	}
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= maxIdleBuckets {
+			l.evictIdle(now)
+		}
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rps)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / l.rps * float64(time.Second))
+}
+
+// evictIdle drops buckets that have refilled completely; they carry no state.
+func (l *RateLimiter) evictIdle(now time.Time) {
+	for k, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rps >= l.burst {
+			delete(l.buckets, k)
+		}
+	}
+}
+
+func (l *RateLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := "ip:" + c.ClientIP()
+		if p, ok := PrincipalFrom(c); ok && p.ClientID != "" {
+			key = "client:" + p.ClientID
+		} else if id, ok := CertClientID(c); ok {
+			key = "client:" + id
+		}
+		ok, wait := l.allow(key, time.Now())
+		if !ok {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate_limited"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,116 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aq2208/gorder-api/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// OrderAction serves POST /v1/orders:<action> (custom methods on the collection). gin cannot route
+// a literal colon, so the route captures ":<action>" and unknown actions are 404.
+func (h *OrderHandler) OrderAction(c *gin.Context) {
+	switch c.Param("action") {
+	case ":batch":
+		h.CreateOrderBatch(c)
+	default:
+		c.JSON(http.StatusNotFound, gin.H{"error": "not_found"})
+	}
+}
+
+type batchOrderReq struct {
+	UserID string `json:"userId"`
+	Amount struct {
+		Cents    int64  `json:"cents"`
+		Currency string `json:"currency"`
+	} `json:"amount"`
+	Items          string `json:"items"`
+	IdempotencyKey string `json:"idempotencyKey"`
+}
+
+type createBatchReq struct {
+	Mode   string          `json:"mode"` // atomic | per_item; default from config
+	Orders []batchOrderReq `json:"orders" binding:"required"`
+}
+
+type batchItemResp struct {
+	Index    int    `json:"index"`
+	OrderID  string `json:"orderId,omitempty"`
+	Status   string `json:"status,omitempty"`
+	Replayed bool   `json:"replayed,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+type createBatchResp struct {
+	Created int             `json:"created"`
+	Failed  int             `json:"failed"`
+	Results []batchItemResp `json:"results"`
+}
+
+// CreateOrderBatch handles POST /v1/orders:batch. Items are validated individually and answered in
+// request order; 200 means the batch was processed, with each item's outcome in results.
+func (h *OrderHandler) CreateOrderBatch(c *gin.Context) {
+	var req createBatchReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad_request"})
+		return
+	}
+
+	in := make([]usecase.CreateOrderInput, len(req.Orders))
+	for i, o := range req.Orders {
+		in[i] = usecase.CreateOrderInput{
+			UserID:         o.UserID,
+			IdempotencyKey: o.IdempotencyKey,
+			AmountCents:    o.Amount.Cents,
+			Currency:       o.Amount.Currency,
+			ItemsJSON:      o.Items,
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	results, err := h.batch.Execute(ctx, in, req.Mode)
+	switch {
+	case errors.Is(err, usecase.ErrBatchTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, usecase.ErrValidation):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad_request"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := createBatchResp{Results: make([]batchItemResp, len(results))}
+	for i, r := range results {
+		item := batchItemResp{Index: i, OrderID: r.OrderID, Status: r.Status, Replayed: r.Replayed}
+		if r.Err != nil {
+			item.Error, item.Message = batchErrorCode(r.Err), r.Err.Error()
+			resp.Failed++
+		} else {
+			resp.Created++
+		}
+		resp.Results[i] = item
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func batchErrorCode(err error) string {
+	switch {
+	case errors.Is(err, usecase.ErrValidation), errors.Is(err, usecase.ErrInvalidAmount):
+		return "invalid"
+	case errors.Is(err, usecase.ErrForbidden):
+		return "forbidden"
+	case errors.Is(err, usecase.ErrDuplicate):
+		return "duplicate"
+	case errors.Is(err, usecase.ErrBatchAborted):
+		return "aborted"
+	}
+	return "failed"
+}
@@ -0,0 +1,133 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aq2208/gorder-api/configs"
+	domain "github.com/aq2208/gorder-api/internal/entity"
+	"github.com/aq2208/gorder-api/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// OrderEventsHandler streams order status changes as Server-Sent Events.
+type OrderEventsHandler struct {
+	watch     *usecase.WatchOrder
+	heartbeat time.Duration
+}
+
+func NewOrderEventsHandler(watch *usecase.WatchOrder, cfg configs.Config) *OrderEventsHandler {
+	return &OrderEventsHandler{watch: watch, heartbeat: cfg.Events.Heartbeat}
+}
+
+// Stream serves GET /v1/orders/:id/events. A fresh stream starts with the current state; a reconnect
+// with Last-Event-ID (header, or ?lastEventId= for clients that cannot set it) replays what it missed.
+// Every event is `event: status` with the order as data; the stream ends after a final status.
+func (h *OrderEventsHandler) Stream(c *gin.Context) {
+	lastID := c.GetHeader("Last-Event-ID")
+	if lastID == "" {
+		lastID = c.Query("lastEventId")
+	}
+
+	ctx := c.Request.Context()
+	w, err := h.watch.Watch(ctx, c.Param("id"), lastID)
+	if errors.Is(err, usecase.ErrForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not_found"})
+		return
+	}
+	defer w.Close()
+
+	hdr := c.Writer.Header()
+	hdr.Set("Content-Type", "text/event-stream")
+	hdr.Set("Cache-Control", "no-cache")
+	hdr.Set("Connection", "keep-alive")
+	hdr.Set("X-Accel-Buffering", "no") // nginx: do not buffer the stream
+	c.Status(http.StatusOK)
+
+	sse := &sseWriter{c: c, rc: http.NewResponseController(c.Writer), grace: h.heartbeat + 5*time.Second}
+	if !sse.write(fmt.Sprintf("retry: %d\n\n", 3000)) {
+		return
+	}
+
+	order := w.Order
+	if w.Backlog == nil {
+		if !sse.event(w.LastID, orderEvent{OrderID: order.ID, Status: order.Status, At: order.UpdatedAt}) {
+			return
+		}
+	}
+	for _, ev := range w.Backlog {
+		if !sse.event(ev.ID, fromEvent(ev)) {
+			return
+		}
+	}
+	// the state was read after subscribing, so a final order has nothing more to send
+	if domain.Status(order.Status).IsFinal() {
+		return
+	}
+
+	ping := time.NewTicker(h.heartbeat)
+	defer ping.Stop()
+	for {
+		select {
+		case ev, ok := <-w.Events:
+			if !ok {
+				return
+			}
+			if !sse.event(ev.ID, fromEvent(ev)) || domain.Status(ev.Status).IsFinal() {
+				return
+			}
+		case <-ping.C:
+			if !sse.write(": ping\n\n") {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+type orderEvent struct {
+	OrderID string    `json:"orderId"`
+	Status  string    `json:"status"`
+	At      time.Time `json:"at"`
+}
+
+func fromEvent(ev usecase.OrderEvent) orderEvent {
+	return orderEvent{OrderID: ev.OrderID, Status: ev.Status, At: ev.At}
+}
+
+// sseWriter writes and flushes frames, pushing the write deadline past the server's write timeout
+// before each one. A failed write means the client is gone.
+type sseWriter struct {
+	c     *gin.Context
+	rc    *http.ResponseController
+	grace time.Duration
+}
+
+func (s *sseWriter) write(frame string) bool {
+	_ = s.rc.SetWriteDeadline(time.Now().Add(s.grace))
+	if _, err := s.c.Writer.WriteString(frame); err != nil {
+		return false
+	}
+	s.c.Writer.Flush()
+	return true
+}
+
+func (s *sseWriter) event(id string, data orderEvent) bool {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return false
+	}
+	frame := "event: status\ndata: " + string(b) + "\n\n"
+	if id != "" {
+		frame = "id: " + id + "\n" + frame
+	}
+	return s.write(frame)
+}
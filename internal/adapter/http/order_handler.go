@@ -4,23 +4,46 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/aq2208/gorder-api/configs"
+	domain "github.com/aq2208/gorder-api/internal/entity"
 	"github.com/aq2208/gorder-api/internal/usecase"
 	"github.com/gin-gonic/gin"
 )
 
 type OrderHandler struct {
-	create *usecase.CreateOrder
-	query  usecase.OrderRepo
+	create  *usecase.CreateOrder
+	batch   *usecase.CreateOrderBatch
+	get     *usecase.GetOrder
+	watch   *usecase.WatchOrder
+	cancel  *usecase.CancelOrder
+	maxWait time.Duration
+
+	finalCache, pendingCache string // Cache-Control for final / in-flight orders
 }
 
-func NewOrderHandler(create *usecase.CreateOrder, query usecase.OrderRepo) *OrderHandler {
-	return &OrderHandler{create: create, query: query}
+func NewOrderHandler(create *usecase.CreateOrder, batch *usecase.CreateOrderBatch, get *usecase.GetOrder, watch *usecase.WatchOrder,
+	cancel *usecase.CancelOrder, cfg configs.Config) *OrderHandler {
+	return &OrderHandler{create: create, batch: batch, get: get, watch: watch, cancel: cancel, maxWait: cfg.Events.MaxWait,
+		finalCache:   cacheControl(cfg.HTTP.Caching.FinalMaxAge),
+		pendingCache: cacheControl(cfg.HTTP.Caching.PendingMaxAge),
+	}
+}
+
+// cacheControl is private (responses depend on the caller's token) and, without a max age, no-cache:
+// clients may store the order but revalidate before each use.
+func cacheControl(maxAge time.Duration) string {
+	if maxAge <= 0 {
+		return "private, no-cache"
+	}
+	return "private, max-age=" + strconv.Itoa(int(maxAge/time.Second)) + ", must-revalidate"
 }
 
 type createOrderReq struct {
-	UserID string `json:"userId" binding:"required"`
+	UserID string `json:"userId"` // optional for end-user tokens (defaults to the token subject)
 
 	Amount struct {
 		Cents    int64  `json:"cents" binding:"required,gt=0"`
@@ -61,9 +84,12 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 		if errors.Is(err, usecase.ErrDuplicate) {
 			status = http.StatusConflict
 		}
-		if errors.Is(err, usecase.ErrInvalidAmount) {
+		if errors.Is(err, usecase.ErrInvalidAmount) || errors.Is(err, usecase.ErrValidation) {
 			status = http.StatusBadRequest
 		}
+		if errors.Is(err, usecase.ErrForbidden) {
+			status = http.StatusForbidden
+		}
 		c.JSON(status, gin.H{"error": err.Error()})
 		return
 	}
@@ -74,22 +100,127 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 	})
 }
 
+// GetOrderByID returns the order. With ?wait=30s it long-polls: the response is held until the order
+// is CONFIRMED/FAILED or the wait (capped at events.max_wait) elapses, and carries the state either way.
+// It is a conditional GET: a client holding the current ETag (If-None-Match) or a copy at least as new
+// as updated_at (If-Modified-Since) gets 304 without a body.
 func (h *OrderHandler) GetOrderByID(c *gin.Context) {
 	id := c.Param("id")
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Second)
+
+	var wait time.Duration
+	if v := c.Query("wait"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wait"})
+			return
+		}
+		wait = min(d, h.maxWait)
+		// the server's write timeout would cut a long wait short
+		_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(wait + 5*time.Second))
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), wait+2*time.Second)
 	defer cancel()
 
-	rec, err := h.query.GetByID(ctx, id)
+	var rec *usecase.OrderRecord
+	var err error
+	if wait > 0 {
+		rec, err = h.watch.AwaitFinal(ctx, id, wait)
+	} else {
+		rec, err = h.get.Execute(ctx, id)
+	}
+	if errors.Is(err, usecase.ErrForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		return
+	}
 	if err != nil || rec == nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "not_found"})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{
+	setValidators(c, rec)
+	if st := domain.Status(rec.Status); st == domain.StatusConfirmed || st == domain.StatusFailed {
+		c.Header("Cache-Control", h.finalCache)
+	} else {
+		c.Header("Cache-Control", h.pendingCache)
+	}
+	if notModified(c.Request, rec) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	writeOrder(c, rec)
+}
+
+// notModified evaluates If-None-Match with weak comparison or, only when that is absent,
+// If-Modified-Since (RFC 9110 13.2.2). Last-Modified has one-second resolution, so two updates within
+// a second are only told apart by the ETag.
+func notModified(r *http.Request, rec *usecase.OrderRecord) bool {
+	if inm := r.Header.Values("If-None-Match"); len(inm) > 0 {
+		etag := rec.ETag()
+		for _, tag := range strings.Split(strings.Join(inm, ","), ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+		return false
+	}
+	ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	return err == nil && !rec.UpdatedAt.Truncate(time.Second).After(ims)
+}
+
+// OrderItemAction handles POST /v1/orders/{id}:{action}; the router sees "{id}:{action}" as the id.
+func (h *OrderHandler) OrderItemAction(c *gin.Context) {
+	id, action, _ := strings.Cut(c.Param("id"), ":")
+	switch action {
+	case "cancel":
+		h.CancelOrder(c, id)
+	default:
+		c.JSON(http.StatusNotFound, gin.H{"error": "not_found"})
+	}
+}
+
+// CancelOrder cancels a PROCESSING order. With If-Match it only does so while the order is still at
+// one of the given ETags, so a client acting on a stale read gets 412 instead of overwriting a change.
+func (h *OrderHandler) CancelOrder(c *gin.Context, id string) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 3*time.Second)
+	defer cancel()
+
+	rec, err := h.cancel.ExecuteIf(ctx, id, usecase.MatchETags(c.GetHeader("If-Match")))
+	switch {
+	case err == nil:
+		setValidators(c, rec)
+		writeOrder(c, rec)
+	case errors.Is(err, usecase.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+	case errors.Is(err, usecase.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not_found"})
+	case errors.Is(err, usecase.ErrPreconditionFailed):
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrNotCancellable), errors.Is(err, usecase.ErrVersionConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+	}
+}
+
+// setValidators sets what clients revalidate and write against: ETag from the version (also used by
+// If-Match) and Last-Modified from updated_at.
+func setValidators(c *gin.Context, rec *usecase.OrderRecord) {
+	c.Header("ETag", rec.ETag())
+	c.Header("Last-Modified", rec.UpdatedAt.UTC().Format(http.TimeFormat))
+}
+
+func writeOrder(c *gin.Context, rec *usecase.OrderRecord) {
+	out := gin.H{
 		"id":           rec.ID,
 		"user_id":      rec.UserID,
 		"status":       rec.Status,
 		"amount_cents": rec.AmountCents,
 		"currency":     rec.Currency,
 		"items_json":   rec.ItemsJSON,
-	})
+	}
+	if rec.FailureReason != "" {
+		out["failure_reason"] = rec.FailureReason
+	}
+	c.JSON(http.StatusOK, out)
 }
@@ -0,0 +1,17 @@
+package http
+
+import (
+	"github.com/aq2208/gorder-api/internal/adapter/http/middleware"
+	"github.com/google/wire"
+)
+
+// ProviderSet provides the gin router with its handlers and middleware.
+var ProviderSet = wire.NewSet(
+	middleware.ProviderSet,
+	NewOrderHandler,
+	NewOrderEventsHandler,
+	NewWebhookHandler,
+	NewJobHandler,
+	NewTokenHandler,
+	NewRouter,
+)
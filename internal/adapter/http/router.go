@@ -1,15 +1,16 @@
 package http
 
 import (
+	"github.com/aq2208/gorder-api/configs"
 	"github.com/aq2208/gorder-api/internal/adapter/http/middleware"
 	"github.com/aq2208/gorder-api/internal/logging"
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-func NewRouter(h *OrderHandler, th *TokenHandler, authz *middleware.Authz, cv *middleware.CryptoVerify) *gin.Engine {
+func NewRouter(h *OrderHandler, eh *OrderEventsHandler, wh *WebhookHandler, jh *JobHandler, th *TokenHandler, authz *middleware.Authz, cv *middleware.CryptoVerify, rl *middleware.RateLimiter, cfg configs.Config) *gin.Engine {
 	r := gin.New()
-	r.Use(gin.Recovery(), middleware.MetricsMiddleware())
+	r.Use(gin.Recovery(), middleware.MetricsMiddleware(), middleware.ClientCert())
 
 	logging.Init("order-api", "./logs/app.log")
 	l := logging.New("http")
@@ -23,13 +24,38 @@ func NewRouter(h *OrderHandler, th *TokenHandler, authz *middleware.Authz, cv *m
 	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
 	r.POST("/v1/token", th.IssueToken)
 
-	r.POST("/_test/encrypt-sign", cv.EncryptAndSign())
-	r.POST("/_test/encrypt-sign-text", cv.EncryptAndSignText())
+	// client-side crypto for manual testing; they sign and decrypt for any client_id, so never in stg/prod
+	if cfg.TestHelpers() {
+		r.POST("/_test/encrypt-sign", cv.EncryptAndSign())
+		r.POST("/_test/encrypt-sign-text", cv.EncryptAndSignText())
+		r.POST("/_test/decrypt-response", cv.DecryptResponse())
+	}
 
 	v1 := r.Group("/v1")
 	{
-		v1.POST("/orders", authz.Require("orders.write"), cv.CryptoVerify(), h.CreateOrder)
-		v1.GET("/orders/:id", authz.Require("orders.read"), cv.CryptoVerify(), h.GetOrderByID)
+		v1.POST("/orders", authz.Require("orders.write"), rl.Middleware(), cv.CryptoVerify(), h.CreateOrder)
+		v1.POST("/orders:action", authz.Require("orders.write"), rl.Middleware(), cv.CryptoVerify(), h.OrderAction) // :batch
+		v1.GET("/orders/:id", authz.Require("orders.read"), rl.Middleware(), cv.CryptoVerify(), h.GetOrderByID)
+		v1.POST("/orders/:id", authz.Require("orders.write"), rl.Middleware(), cv.CryptoVerify(), h.OrderItemAction) // {id}:cancel
+		// SSE: no request body to verify (EventSource cannot send one); the bearer token still applies
+		v1.GET("/orders/:id/events", authz.Require("orders.read"), rl.Middleware(), eh.Stream)
+
+		hooks := v1.Group("/webhooks", authz.Require("webhooks.manage"), rl.Middleware(), cv.CryptoVerify())
+		hooks.POST("", wh.Subscribe)
+		hooks.GET("", wh.List)
+		hooks.DELETE("/:id", wh.Unsubscribe)
+		hooks.GET("/deliveries", wh.Deliveries)
+		hooks.GET("/deliveries/:id", wh.Delivery)
+		hooks.POST("/deliveries/:id/redeliver", wh.Redeliver)
+
+		// bulk jobs; an import's file is the (decrypted) request body
+		jobs := v1.Group("/jobs", authz.Require("jobs.manage"), rl.Middleware(), cv.CryptoVerify())
+		jobs.POST("/import", jh.Import)
+		jobs.POST("/export", jh.Export)
+		jobs.GET("", jh.List)
+		jobs.GET("/:id", jh.Get)
+		jobs.GET("/:id/result", jh.Result)
+		jobs.GET("/:id/errors", jh.Errors)
 	}
 
 	return r
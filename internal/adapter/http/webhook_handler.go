@@ -0,0 +1,164 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aq2208/gorder-api/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookHandler serves /v1/webhooks: the calling client's subscriptions and delivery logs.
+type WebhookHandler struct {
+	uc *usecase.ManageWebhooks
+}
+
+func NewWebhookHandler(uc *usecase.ManageWebhooks) *WebhookHandler {
+	return &WebhookHandler{uc: uc}
+}
+
+type subscribeReq struct {
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events" binding:"required"`
+}
+
+type subscriptionResp struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"` // only in the create response
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type deliveryResp struct {
+	ID             string          `json:"id"`
+	SubscriptionID string          `json:"subscription_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	OrderID        string          `json:"order_id"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	Payload        json.RawMessage `json:"payload,omitempty"`
+	Log            []attemptResp   `json:"attempts_log,omitempty"`
+}
+
+type attemptResp struct {
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMS int64     `json:"duration_ms"`
+}
+
+// Subscribe handles POST /v1/webhooks; the response carries the signing secret, which is not shown again.
+func (h *WebhookHandler) Subscribe(c *gin.Context) {
+	var req subscribeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad_request"})
+		return
+	}
+	s, err := h.uc.Subscribe(c.Request.Context(), req.URL, req.Events)
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, toSubscriptionResp(*s))
+}
+
+func (h *WebhookHandler) List(c *gin.Context) {
+	subs, err := h.uc.List(c.Request.Context())
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	out := make([]subscriptionResp, 0, len(subs))
+	for _, s := range subs {
+		out = append(out, toSubscriptionResp(s))
+	}
+	c.JSON(http.StatusOK, gin.H{"subscriptions": out})
+}
+
+func (h *WebhookHandler) Unsubscribe(c *gin.Context) {
+	if err := h.uc.Unsubscribe(c.Request.Context(), c.Param("id")); err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Deliveries handles GET /v1/webhooks/deliveries?status=pending|delivered|failed&limit=50.
+func (h *WebhookHandler) Deliveries(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	ds, err := h.uc.Deliveries(c.Request.Context(), c.Query("status"), limit)
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	out := make([]deliveryResp, 0, len(ds))
+	for _, d := range ds {
+		out = append(out, toDeliveryResp(d))
+	}
+	c.JSON(http.StatusOK, gin.H{"deliveries": out})
+}
+
+// Delivery returns one delivery with its payload and attempt log.
+func (h *WebhookHandler) Delivery(c *gin.Context) {
+	d, attempts, err := h.uc.Delivery(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	out := toDeliveryResp(*d)
+	out.Payload = d.Payload
+	for _, a := range attempts {
+		out.Log = append(out.Log, attemptResp{At: a.At, StatusCode: a.StatusCode, Error: a.Error, DurationMS: a.Duration.Milliseconds()})
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	if err := h.uc.Redeliver(c.Request.Context(), c.Param("id")); err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"id": c.Param("id"), "status": usecase.DeliveryPending})
+}
+
+func toSubscriptionResp(s usecase.WebhookSubscription) subscriptionResp {
+	return subscriptionResp{ID: s.ID, URL: s.URL, Events: s.Events, Secret: s.Secret, CreatedAt: s.CreatedAt}
+}
+
+func toDeliveryResp(d usecase.WebhookDelivery) deliveryResp {
+	out := deliveryResp{
+		ID: d.ID, SubscriptionID: d.SubscriptionID, EventID: d.EventID, EventType: d.EventType, OrderID: d.OrderID,
+		Status: d.Status, Attempts: d.Attempts, LastStatusCode: d.LastStatusCode, LastError: d.LastError,
+		CreatedAt: d.CreatedAt,
+	}
+	if d.Status == usecase.DeliveryPending {
+		out.NextAttemptAt = &d.NextAttemptAt
+	}
+	if !d.DeliveredAt.IsZero() {
+		out.DeliveredAt = &d.DeliveredAt
+	}
+	return out
+}
+
+func webhookError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+	case errors.Is(err, usecase.ErrWebhookNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not_found"})
+	case errors.Is(err, usecase.ErrInvalidWebhook), errors.Is(err, usecase.ErrValidation):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+	}
+}
@@ -0,0 +1,37 @@
+package jobs
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/aq2208/gorder-api/internal/usecase"
+)
+
+// LocalFiles keeps job files in one directory. With several API replicas it must be shared between
+// them (e.g. one volume), since an upload received by one replica may be run by another.
+type LocalFiles struct{ dir string }
+
+// NewLocalFiles creates dir if needed.
+func NewLocalFiles(dir string) (*LocalFiles, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("jobs dir: %w", err)
+	}
+	return &LocalFiles{dir: dir}, nil
+}
+
+func (f *LocalFiles) Create(name string) (io.WriteCloser, error) {
+	return os.OpenFile(f.path(name), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+}
+
+func (f *LocalFiles) Append(name string) (io.WriteCloser, error) {
+	return os.OpenFile(f.path(name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+}
+
+func (f *LocalFiles) Open(name string) (io.ReadCloser, error) { return os.Open(f.path(name)) }
+
+// path keeps names inside dir; they are generated from job ids, never taken from requests.
+func (f *LocalFiles) path(name string) string { return filepath.Join(f.dir, filepath.Base(name)) }
+
+var _ usecase.JobFiles = (*LocalFiles)(nil)
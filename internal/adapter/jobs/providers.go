@@ -0,0 +1,30 @@
+package jobs
+
+import (
+	"github.com/aq2208/gorder-api/configs"
+	"github.com/aq2208/gorder-api/internal/usecase"
+	"github.com/google/wire"
+)
+
+// ProviderSet provides the job file store and the job runner; it needs a JobRepo, an OrderExportRepo
+// and the CreateOrder use case.
+var ProviderSet = wire.NewSet(
+	ProvideLocalFiles,
+	wire.Bind(new(usecase.JobFiles), new(*LocalFiles)),
+	ProvideRunJobs,
+	ProvideRunner,
+)
+
+func ProvideLocalFiles(cfg configs.Config) (*LocalFiles, error) { return NewLocalFiles(cfg.Jobs.Dir) }
+
+func ProvideRunJobs(cfg configs.Config, repo usecase.JobRepo, files usecase.JobFiles, create *usecase.CreateOrder,
+	orders usecase.OrderExportRepo) *usecase.RunJobs {
+	return usecase.NewRunJobs(repo, files, create, orders,
+		usecase.WithExportPageSize(cfg.Jobs.PageSize),
+		usecase.WithJobClaimTTL(cfg.Jobs.ClaimTTL),
+	)
+}
+
+func ProvideRunner(cfg configs.Config, uc *usecase.RunJobs) *Runner {
+	return NewRunner(uc, cfg.Jobs.Interval, cfg.Jobs.Enabled)
+}
@@ -0,0 +1,63 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/aq2208/gorder-api/internal/logging"
+	"github.com/aq2208/gorder-api/internal/usecase"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var jobsFinished = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "jobs_finished_total",
+		Help: "Bulk import/export jobs finished, by kind and status",
+	},
+	[]string{"kind", "status"}, // import | export; completed | failed
+)
+
+// Runner runs queued bulk jobs in the API process, one at a time. Jobs are claimed in the database,
+// so every replica runs one; after a job it looks for the next at once.
+type Runner struct {
+	uc       *usecase.RunJobs
+	interval time.Duration
+	enabled  bool
+}
+
+func NewRunner(uc *usecase.RunJobs, interval time.Duration, enabled bool) *Runner {
+	return &Runner{uc: uc, interval: interval, enabled: enabled}
+}
+
+// Run blocks until ctx is cancelled; a job still running then is resumed later (see RunJobs).
+func (r *Runner) Run(ctx context.Context) {
+	l := logging.New("jobs")
+	if !r.enabled {
+		l.Info("job runner disabled")
+		return
+	}
+
+	t := time.NewTicker(r.interval)
+	defer t.Stop()
+	for {
+		j, err := r.uc.Execute(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			l.Warn("job run failed", "err", err)
+		}
+		if j != nil {
+			jobsFinished.WithLabelValues(j.Kind, j.Status).Inc()
+			l.Info("job finished", "job_id", j.ID, "kind", j.Kind, "status", j.Status,
+				"processed", j.Processed, "failed", j.Failed, "error", j.Error)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
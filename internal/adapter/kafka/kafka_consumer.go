@@ -3,7 +3,9 @@ package kafka
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
+	"time"
 
 	"github.com/IBM/sarama"
 	"github.com/aq2208/gorder-api/internal/usecase"
@@ -60,14 +62,48 @@ func (h *cgHandler) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.
 			sess.MarkMessage(msg, "decode-error")
 			continue
 		}
-		if err := h.handle(sess.Context(), ev); err != nil {
-			if h.logger != nil {
-				h.logger.Printf("handler error: %v (key=%s, off=%d)", err, string(msg.Key), msg.Offset)
-			}
-			// Do not mark message; let it retry on next poll or route with your DLQ pattern.
-			continue
+		if err := h.process(sess.Context(), ev, msg); err != nil {
+			// session ending (shutdown or rebalance): leave the offset unmarked so the message is
+			// consumed again; marking a later one would commit past it
+			return nil
 		}
 		sess.MarkMessage(msg, "")
 	}
 	return nil
 }
+
+// Handler failures are retried in place with capped exponential backoff: the partition waits rather
+// than committing past a status update that was not applied.
+const (
+	retryBase = 200 * time.Millisecond
+	retryMax  = 30 * time.Second
+)
+
+// process runs the handler until it succeeds or ctx ends. An event for an unknown order is logged and
+// skipped, since retrying cannot fix it.
+func (h *cgHandler) process(ctx context.Context, ev usecase.OrderStatusChangedMsg, msg *sarama.ConsumerMessage) error {
+	delay := retryBase
+	for {
+		err := h.handle(ctx, ev)
+		if err == nil {
+			return nil
+		}
+		if errors.Is(err, usecase.ErrNotFound) {
+			h.logf("skipping event for unknown order %s (off=%d)", ev.OrderID, msg.Offset)
+			return nil
+		}
+		h.logf("handler error: %v (key=%s, off=%d); retrying in %s", err, string(msg.Key), msg.Offset, delay)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, retryMax)
+	}
+}
+
+func (h *cgHandler) logf(format string, args ...any) {
+	if h.logger != nil {
+		h.logger.Printf(format, args...)
+	}
+}
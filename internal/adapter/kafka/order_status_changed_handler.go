@@ -8,12 +8,15 @@ import (
 )
 
 type OrderStatusChangedHandler struct {
-	Repo  usecase.OrderRepo
-	Cache usecase.OrderCache // optional
+	Repo     usecase.OrderRepo
+	Cache    usecase.OrderCache          // optional
+	Events   usecase.OrderEventPublisher // optional; feeds SSE / long-poll watchers
+	Webhooks *usecase.NotifyWebhooks     // optional
 }
 
-func NewOrderStatusChangedHandler(repo usecase.OrderRepo, cache usecase.OrderCache) *OrderStatusChangedHandler {
-	return &OrderStatusChangedHandler{Repo: repo, Cache: cache}
+func NewOrderStatusChangedHandler(repo usecase.OrderRepo, cache usecase.OrderCache, events usecase.OrderEventPublisher,
+	webhooks *usecase.NotifyWebhooks) *OrderStatusChangedHandler {
+	return &OrderStatusChangedHandler{Repo: repo, Cache: cache, Events: events, Webhooks: webhooks}
 }
 
 func (h *OrderStatusChangedHandler) Handle(ctx context.Context, ev usecase.OrderStatusChangedMsg) error {
@@ -26,8 +29,8 @@ func (h *OrderStatusChangedHandler) Handle(ctx context.Context, ev usecase.Order
 		newStatus = domain.StatusFailed
 	}
 
-	// update order status
-	if err := h.Repo.UpdateStatus(ctx, ev.OrderID, string(newStatus)); err != nil {
+	// update order status (compare-and-swap, retried over concurrent writes)
+	if err := usecase.ApplyStatus(ctx, h.Repo, ev.OrderID, string(newStatus)); err != nil {
 		return err
 	}
 
@@ -35,5 +38,16 @@ func (h *OrderStatusChangedHandler) Handle(ctx context.Context, ev usecase.Order
 	if h.Cache != nil {
 		_ = h.Cache.SetStatus(ctx, ev.OrderID, string(newStatus))
 	}
+
+	// Push best-effort: watchers that miss it still see the new state when they (re)connect
+	if h.Events != nil {
+		_, _ = h.Events.PublishStatus(ctx, ev.OrderID, string(newStatus))
+	}
+
+	// Webhooks must not be lost: an error makes the consumer retry the whole event before committing its
+	// offset (re-applying the status is harmless and deliveries are deduplicated)
+	if h.Webhooks != nil {
+		return h.Webhooks.Execute(ctx, ev.OrderID, string(newStatus))
+	}
 	return nil
 }
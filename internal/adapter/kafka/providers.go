@@ -0,0 +1,28 @@
+package kafka
+
+import (
+	"github.com/IBM/sarama"
+	"github.com/aq2208/gorder-api/configs"
+	"github.com/google/wire"
+)
+
+// ProviderSet provides the order status consumer (Kafka -> OrderRepo/OrderCache).
+var ProviderSet = wire.NewSet(
+	NewConsumerGroup,
+	ProvideStatusConsumer,
+	NewOrderStatusChangedHandler,
+)
+
+// NewConsumerGroup joins cfg.Kafka.GroupID on cfg.Kafka.Brokers.
+func NewConsumerGroup(cfg configs.Config) (sarama.ConsumerGroup, func(), error) {
+	grp, err := NewGroup(cfg.Kafka.Brokers, cfg.Kafka.GroupID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return grp, func() { _ = grp.Close() }, nil
+}
+
+// ProvideStatusConsumer consumes cfg.Kafka.Topic; call Start to run it.
+func ProvideStatusConsumer(grp sarama.ConsumerGroup, cfg configs.Config, h *OrderStatusChangedHandler) *Consumer {
+	return NewConsumer(grp, []string{cfg.Kafka.Topic}, h.Handle)
+}
@@ -0,0 +1,45 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/aq2208/gorder-api/internal/usecase"
+)
+
+// StatusProducer publishes OrderStatusChangedMsg events keyed by order id, the way order-gw does.
+// Used by the fake gateway; the service itself only consumes this topic.
+type StatusProducer struct {
+	producer sarama.SyncProducer
+	topic    string
+}
+
+func NewStatusProducer(brokers []string, topic string) (*StatusProducer, error) {
+	cfg := sarama.NewConfig()
+	cfg.Version = sarama.V2_6_0_0
+	cfg.Producer.RequiredAcks = sarama.WaitForAll
+	cfg.Producer.Return.Successes = true
+	cfg.Net.DialTimeout = 5 * time.Second
+	p, err := sarama.NewSyncProducer(brokers, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &StatusProducer{producer: p, topic: topic}, nil
+}
+
+func (p *StatusProducer) Publish(ctx context.Context, ev usecase.OrderStatusChangedMsg) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	_, _, err = p.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: p.topic,
+		Key:   sarama.StringEncoder(ev.OrderID),
+		Value: sarama.ByteEncoder(body),
+	})
+	return err
+}
+
+func (p *StatusProducer) Close() error { return p.producer.Close() }
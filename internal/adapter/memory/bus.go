@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/aq2208/gorder-api/internal/logging"
+	"github.com/aq2208/gorder-api/internal/usecase"
+)
+
+const (
+	busBuffer      = 1024
+	maxDeliveries  = 5
+	redeliverDelay = time.Second
+)
+
+// bus is an in-process stand-in for a broker: a buffered channel with at-least-once delivery.
+// A failed message is redelivered after redeliverDelay, up to maxDeliveries times, then dropped and logged.
+type bus[T any] struct {
+	name string
+	ch   chan delivery[T]
+}
+
+type delivery[T any] struct {
+	msg     T
+	attempt int
+}
+
+func newBus[T any](name string) *bus[T] {
+	return &bus[T]{name: name, ch: make(chan delivery[T], busBuffer)}
+}
+
+func (b *bus[T]) publish(ctx context.Context, msg T) error {
+	select {
+	case b.ch <- delivery[T]{msg: msg, attempt: 1}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run delivers messages to handle until ctx is cancelled.
+func (b *bus[T]) run(ctx context.Context, handle func(context.Context, T) error) {
+	l := logging.New("memory-bus").With("bus", b.name)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case d := <-b.ch:
+			err := handle(ctx, d.msg)
+			if err == nil {
+				continue
+			}
+			if d.attempt >= maxDeliveries {
+				l.Error("message dropped", "attempts", d.attempt, "err", err)
+				continue
+			}
+			l.Warn("handler failed; redelivering", "attempt", d.attempt, "err", err)
+			d.attempt++
+			time.AfterFunc(redeliverDelay, func() {
+				select {
+				case b.ch <- d:
+				case <-ctx.Done():
+				}
+			})
+		}
+	}
+}
+
+// CommandBus replaces RabbitMQ: it implements usecase.OrderQueue and feeds the order worker.
+type CommandBus struct{ b *bus[usecase.CreatedMsg] }
+
+func NewCommandBus() *CommandBus { return &CommandBus{b: newBus[usecase.CreatedMsg]("order.created")} }
+
+func (c *CommandBus) PublishCreated(ctx context.Context, msg usecase.CreatedMsg) error {
+	return c.b.publish(ctx, msg)
+}
+
+func (c *CommandBus) PublishCreatedBatch(ctx context.Context, msgs []usecase.CreatedMsg) error {
+	for _, msg := range msgs {
+		if err := c.b.publish(ctx, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run consumes order.created commands (e.g. with queue.OrderCreatedHandler.HandleCreate) until ctx ends.
+func (c *CommandBus) Run(ctx context.Context, handle func(context.Context, usecase.CreatedMsg) error) {
+	c.b.run(ctx, handle)
+}
+
+// StatusBus replaces the Kafka status topic: the fake gateway publishes to it and the status handler consumes it.
+type StatusBus struct {
+	b *bus[usecase.OrderStatusChangedMsg]
+}
+
+func NewStatusBus() *StatusBus {
+	return &StatusBus{b: newBus[usecase.OrderStatusChangedMsg]("order.status.changed")}
+}
+
+func (s *StatusBus) Publish(ctx context.Context, ev usecase.OrderStatusChangedMsg) error {
+	return s.b.publish(ctx, ev)
+}
+
+// Run consumes status changes (e.g. with kafka.OrderStatusChangedHandler.Handle) until ctx ends.
+func (s *StatusBus) Run(ctx context.Context, handle func(context.Context, usecase.OrderStatusChangedMsg) error) {
+	s.b.run(ctx, handle)
+}
+
+var _ usecase.OrderQueue = (*CommandBus)(nil)
@@ -0,0 +1,32 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aq2208/gorder-api/internal/usecase"
+)
+
+// DiscrepancyRepo keeps the reconciliation report in memory.
+type DiscrepancyRepo struct {
+	mu   sync.Mutex
+	rows []usecase.Discrepancy
+}
+
+func NewDiscrepancyRepo() *DiscrepancyRepo { return &DiscrepancyRepo{} }
+
+func (r *DiscrepancyRepo) InsertDiscrepancies(ctx context.Context, ds []usecase.Discrepancy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rows = append(r.rows, ds...)
+	return nil
+}
+
+// All returns a copy of every stored row.
+func (r *DiscrepancyRepo) All() []usecase.Discrepancy {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]usecase.Discrepancy(nil), r.rows...)
+}
+
+var _ usecase.DiscrepancyRepo = (*DiscrepancyRepo)(nil)
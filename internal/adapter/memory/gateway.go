@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/aq2208/gorder-api/internal/logging"
+	"github.com/aq2208/gorder-api/internal/usecase"
+)
+
+// Gateway stands in for order-gw: it accepts every order and, after Delay, publishes the outcome to the
+// StatusBus the way order-gw publishes to Kafka.
+type Gateway struct {
+	statuses *StatusBus
+	Delay    time.Duration
+	Status   string // published status, CONFIRMED by default
+}
+
+func NewGateway(statuses *StatusBus) *Gateway {
+	return &Gateway{statuses: statuses, Delay: 500 * time.Millisecond, Status: "CONFIRMED"}
+}
+
+// CreateOrder implements queue.OrderGateway.
+func (g *Gateway) CreateOrder(ctx context.Context, orderID, userID string, cents int64, currency string) error {
+	ev := usecase.OrderStatusChangedMsg{
+		OrderID:  orderID,
+		UserID:   userID,
+		Cents:    cents,
+		Currency: currency,
+		Status:   g.Status,
+	}
+	time.AfterFunc(g.Delay, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := g.statuses.Publish(ctx, ev); err != nil {
+			logging.New("memory-gw").Error("publish status", "order_id", orderID, "err", err)
+		}
+	})
+	return nil
+}
@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aq2208/gorder-api/internal/usecase"
+)
+
+// JobRepo keeps bulk jobs in a map, with their claim expiry.
+type JobRepo struct {
+	mu     sync.Mutex
+	jobs   map[string]usecase.Job
+	claims map[string]time.Time
+}
+
+func NewJobRepo() *JobRepo {
+	return &JobRepo{jobs: map[string]usecase.Job{}, claims: map[string]time.Time{}}
+}
+
+func (r *JobRepo) CreateJob(ctx context.Context, j *usecase.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	j.CreatedAt = time.Now()
+	j.UpdatedAt = j.CreatedAt
+	r.jobs[j.ID] = *j
+	return nil
+}
+
+func (r *JobRepo) GetJob(ctx context.Context, clientID, id string) (*usecase.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	j, ok := r.jobs[id]
+	if !ok || j.Owner.ClientID != clientID {
+		return nil, usecase.ErrJobNotFound
+	}
+	return &j, nil
+}
+
+func (r *JobRepo) ListJobs(ctx context.Context, clientID string, limit int) ([]usecase.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []usecase.Job
+	for _, j := range r.jobs {
+		if j.Owner.ClientID == clientID {
+			out = append(out, j)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (r *JobRepo) ClaimJob(ctx context.Context, lease time.Duration) (*usecase.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	var next *usecase.Job
+	for _, j := range r.jobs {
+		claimable := j.Status == usecase.JobQueued || (j.Status == usecase.JobRunning && !r.claims[j.ID].After(now))
+		if claimable && (next == nil || j.CreatedAt.Before(next.CreatedAt)) {
+			next = &j
+		}
+	}
+	if next == nil {
+		return nil, nil
+	}
+	next.Status, next.UpdatedAt = usecase.JobRunning, now
+	if next.StartedAt.IsZero() {
+		next.StartedAt = now
+	}
+	r.jobs[next.ID] = *next
+	r.claims[next.ID] = now.Add(lease)
+	return next, nil
+}
+
+func (r *JobRepo) SaveJob(ctx context.Context, j *usecase.Job, lease time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	j.UpdatedAt = time.Now()
+	r.jobs[j.ID] = *j
+	r.claims[j.ID] = j.UpdatedAt.Add(lease)
+	return nil
+}
+
+var _ usecase.JobRepo = (*JobRepo)(nil)
@@ -0,0 +1,157 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aq2208/gorder-api/internal/security"
+	"github.com/aq2208/gorder-api/internal/usecase"
+)
+
+// kv is a string map with per-key expiry, the subset of Redis the cache adapters use.
+type kv struct {
+	mu      sync.Mutex
+	entries map[string]kvEntry
+}
+
+type kvEntry struct {
+	value   string
+	expires time.Time // zero = never
+}
+
+const sweepThreshold = 10000
+
+func newKV() *kv { return &kv{entries: map[string]kvEntry{}} }
+
+func (s *kv) get(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok {
+		return "", false
+	}
+	if !e.expires.IsZero() && time.Now().After(e.expires) {
+		delete(s.entries, key)
+		return "", false
+	}
+	return e.value, true
+}
+
+func (s *kv) set(key, value string, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweepLocked()
+	s.entries[key] = kvEntry{value: value, expires: expiry(ttl)}
+}
+
+// setNX stores value only if key is absent (or expired), like Redis SET NX.
+func (s *kv) setNX(key, value string, ttl time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries[key]; ok && (e.expires.IsZero() || time.Now().Before(e.expires)) {
+		return false
+	}
+	s.sweepLocked()
+	s.entries[key] = kvEntry{value: value, expires: expiry(ttl)}
+	return true
+}
+
+func (s *kv) del(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+}
+
+// sweepLocked drops expired entries once the map grows, so keys that are never read again do not pile up.
+func (s *kv) sweepLocked() {
+	if len(s.entries) < sweepThreshold {
+		return
+	}
+	now := time.Now()
+	for k, e := range s.entries {
+		if !e.expires.IsZero() && now.After(e.expires) {
+			delete(s.entries, k)
+		}
+	}
+}
+
+func expiry(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
+
+// Cache implements usecase.OrderCache.
+type Cache struct {
+	kv  *kv
+	ttl atomic.Int64 // time.Duration; 0 = no expiry
+}
+
+func NewCache(ttl time.Duration) *Cache {
+	c := &Cache{kv: newKV()}
+	c.SetTTL(ttl)
+	return c
+}
+
+func (c *Cache) SetTTL(ttl time.Duration) { c.ttl.Store(int64(ttl)) }
+
+func (c *Cache) SetStatus(ctx context.Context, orderID string, status string) error {
+	c.kv.set(orderID, status, time.Duration(c.ttl.Load()))
+	return nil
+}
+
+func (c *Cache) GetStatus(ctx context.Context, orderID string) (string, error) {
+	v, _ := c.kv.get(orderID)
+	return v, nil
+}
+
+// IdempotencyStore implements usecase.IdempotencyStore.
+type IdempotencyStore struct {
+	locks, mappings *kv
+	ttl             atomic.Int64 // time.Duration
+}
+
+func NewIdempotencyStore(ttl time.Duration) *IdempotencyStore {
+	s := &IdempotencyStore{locks: newKV(), mappings: newKV()}
+	s.SetTTL(ttl)
+	return s
+}
+
+func (s *IdempotencyStore) SetTTL(ttl time.Duration) { s.ttl.Store(int64(ttl)) }
+
+func (s *IdempotencyStore) TryLock(ctx context.Context, key string) (bool, error) {
+	return s.locks.setNX(key, "1", time.Duration(s.ttl.Load())), nil
+}
+
+func (s *IdempotencyStore) Unlock(ctx context.Context, key string) error {
+	s.locks.del(key)
+	return nil
+}
+
+func (s *IdempotencyStore) Remember(ctx context.Context, key, value string) error {
+	s.mappings.set(key, value, time.Duration(s.ttl.Load()))
+	return nil
+}
+
+func (s *IdempotencyStore) Recall(ctx context.Context, key string) (string, bool, error) {
+	v, ok := s.mappings.get(key)
+	return v, ok, nil
+}
+
+// NonceStore implements security.NonceStore.
+type NonceStore struct{ kv *kv }
+
+func NewNonceStore() *NonceStore { return &NonceStore{kv: newKV()} }
+
+func (s *NonceStore) Claim(ctx context.Context, scope, nonce string, ttl time.Duration) (bool, error) {
+	return s.kv.setNX(scope+":"+nonce, "1", ttl), nil
+}
+
+var (
+	_ usecase.OrderCache       = (*Cache)(nil)
+	_ usecase.IdempotencyStore = (*IdempotencyStore)(nil)
+	_ security.NonceStore      = (*NonceStore)(nil)
+)
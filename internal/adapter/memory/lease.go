@@ -0,0 +1,17 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/aq2208/gorder-api/internal/usecase"
+)
+
+// LeaderLease is always held: a local-mem process is the only replica.
+type LeaderLease struct{}
+
+func NewLeaderLease() *LeaderLease { return &LeaderLease{} }
+
+func (LeaderLease) Acquire(ctx context.Context) (bool, error) { return true, nil }
+func (LeaderLease) Release(ctx context.Context) error         { return nil }
+
+var _ usecase.LeaderLease = (*LeaderLease)(nil)
@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aq2208/gorder-api/internal/usecase"
+)
+
+const orderEventsKept = 100 // per order, like the Redis stream cap
+
+// OrderEvents keeps each order's status changes and fans them out to local watchers. Event IDs use
+// the Redis stream format ("<ms>-<seq>") so resume behaves the same in both backends.
+type OrderEvents struct {
+	mu      sync.Mutex
+	lastMS  int64
+	seq     uint64
+	history map[string][]usecase.OrderEvent
+	subs    map[string]map[chan usecase.OrderEvent]struct{}
+}
+
+func NewOrderEvents() *OrderEvents {
+	return &OrderEvents{
+		history: map[string][]usecase.OrderEvent{},
+		subs:    map[string]map[chan usecase.OrderEvent]struct{}{},
+	}
+}
+
+func (e *OrderEvents) PublishStatus(ctx context.Context, orderID, status string) (usecase.OrderEvent, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	ms := now.UnixMilli()
+	if ms <= e.lastMS {
+		ms = e.lastMS
+		e.seq++
+	} else {
+		e.lastMS, e.seq = ms, 0
+	}
+	ev := usecase.OrderEvent{ID: fmt.Sprintf("%d-%d", ms, e.seq), OrderID: orderID, Status: status, At: now}
+
+	h := append(e.history[orderID], ev)
+	if len(h) > orderEventsKept {
+		h = h[len(h)-orderEventsKept:]
+	}
+	e.history[orderID] = h
+
+	for ch := range e.subs[orderID] {
+		select {
+		case ch <- ev:
+		default: // slow watcher; it can catch up via Since
+		}
+	}
+	return ev, nil
+}
+
+func (e *OrderEvents) Since(ctx context.Context, orderID, lastID string) ([]usecase.OrderEvent, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var out []usecase.OrderEvent
+	for _, ev := range e.history[orderID] {
+		if usecase.EventIDAfter(ev.ID, lastID) {
+			out = append(out, ev)
+		}
+	}
+	return out, nil
+}
+
+func (e *OrderEvents) Subscribe(ctx context.Context, orderID string) (<-chan usecase.OrderEvent, func()) {
+	ch := make(chan usecase.OrderEvent, 16)
+	e.mu.Lock()
+	if e.subs[orderID] == nil {
+		e.subs[orderID] = map[chan usecase.OrderEvent]struct{}{}
+	}
+	e.subs[orderID][ch] = struct{}{}
+	e.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			e.mu.Lock()
+			delete(e.subs[orderID], ch)
+			if len(e.subs[orderID]) == 0 {
+				delete(e.subs, orderID)
+			}
+			e.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+var _ usecase.OrderEventStream = (*OrderEvents)(nil)
@@ -0,0 +1,249 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	domain "github.com/aq2208/gorder-api/internal/entity"
+	"github.com/aq2208/gorder-api/internal/usecase"
+)
+
+// OrderRepo is a map-backed usecase.OrderRepo. Records are copied in and out, so callers cannot
+// mutate stored state.
+type OrderRepo struct {
+	mu     sync.RWMutex
+	orders map[string]usecase.OrderRecord
+}
+
+func NewOrderRepo() *OrderRepo {
+	return &OrderRepo{orders: map[string]usecase.OrderRecord{}}
+}
+
+func (r *OrderRepo) Create(ctx context.Context, o *usecase.OrderRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.orders[o.ID]; ok {
+		return fmt.Errorf("order %s already exists", o.ID)
+	}
+	if r.idemKeyTaken(o) {
+		return usecase.ErrDuplicate
+	}
+	rec := *o
+	rec.Version = 0
+	rec.CreatedAt = time.Now()
+	rec.UpdatedAt = rec.CreatedAt
+	r.orders[o.ID] = rec
+	return nil
+}
+
+// CreateBatch stores all records or, if any id exists already, none.
+func (r *OrderRepo) CreateBatch(ctx context.Context, recs []*usecase.OrderRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, o := range recs {
+		if _, ok := r.orders[o.ID]; ok {
+			return fmt.Errorf("order %s already exists", o.ID)
+		}
+		if r.idemKeyTaken(o) || slices.ContainsFunc(recs[:i], func(p *usecase.OrderRecord) bool { return sameIdemKey(p, o) }) {
+			return usecase.ErrDuplicate
+		}
+	}
+	now := time.Now()
+	for _, o := range recs {
+		rec := *o
+		rec.Version = 0
+		rec.CreatedAt, rec.UpdatedAt = now, now
+		r.orders[o.ID] = rec
+	}
+	return nil
+}
+
+func (r *OrderRepo) UpdateStatus(ctx context.Context, id string, version int64, toStatus string) (bool, error) {
+	return r.update(id, version, func(rec *usecase.OrderRecord) bool {
+		rec.Status = toStatus
+		return true
+	})
+}
+
+func (r *OrderRepo) GetByID(ctx context.Context, id string) (*usecase.OrderRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rec, ok := r.orders[id]
+	if !ok {
+		return nil, usecase.ErrNotFound
+	}
+	return &rec, nil
+}
+
+func (r *OrderRepo) GetByIdemKey(ctx context.Context, clientID, userID, idemKey string) (*usecase.OrderRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, rec := range r.orders {
+		if rec.ClientID == clientID && rec.UserID == userID && rec.IdempotencyKey != "" && rec.IdempotencyKey == idemKey {
+			return &rec, nil
+		}
+	}
+	return nil, usecase.ErrNotFound
+}
+
+// idemKeyTaken mirrors the unique (client_id, user_id, idempotency_key) index; the caller holds r.mu.
+func (r *OrderRepo) idemKeyTaken(o *usecase.OrderRecord) bool {
+	for _, rec := range r.orders {
+		if sameIdemKey(&rec, o) {
+			return true
+		}
+	}
+	return false
+}
+
+func sameIdemKey(a, b *usecase.OrderRecord) bool {
+	return a.IdempotencyKey != "" && a.IdempotencyKey == b.IdempotencyKey && a.ClientID == b.ClientID && a.UserID == b.UserID
+}
+
+func (r *OrderRepo) ListStuck(ctx context.Context, status string, olderThan time.Duration, limit int) ([]usecase.OrderRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cutoff := time.Now().Add(-olderThan)
+	var out []usecase.OrderRecord
+	for _, rec := range r.orders {
+		if rec.Status == status && rec.UpdatedAt.Before(cutoff) {
+			out = append(out, rec)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].UpdatedAt.Before(out[j].UpdatedAt) })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (r *OrderRepo) MarkRedispatched(ctx context.Context, id string, version int64) (bool, error) {
+	return r.update(id, version, func(rec *usecase.OrderRecord) bool {
+		rec.DispatchAttempts++
+		return rec.Status == string(domain.StatusProcessing)
+	})
+}
+
+func (r *OrderRepo) FailStuck(ctx context.Context, id string, version int64, reason string) (bool, error) {
+	return r.update(id, version, func(rec *usecase.OrderRecord) bool {
+		ok := rec.Status == string(domain.StatusProcessing)
+		rec.Status = string(domain.StatusFailed)
+		rec.FailureReason = reason
+		return ok
+	})
+}
+
+func (r *OrderRepo) ListRecent(ctx context.Context, window, settle time.Duration, after usecase.OrderCursor, limit int) ([]usecase.OrderRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	now := time.Now()
+	var out []usecase.OrderRecord
+	for _, rec := range r.orders {
+		if rec.CreatedAt.Before(now.Add(-window)) || rec.UpdatedAt.After(now.Add(-settle)) {
+			continue
+		}
+		if after.ID != "" && !cursorBefore(after, rec) {
+			continue
+		}
+		out = append(out, rec)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return cursorBefore(usecase.OrderCursor{CreatedAt: out[i].CreatedAt, ID: out[i].ID}, out[j])
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (r *OrderRepo) ListByUser(ctx context.Context, userID, status string, before usecase.OrderCursor, limit int) ([]usecase.OrderRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var out []usecase.OrderRecord
+	for _, rec := range r.orders {
+		if rec.UserID != userID || (status != "" && rec.Status != status) {
+			continue
+		}
+		if before.ID != "" && !cursorAfter(before, rec) {
+			continue
+		}
+		out = append(out, rec)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return cursorAfter(usecase.OrderCursor{CreatedAt: out[i].CreatedAt, ID: out[i].ID}, out[j])
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// cursorAfter reports whether c sorts after rec in (created_at, id) order.
+func cursorAfter(c usecase.OrderCursor, rec usecase.OrderRecord) bool {
+	if !c.CreatedAt.Equal(rec.CreatedAt) {
+		return c.CreatedAt.After(rec.CreatedAt)
+	}
+	return c.ID > rec.ID
+}
+
+// cursorBefore reports whether c sorts before rec in (created_at, id) order.
+func cursorBefore(c usecase.OrderCursor, rec usecase.OrderRecord) bool {
+	if !c.CreatedAt.Equal(rec.CreatedAt) {
+		return c.CreatedAt.Before(rec.CreatedAt)
+	}
+	return c.ID < rec.ID
+}
+
+// update applies fn to a copy of the order if it is still at version; fn returning false discards
+// the copy. Applied changes bump the version, as the MySQL repo's compare-and-swap does.
+func (r *OrderRepo) update(id string, version int64, fn func(*usecase.OrderRecord) bool) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec, ok := r.orders[id]
+	if !ok || rec.Version != version || !fn(&rec) {
+		return false, nil
+	}
+	rec.Version++
+	rec.UpdatedAt = time.Now()
+	r.orders[id] = rec
+	return true, nil
+}
+
+// ExportOrders pages forward in (created_at, id) order over the orders matching f.
+func (r *OrderRepo) ExportOrders(ctx context.Context, f usecase.OrderFilter, after usecase.OrderCursor, limit int) ([]usecase.OrderRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var out []usecase.OrderRecord
+	for _, rec := range r.orders {
+		switch {
+		case f.UserID != "" && rec.UserID != f.UserID,
+			f.TenantID != "" && rec.TenantID != f.TenantID,
+			f.Status != "" && rec.Status != f.Status,
+			!f.CreatedFrom.IsZero() && rec.CreatedAt.Before(f.CreatedFrom),
+			!f.CreatedTo.IsZero() && !rec.CreatedAt.Before(f.CreatedTo),
+			after.ID != "" && !cursorBefore(after, rec):
+			continue
+		}
+		out = append(out, rec)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return cursorBefore(usecase.OrderCursor{CreatedAt: out[i].CreatedAt, ID: out[i].ID}, out[j])
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+var (
+	_ usecase.OrderRepo       = (*OrderRepo)(nil)
+	_ usecase.StuckOrderRepo  = (*OrderRepo)(nil)
+	_ usecase.ReconcileRepo   = (*OrderRepo)(nil)
+	_ usecase.OrderListRepo   = (*OrderRepo)(nil)
+	_ usecase.OrderBatchRepo  = (*OrderRepo)(nil)
+	_ usecase.OrderExportRepo = (*OrderRepo)(nil)
+)
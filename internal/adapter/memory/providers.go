@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"github.com/aq2208/gorder-api/configs"
+	"github.com/aq2208/gorder-api/internal/security"
+	"github.com/aq2208/gorder-api/internal/usecase"
+	"github.com/google/wire"
+)
+
+// ProviderSet provides in-memory implementations of every port, for APP_ENV=local-mem and tests.
+// Bind the Gateway to the worker's gateway port in the injector.
+var ProviderSet = wire.NewSet(
+	NewOrderRepo,
+	ProvideCache,
+	ProvideIdempotencyStore,
+	NewNonceStore,
+	NewCommandBus,
+	NewStatusBus,
+	NewGateway,
+	NewLeaderLease,
+	NewDiscrepancyRepo,
+	NewOrderEvents,
+	NewWebhookRepo,
+	NewJobRepo,
+	wire.Bind(new(usecase.OrderRepo), new(*OrderRepo)),
+	wire.Bind(new(usecase.StuckOrderRepo), new(*OrderRepo)),
+	wire.Bind(new(usecase.ReconcileRepo), new(*OrderRepo)),
+	wire.Bind(new(usecase.OrderListRepo), new(*OrderRepo)),
+	wire.Bind(new(usecase.OrderBatchRepo), new(*OrderRepo)),
+	wire.Bind(new(usecase.OrderExportRepo), new(*OrderRepo)),
+	wire.Bind(new(usecase.DiscrepancyRepo), new(*DiscrepancyRepo)),
+	wire.Bind(new(usecase.OrderCache), new(*Cache)),
+	wire.Bind(new(usecase.IdempotencyStore), new(*IdempotencyStore)),
+	wire.Bind(new(security.NonceStore), new(*NonceStore)),
+	wire.Bind(new(usecase.OrderQueue), new(*CommandBus)),
+	wire.Bind(new(usecase.OrderBatchQueue), new(*CommandBus)),
+	wire.Bind(new(usecase.LeaderLease), new(*LeaderLease)),
+	wire.Bind(new(usecase.OrderEventStream), new(*OrderEvents)),
+	wire.Bind(new(usecase.OrderEventPublisher), new(*OrderEvents)),
+	wire.Bind(new(usecase.WebhookRepo), new(*WebhookRepo)),
+	wire.Bind(new(usecase.JobRepo), new(*JobRepo)),
+)
+
+func ProvideCache(cfg configs.Config) *Cache { return NewCache(cfg.Cache.TTL) }
+
+func ProvideIdempotencyStore(cfg configs.Config) *IdempotencyStore {
+	return NewIdempotencyStore(cfg.Idempotency.TTL)
+}
@@ -0,0 +1,172 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aq2208/gorder-api/internal/usecase"
+)
+
+// WebhookRepo keeps subscriptions, deliveries and attempt logs in maps.
+type WebhookRepo struct {
+	mu         sync.Mutex
+	subs       map[string]usecase.WebhookSubscription
+	deliveries map[string]usecase.WebhookDelivery
+	attempts   map[string][]usecase.WebhookAttempt
+}
+
+func NewWebhookRepo() *WebhookRepo {
+	return &WebhookRepo{
+		subs:       map[string]usecase.WebhookSubscription{},
+		deliveries: map[string]usecase.WebhookDelivery{},
+		attempts:   map[string][]usecase.WebhookAttempt{},
+	}
+}
+
+func (r *WebhookRepo) CreateSubscription(ctx context.Context, s *usecase.WebhookSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s.CreatedAt = time.Now()
+	c := *s
+	c.Events = slices.Clone(s.Events)
+	r.subs[s.ID] = c
+	return nil
+}
+
+func (r *WebhookRepo) ListSubscriptions(ctx context.Context, clientID string) ([]usecase.WebhookSubscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []usecase.WebhookSubscription
+	for _, s := range r.subs {
+		if s.ClientID == clientID {
+			s.Events = slices.Clone(s.Events)
+			out = append(out, s)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (r *WebhookRepo) GetSubscription(ctx context.Context, id string) (*usecase.WebhookSubscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.subs[id]
+	if !ok {
+		return nil, usecase.ErrWebhookNotFound
+	}
+	s.Events = slices.Clone(s.Events)
+	return &s, nil
+}
+
+func (r *WebhookRepo) DeleteSubscription(ctx context.Context, clientID, id string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.subs[id]
+	if !ok || s.ClientID != clientID {
+		return false, nil
+	}
+	delete(r.subs, id)
+	return true, nil
+}
+
+func (r *WebhookRepo) EnqueueDeliveries(ctx context.Context, ds []usecase.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for _, d := range ds {
+		if r.queued(d.SubscriptionID, d.EventID) {
+			continue
+		}
+		d.CreatedAt, d.UpdatedAt, d.NextAttemptAt = now, now, now
+		r.deliveries[d.ID] = d
+	}
+	return nil
+}
+
+func (r *WebhookRepo) queued(subscriptionID, eventID string) bool {
+	for _, d := range r.deliveries {
+		if d.SubscriptionID == subscriptionID && d.EventID == eventID {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *WebhookRepo) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]usecase.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	var due []usecase.WebhookDelivery
+	for _, d := range r.deliveries {
+		if d.Status == usecase.DeliveryPending && !d.NextAttemptAt.After(now) {
+			due = append(due, d)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttemptAt.Before(due[j].NextAttemptAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	for _, d := range due {
+		d.NextAttemptAt = now.Add(lease)
+		r.deliveries[d.ID] = d
+	}
+	return due, nil
+}
+
+func (r *WebhookRepo) RecordAttempt(ctx context.Context, d *usecase.WebhookDelivery, a usecase.WebhookAttempt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliveries[d.ID] = *d
+	r.attempts[d.ID] = append(r.attempts[d.ID], a)
+	return nil
+}
+
+func (r *WebhookRepo) GetDelivery(ctx context.Context, clientID, id string) (*usecase.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	d, ok := r.deliveries[id]
+	if !ok || d.ClientID != clientID {
+		return nil, usecase.ErrWebhookNotFound
+	}
+	return &d, nil
+}
+
+func (r *WebhookRepo) ListDeliveries(ctx context.Context, clientID, status string, limit int) ([]usecase.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []usecase.WebhookDelivery
+	for _, d := range r.deliveries {
+		if d.ClientID == clientID && (status == "" || d.Status == status) {
+			out = append(out, d)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (r *WebhookRepo) ListAttempts(ctx context.Context, deliveryID string) ([]usecase.WebhookAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.attempts[deliveryID]), nil
+}
+
+func (r *WebhookRepo) Redeliver(ctx context.Context, clientID, id string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	d, ok := r.deliveries[id]
+	if !ok || d.ClientID != clientID {
+		return false, nil
+	}
+	now := time.Now()
+	d.Status, d.Attempts, d.NextAttemptAt, d.UpdatedAt = usecase.DeliveryPending, 0, now, now
+	r.deliveries[id] = d
+	return true, nil
+}
+
+var _ usecase.WebhookRepo = (*WebhookRepo)(nil)
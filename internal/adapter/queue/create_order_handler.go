@@ -2,7 +2,10 @@ package queue
 
 import (
 	"context"
+	"errors"
+	"log"
 
+	domain "github.com/aq2208/gorder-api/internal/entity"
 	"github.com/aq2208/gorder-api/internal/usecase"
 )
 
@@ -14,15 +17,29 @@ type OrderGateway interface {
 
 // OrderCreatedHandler forwards the event to order-gw via gRPC.
 type OrderCreatedHandler struct {
-	GW OrderGateway
+	Repo usecase.OrderRepo
+	GW   OrderGateway
 }
 
-func NewOrderCreatedHandler(gw OrderGateway) *OrderCreatedHandler {
-	return &OrderCreatedHandler{GW: gw}
+func NewOrderCreatedHandler(repo usecase.OrderRepo, gw OrderGateway) *OrderCreatedHandler {
+	return &OrderCreatedHandler{Repo: repo, GW: gw}
 }
 
 // HandleCreate is intended to be used with the JSON adapter (queue.JSONHandler[CreatedMsg]).
+// Only orders still PROCESSING are forwarded: one cancelled (or otherwise settled) after the
+// command was published is acked without reaching order-gw.
 func (h *OrderCreatedHandler) HandleCreate(ctx context.Context, msg usecase.CreatedMsg) error {
-	// Single responsibility: call the downstream gRPC.
+	rec, err := h.Repo.GetByID(ctx, msg.OrderID)
+	if errors.Is(err, usecase.ErrNotFound) {
+		log.Printf("[order-worker] skip order=%s: not found", msg.OrderID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if rec.Status != string(domain.StatusProcessing) {
+		log.Printf("[order-worker] skip order=%s: status %s", msg.OrderID, rec.Status)
+		return nil
+	}
 	return h.GW.CreateOrder(ctx, msg.OrderID, msg.UserID, msg.Cents, msg.Currency)
 }
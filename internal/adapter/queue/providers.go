@@ -0,0 +1,69 @@
+package queue
+
+import (
+	"github.com/aq2208/gorder-api/configs"
+	"github.com/aq2208/gorder-api/internal/usecase"
+	"github.com/google/wire"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ProviderSet provides the order.created producer (usecase.OrderQueue, OrderBatchQueue) and the worker-side Router.
+// Producer and Router each get their own channel on the shared connection.
+var ProviderSet = wire.NewSet(
+	NewConnection,
+	ProvideProducer,
+	ProvideRouter,
+	NewOrderCreatedHandler,
+	wire.Bind(new(usecase.OrderQueue), new(*RabbitProducer)),
+	wire.Bind(new(usecase.OrderBatchQueue), new(*RabbitProducer)),
+)
+
+// NewConnection dials cfg.Rabbit.URL.
+func NewConnection(cfg configs.Config) (*amqp.Connection, func(), error) {
+	conn, err := amqp.Dial(cfg.Rabbit.URL)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn, func() { _ = conn.Close() }, nil
+}
+
+func topologyOptions(cfg configs.Config) []ProducerOption {
+	return []ProducerOption{
+		WithExchange(cfg.Rabbit.Exchange),
+		WithRoutingKey(cfg.Rabbit.RoutingKey),
+		WithQueue(cfg.Rabbit.Queue),
+	}
+}
+
+func ProvideProducer(conn *amqp.Connection, cfg configs.Config) (*RabbitProducer, func(), error) {
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, nil, err
+	}
+	p, err := NewRabbitProducer(ch, topologyOptions(cfg)...)
+	if err != nil {
+		_ = ch.Close()
+		return nil, nil, err
+	}
+	return p, func() { _ = ch.Close() }, nil
+}
+
+// ProvideRouter registers the order.created handler on cfg.Rabbit.Queue; call Start to consume.
+// The topology is declared here too, since the worker may start before any producer.
+func ProvideRouter(conn *amqp.Connection, cfg configs.Config, h *OrderCreatedHandler) (*Router, func(), error) {
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := DeclareTopology(ch, topologyOptions(cfg)...); err != nil {
+		_ = ch.Close()
+		return nil, nil, err
+	}
+	prefetch := cfg.Rabbit.Prefetch
+	if prefetch <= 0 {
+		prefetch = 50
+	}
+	r := NewRouter(ch, WithPrefetch(prefetch))
+	r.Register(cfg.Rabbit.Queue, JSONHandler[usecase.CreatedMsg]{HandleFunc: h.HandleCreate})
+	return r, func() { _ = ch.Close() }, nil
+}
@@ -10,21 +10,61 @@ import (
 )
 
 const (
-	exchangeName = "order.events"
-	routingKey   = "order.created"
-	queueName    = "order.created.q"
+	DefaultExchange   = "order.events"
+	DefaultRoutingKey = "order.created"
+	DefaultQueue      = "order.created.q"
 )
 
 // RabbitProducer implements usecase.OrderQueue
 type RabbitProducer struct {
-	ch *amqp.Channel
+	ch         *amqp.Channel
+	exchange   string
+	routingKey string
+	queue      string
 }
 
+type ProducerOption func(*RabbitProducer)
+
+func WithExchange(name string) ProducerOption { return func(p *RabbitProducer) { p.exchange = name } }
+func WithRoutingKey(rk string) ProducerOption { return func(p *RabbitProducer) { p.routingKey = rk } }
+func WithQueue(name string) ProducerOption    { return func(p *RabbitProducer) { p.queue = name } }
+
 // NewRabbitProducer sets up the exchange, queue, and binding once at startup.
-func NewRabbitProducer(ch *amqp.Channel) (*RabbitProducer, error) {
+// Defaults: exchange=order.events, routingKey=order.created, queue=order.created.q.
+func NewRabbitProducer(ch *amqp.Channel, opts ...ProducerOption) (*RabbitProducer, error) {
+	p := newProducer(ch, opts)
+	if err := p.declare(); err != nil {
+		return nil, err
+	}
+
+	// enable publisher confirms (optional but recommended)
+	if err := ch.Confirm(false); err != nil {
+		return nil, fmt.Errorf("enable confirm mode: %w", err)
+	}
+
+	return p, nil
+}
+
+// DeclareTopology declares the exchange, queue and binding without creating a producer,
+// for consumers that may start before any producer (e.g. the order-worker binary).
+func DeclareTopology(ch *amqp.Channel, opts ...ProducerOption) error {
+	return newProducer(ch, opts).declare()
+}
+
+func newProducer(ch *amqp.Channel, opts []ProducerOption) *RabbitProducer {
+	p := &RabbitProducer{ch: ch, exchange: DefaultExchange, routingKey: DefaultRoutingKey, queue: DefaultQueue}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+func (p *RabbitProducer) declare() error {
+	ch := p.ch
+
 	// 1. declare exchange (topic type, durable)
 	if err := ch.ExchangeDeclare(
-		exchangeName,
+		p.exchange,
 		"topic",
 		true,  // durable
 		false, // auto-delete
@@ -32,12 +72,12 @@ func NewRabbitProducer(ch *amqp.Channel) (*RabbitProducer, error) {
 		false, // no-wait
 		nil,
 	); err != nil {
-		return nil, fmt.Errorf("declare exchange: %w", err)
+		return fmt.Errorf("declare exchange: %w", err)
 	}
 
 	// 2. declare queue
 	q, err := ch.QueueDeclare(
-		queueName,
+		p.queue,
 		true,  // durable
 		false, // auto-delete
 		false, // exclusive
@@ -45,26 +85,20 @@ func NewRabbitProducer(ch *amqp.Channel) (*RabbitProducer, error) {
 		nil,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("declare queue: %w", err)
+		return fmt.Errorf("declare queue: %w", err)
 	}
 
 	// 3. bind queue → exchange
 	if err := ch.QueueBind(
 		q.Name,
-		routingKey,
-		exchangeName,
+		p.routingKey,
+		p.exchange,
 		false, // no-wait
 		nil,
 	); err != nil {
-		return nil, fmt.Errorf("queue bind: %w", err)
+		return fmt.Errorf("queue bind: %w", err)
 	}
-
-	// 4. enable publisher confirms (optional but recommended)
-	if err := ch.Confirm(false); err != nil {
-		return nil, fmt.Errorf("enable confirm mode: %w", err)
-	}
-
-	return &RabbitProducer{ch: ch}, nil
+	return nil
 }
 
 // PublishCreated sends an "order.created" event to the exchange.
@@ -83,8 +117,8 @@ func (p *RabbitProducer) PublishCreated(ctx context.Context, msg usecase.Created
 	// Publish with context-aware cancellation
 	if err := p.ch.PublishWithContext(
 		ctx,
-		exchangeName, // exchange
-		routingKey,   // routing key
+		p.exchange,   // exchange
+		p.routingKey, // routing key
 		false,        // mandatory
 		false,        // immediate
 		pub,
@@ -94,3 +128,39 @@ func (p *RabbitProducer) PublishCreated(ctx context.Context, msg usecase.Created
 
 	return nil
 }
+
+// PublishCreatedBatch publishes all messages, then waits until the broker has confirmed each one.
+// A nack or a missing confirmation fails the whole batch; messages that did get through stay
+// published (their orders exist, so dispatching them is still correct).
+func (p *RabbitProducer) PublishCreatedBatch(ctx context.Context, msgs []usecase.CreatedMsg) error {
+	confirms := make([]*amqp.DeferredConfirmation, 0, len(msgs))
+	for _, msg := range msgs {
+		body, err := json.Marshal(msg)
+		if err != nil {
+			return fmt.Errorf("marshal message: %w", err)
+		}
+		dc, err := p.ch.PublishWithDeferredConfirmWithContext(ctx, p.exchange, p.routingKey, false, false, amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			Body:         body,
+		})
+		if err != nil {
+			return fmt.Errorf("publish: %w", err)
+		}
+		confirms = append(confirms, dc)
+	}
+
+	for i, dc := range confirms {
+		if dc == nil { // channel not in confirm mode
+			continue
+		}
+		acked, err := dc.WaitContext(ctx)
+		if err != nil {
+			return fmt.Errorf("confirm %s: %w", msgs[i].OrderID, err)
+		}
+		if !acked {
+			return fmt.Errorf("broker nacked %s", msgs[i].OrderID)
+		}
+	}
+	return nil
+}
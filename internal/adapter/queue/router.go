@@ -3,6 +3,7 @@ package queue
 import (
 	"context"
 	"log"
+	"sync"
 	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
@@ -10,6 +11,8 @@ import (
 
 // Router manages multiple consumers (one per registered queue) on a single AMQP channel.
 type Router struct {
+	mu            sync.Mutex
+	started       bool
 	ch            *amqp.Channel
 	prefetch      int
 	callTimeout   time.Duration
@@ -54,45 +57,82 @@ func (r *Router) Register(queueName string, h Handler) {
 	})
 }
 
+// SetPrefetch changes the prefetch while consuming (config hot reload).
+// RabbitMQ applies basic.qos only to consumers created afterwards, so every consumer is re-subscribed;
+// deliveries already in flight are still acked/nacked on the same channel.
+func (r *Router) SetPrefetch(n int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if n <= 0 || n == r.prefetch {
+		return nil
+	}
+	if err := r.ch.Qos(n, 0, false); err != nil {
+		return err
+	}
+	r.prefetch = n
+	if !r.started {
+		return nil
+	}
+	for _, reg := range r.registrations {
+		if err := r.ch.Cancel(reg.consumerTag, false); err != nil {
+			return err
+		}
+		if err := r.consume(reg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Start begins consuming; non-blocking (spawns one goroutine per queue).
 // QoS (prefetch) is set per-channel and applies to all consumers on this channel.
 func (r *Router) Start() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	if err := r.ch.Qos(r.prefetch, 0, false); err != nil {
 		return err
 	}
 
 	for _, reg := range r.registrations {
-		deliveries, err := r.ch.Consume(
-			reg.queueName,
-			reg.consumerTag,
-			false, // manual ack
-			false, // exclusive
-			false, // no-local
-			false, // no-wait
-			nil,
-		)
-		if err != nil {
+		if err := r.consume(reg); err != nil {
 			return err
 		}
+	}
+	r.started = true
 
-		go func(queueName, tag string, h Handler, msgs <-chan amqp.Delivery) {
-			for d := range msgs {
-				log.Printf("[rmq-router] handler queue=%s tag=%s rk=%s body=%s", queueName, tag, d.RoutingKey, d.Body)
-				ctx, cancel := context.WithTimeout(context.Background(), r.callTimeout)
-				err := h.Handle(ctx, d)
-				cancel()
-
-				if err != nil {
-					log.Printf("[rmq-router] handler error queue=%s tag=%s rk=%s err=%v requeue=%v",
-						queueName, tag, d.RoutingKey, err, r.requeueOnErr)
-					_ = d.Nack(false, r.requeueOnErr)
-					continue
-				}
-				_ = d.Ack(false)
-			}
-			log.Printf("[rmq-router] consumer stopped queue=%s tag=%s", queueName, tag)
-		}(reg.queueName, reg.consumerTag, reg.handler, deliveries)
+	return nil
+}
+
+func (r *Router) consume(reg registration) error {
+	deliveries, err := r.ch.Consume(
+		reg.queueName,
+		reg.consumerTag,
+		false, // manual ack
+		false, // exclusive
+		false, // no-local
+		false, // no-wait
+		nil,
+	)
+	if err != nil {
+		return err
 	}
 
+	go func(queueName, tag string, h Handler, msgs <-chan amqp.Delivery) {
+		for d := range msgs {
+			log.Printf("[rmq-router] handler queue=%s tag=%s rk=%s body=%s", queueName, tag, d.RoutingKey, d.Body)
+			ctx, cancel := context.WithTimeout(context.Background(), r.callTimeout)
+			err := h.Handle(ctx, d)
+			cancel()
+
+			if err != nil {
+				log.Printf("[rmq-router] handler error queue=%s tag=%s rk=%s err=%v requeue=%v",
+					queueName, tag, d.RoutingKey, err, r.requeueOnErr)
+				_ = d.Nack(false, r.requeueOnErr)
+				continue
+			}
+			_ = d.Ack(false)
+		}
+		log.Printf("[rmq-router] consumer stopped queue=%s tag=%s", queueName, tag)
+	}(reg.queueName, reg.consumerTag, reg.handler, deliveries)
 	return nil
 }
@@ -0,0 +1,29 @@
+package reaper
+
+import (
+	"github.com/aq2208/gorder-api/configs"
+	"github.com/aq2208/gorder-api/internal/usecase"
+	"github.com/google/wire"
+)
+
+// ProviderSet provides the stuck-order reaper; it needs a StuckOrderRepo, OrderQueue,
+// OrderEventPublisher and LeaderLease.
+var ProviderSet = wire.NewSet(
+	ProvideReapStuckOrders,
+	ProvideRunner,
+)
+
+func ProvideReapStuckOrders(cfg configs.Config, repo usecase.StuckOrderRepo, cache usecase.OrderCache, queue usecase.OrderQueue,
+	events usecase.OrderEventPublisher, webhooks *usecase.NotifyWebhooks) *usecase.ReapStuckOrders {
+	return usecase.NewReapStuckOrders(repo, cache, queue,
+		usecase.WithReapEvents(events),
+		usecase.WithReapWebhooks(webhooks),
+		usecase.WithSLA(cfg.Reaper.SLA),
+		usecase.WithMaxAttempts(cfg.Reaper.MaxAttempts),
+		usecase.WithBatchSize(cfg.Reaper.BatchSize),
+	)
+}
+
+func ProvideRunner(cfg configs.Config, uc *usecase.ReapStuckOrders, lease usecase.LeaderLease) *Runner {
+	return NewRunner(uc, lease, cfg.Reaper.Interval, cfg.Reaper.Enabled)
+}
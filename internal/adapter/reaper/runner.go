@@ -0,0 +1,98 @@
+package reaper
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/aq2208/gorder-api/internal/logging"
+	"github.com/aq2208/gorder-api/internal/usecase"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	reapedOrders = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "reaper_orders_total",
+			Help: "Stuck orders handled by the reaper",
+		},
+		[]string{"action"}, // redispatched | failed
+	)
+	reaperLeader = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "reaper_leader",
+		Help: "1 while this instance holds the reaper lease",
+	})
+)
+
+// Runner executes the reaper every interval on the replica holding the lease. Every tick renews the
+// lease, so it must outlive the interval; if renewal fails the instance stops scanning until it wins
+// the lease again.
+type Runner struct {
+	uc       *usecase.ReapStuckOrders
+	lease    usecase.LeaderLease
+	interval time.Duration
+	enabled  bool
+}
+
+func NewRunner(uc *usecase.ReapStuckOrders, lease usecase.LeaderLease, interval time.Duration, enabled bool) *Runner {
+	return &Runner{uc: uc, lease: lease, interval: interval, enabled: enabled}
+}
+
+// Run blocks until ctx is cancelled, then gives up the lease so another replica takes over at once.
+func (r *Runner) Run(ctx context.Context) {
+	l := logging.New("reaper")
+	if !r.enabled {
+		l.Info("reaper disabled")
+		return
+	}
+
+	t := time.NewTicker(r.interval)
+	defer t.Stop()
+	leader := false
+	for {
+		leader = r.tick(ctx, l, leader)
+		select {
+		case <-ctx.Done():
+			if leader {
+				rctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+				_ = r.lease.Release(rctx)
+				cancel()
+				reaperLeader.Set(0)
+			}
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (r *Runner) tick(ctx context.Context, l *slog.Logger, wasLeader bool) bool {
+	leader, err := r.lease.Acquire(ctx)
+	if err != nil {
+		l.Warn("reaper lease", "err", err)
+		leader = false
+	}
+	if leader != wasLeader {
+		l.Info("reaper leadership changed", "leader", leader)
+		if leader {
+			reaperLeader.Set(1)
+		} else {
+			reaperLeader.Set(0)
+		}
+	}
+	if !leader {
+		return false
+	}
+
+	res, err := r.uc.Execute(ctx)
+	if err != nil {
+		l.Warn("reaper scan failed", "err", err)
+		return true
+	}
+	reapedOrders.WithLabelValues("redispatched").Add(float64(res.Redispatched))
+	reapedOrders.WithLabelValues("failed").Add(float64(res.Failed))
+	if res.Redispatched > 0 || res.Failed > 0 {
+		l.Info("reaped stuck orders", "redispatched", res.Redispatched, "failed", res.Failed)
+	}
+	return true
+}
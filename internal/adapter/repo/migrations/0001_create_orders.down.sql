@@ -0,0 +1,2 @@
+-- Irreversible: the baseline may have adopted an existing orders table, and rolling back must not
+-- drop production orders. Drop the table by hand if that is really wanted.
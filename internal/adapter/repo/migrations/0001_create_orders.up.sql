@@ -1,4 +1,6 @@
-CREATE TABLE orders (
+-- Baseline: the orders table exactly as the former deployments/migrations.sql created it. IF NOT EXISTS
+-- adopts such a database; the later migrations bring it up to date.
+CREATE TABLE IF NOT EXISTS orders (
     id              VARCHAR(64)  NOT NULL PRIMARY KEY,
     user_id         VARCHAR(64)  NOT NULL,
     status          VARCHAR(32)  NOT NULL,
@@ -0,0 +1,3 @@
+ALTER TABLE orders
+    DROP COLUMN client_id,
+    DROP COLUMN tenant_id;
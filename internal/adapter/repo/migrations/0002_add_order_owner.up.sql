@@ -0,0 +1,4 @@
+-- Tenant and API client that created the order (tenant authorization, webhooks).
+ALTER TABLE orders
+    ADD COLUMN tenant_id VARCHAR(64) DEFAULT NULL AFTER user_id,
+    ADD COLUMN client_id VARCHAR(64) DEFAULT NULL AFTER tenant_id;
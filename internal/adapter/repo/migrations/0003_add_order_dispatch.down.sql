@@ -0,0 +1,4 @@
+ALTER TABLE orders
+    DROP KEY idx_orders_status_updated,
+    DROP COLUMN failure_reason,
+    DROP COLUMN dispatch_attempts;
@@ -0,0 +1,5 @@
+-- Stuck-order reaper: re-dispatch count, why an order failed, and the (status, updated_at) scan.
+ALTER TABLE orders
+    ADD COLUMN dispatch_attempts INT NOT NULL DEFAULT 0 AFTER version,
+    ADD COLUMN failure_reason VARCHAR(255) DEFAULT NULL AFTER dispatch_attempts,
+    ADD KEY idx_orders_status_updated (status, updated_at);
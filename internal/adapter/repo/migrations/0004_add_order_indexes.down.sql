@@ -0,0 +1,4 @@
+ALTER TABLE orders
+    DROP KEY uk_orders_client_user_idem,
+    DROP KEY idx_orders_user_created,
+    DROP KEY idx_orders_created_id;
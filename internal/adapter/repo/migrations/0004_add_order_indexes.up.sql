@@ -0,0 +1,6 @@
+-- Reconciliation and export paging (created_at, id), ListOrders per user, and one order per
+-- idempotency key and caller (rows without a key are NULL there and never collide).
+ALTER TABLE orders
+    ADD KEY idx_orders_created_id (created_at, id),
+    ADD KEY idx_orders_user_created (user_id, created_at, id),
+    ADD UNIQUE KEY uk_orders_client_user_idem (client_id, user_id, idempotency_key);
@@ -0,0 +1,17 @@
+-- Reconciliation report: one row per difference between orders and order-gw (order-api reconcile).
+CREATE TABLE order_discrepancies (
+    id                   BIGINT       NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    run_id               VARCHAR(64)  NOT NULL,
+    order_id             VARCHAR(64)  NOT NULL,
+    kind                 VARCHAR(32)  NOT NULL,  -- status_mismatch | amount_mismatch | missing_at_gateway | unknown_gateway_status
+    action               VARCHAR(32)  NOT NULL,  -- reported | corrected | correction_skipped
+    local_status         VARCHAR(32)  NOT NULL,
+    gateway_status       VARCHAR(32)  DEFAULT NULL,
+    local_amount_cents   BIGINT       NOT NULL,
+    gateway_amount_cents BIGINT       DEFAULT NULL,
+    local_currency       VARCHAR(8)   NOT NULL,
+    gateway_currency     VARCHAR(8)   DEFAULT NULL,
+    detected_at          DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY idx_discrepancies_run (run_id),
+    KEY idx_discrepancies_order (order_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,43 @@
+-- Webhooks: client subscriptions, the delivery queue and one log row per attempt.
+CREATE TABLE webhook_subscriptions (
+    id         VARCHAR(64)   NOT NULL PRIMARY KEY,
+    client_id  VARCHAR(64)   NOT NULL,
+    url        VARCHAR(2048) NOT NULL,
+    secret     VARCHAR(128)  NOT NULL,   -- HMAC-SHA256 key for X-Webhook-Signature
+    events     VARCHAR(255)  NOT NULL,   -- comma-separated: order.confirmed,order.failed
+    created_at DATETIME      NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY idx_webhook_subs_client (client_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE webhook_deliveries (
+    id               VARCHAR(64)  NOT NULL PRIMARY KEY,
+    subscription_id  VARCHAR(64)  NOT NULL,
+    client_id        VARCHAR(64)  NOT NULL,
+    event_id         VARCHAR(128) NOT NULL,   -- <order id>:<event type>, stable across retries
+    event_type       VARCHAR(32)  NOT NULL,
+    order_id         VARCHAR(64)  NOT NULL,
+    payload          JSON         NOT NULL,
+    status           VARCHAR(16)  NOT NULL,   -- pending | delivered | failed
+    attempts         INT          NOT NULL DEFAULT 0,
+    next_attempt_at  DATETIME(3)  NOT NULL,
+    claim_token      VARCHAR(64)  DEFAULT NULL,
+    last_status_code INT          DEFAULT NULL,
+    last_error       VARCHAR(512) DEFAULT NULL,
+    created_at       DATETIME(3)  NOT NULL,
+    updated_at       DATETIME(3)  NOT NULL,
+    delivered_at     DATETIME(3)  DEFAULT NULL,
+    UNIQUE KEY uk_deliveries_sub_event (subscription_id, event_id),
+    KEY idx_deliveries_due (status, next_attempt_at),
+    KEY idx_deliveries_claim (claim_token),
+    KEY idx_deliveries_client (client_id, created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE webhook_delivery_attempts (
+    id           BIGINT       NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    delivery_id  VARCHAR(64)  NOT NULL,
+    status_code  INT          DEFAULT NULL,   -- NULL when no response arrived
+    error        VARCHAR(512) DEFAULT NULL,
+    duration_ms  INT          NOT NULL,
+    attempted_at DATETIME(3)  NOT NULL,
+    KEY idx_attempts_delivery (delivery_id, id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,27 @@
+-- Bulk import/export jobs (/v1/jobs). Files live under jobs.dir; a running job whose claim expired is
+-- resumed by another API replica.
+CREATE TABLE jobs (
+    id            VARCHAR(64)  NOT NULL PRIMARY KEY,
+    kind          VARCHAR(16)  NOT NULL,   -- import | export
+    format        VARCHAR(16)  NOT NULL,   -- csv | ndjson | parquet (exports)
+    status        VARCHAR(16)  NOT NULL,   -- queued | running | completed | failed
+    client_id     VARCHAR(64)  NOT NULL,   -- submitting caller; imports create orders as this caller
+    subject       VARCHAR(64)  DEFAULT NULL,
+    tenant_id     VARCHAR(64)  DEFAULT NULL,
+    perms         VARCHAR(512) NOT NULL,   -- comma-separated
+    filter        JSON         DEFAULT NULL,   -- exports: user_id, tenant_id, status, created_from, created_to
+    total         INT          NOT NULL DEFAULT 0,
+    processed     INT          NOT NULL DEFAULT 0,
+    succeeded     INT          NOT NULL DEFAULT 0,
+    failed        INT          NOT NULL DEFAULT 0,
+    error         VARCHAR(512) DEFAULT NULL,
+    claim_token   VARCHAR(64)  DEFAULT NULL,
+    claimed_until DATETIME(3)  DEFAULT NULL,
+    created_at    DATETIME(3)  NOT NULL,
+    updated_at    DATETIME(3)  NOT NULL,
+    started_at    DATETIME(3)  DEFAULT NULL,
+    finished_at   DATETIME(3)  DEFAULT NULL,
+    KEY idx_jobs_claim (status, created_at),
+    KEY idx_jobs_claim_token (claim_token),
+    KEY idx_jobs_client (client_id, created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
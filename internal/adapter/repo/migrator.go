@@ -0,0 +1,294 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Migrations are embedded in the binary as migrations/NNNN_name.up.sql with a matching .down.sql.
+// A statement ends at a line ending in ";" (the driver runs one statement per Exec). A .down.sql
+// with no statements (only comments) marks the migration irreversible.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrateLockName is the MySQL advisory lock held while migrating, so replicas starting together
+// apply each migration once.
+const migrateLockName = "order-api:schema_migrations"
+
+// Migration is one schema version. Down is empty for an irreversible migration.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      []string
+	Down    []string
+	hasDown bool
+}
+
+func (m Migration) Irreversible() bool { return len(m.Down) == 0 }
+
+// MigrationStatus is one row of Migrator.Status. Unknown marks a version recorded in
+// schema_migrations that this binary does not ship (a newer release migrated the database).
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	AppliedAt time.Time // zero while pending
+	Unknown   bool
+}
+
+func (s MigrationStatus) Applied() bool { return !s.AppliedAt.IsZero() }
+
+// Migrations returns the embedded migrations in version order.
+func Migrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int64]*Migration{}
+	for _, e := range entries {
+		base, dir, ok := strings.Cut(e.Name(), ".")
+		if !ok || (dir != "up.sql" && dir != "down.sql") {
+			return nil, fmt.Errorf("migration %s: want NNNN_name.up.sql or .down.sql", e.Name())
+		}
+		num, name, _ := strings.Cut(base, "_")
+		v, err := strconv.ParseInt(num, 10, 64)
+		if err != nil || v <= 0 || name == "" {
+			return nil, fmt.Errorf("migration %s: want NNNN_name.up.sql or .down.sql", e.Name())
+		}
+		raw, err := migrationFiles.ReadFile(path.Join("migrations", e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		m := byVersion[v]
+		if m == nil {
+			m = &Migration{Version: v, Name: name}
+			byVersion[v] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migration %d: named both %s and %s", v, m.Name, name)
+		}
+		stmts := splitStatements(string(raw))
+		if dir == "up.sql" {
+			if len(stmts) == 0 {
+				return nil, fmt.Errorf("migration %s: no statements", e.Name())
+			}
+			m.Up = stmts
+		} else {
+			m.Down, m.hasDown = stmts, true
+		}
+	}
+
+	out := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == nil || !m.hasDown {
+			return nil, fmt.Errorf("migration %d_%s: needs both up and down", m.Version, m.Name)
+		}
+		out = append(out, *m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	for i, m := range out {
+		if m.Version != int64(i+1) {
+			return nil, fmt.Errorf("migration %d_%s: versions must run 1..n without gaps", m.Version, m.Name)
+		}
+	}
+	return out, nil
+}
+
+func splitStatements(src string) []string {
+	var (
+		out []string
+		cur strings.Builder
+	)
+	for _, line := range strings.Split(src, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		cur.WriteString(line)
+		cur.WriteByte('\n')
+		if strings.HasSuffix(trimmed, ";") {
+			out = append(out, strings.TrimSuffix(strings.TrimSpace(cur.String()), ";"))
+			cur.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(cur.String()); rest != "" {
+		out = append(out, rest)
+	}
+	return out
+}
+
+// Migrator applies and rolls back the embedded migrations, recording each in schema_migrations.
+type Migrator struct {
+	db          *sql.DB
+	migrations  []Migration
+	lockTimeout time.Duration
+}
+
+// NewMigrator waits up to lockTimeout for another process's migration run to finish.
+func NewMigrator(db *sql.DB, lockTimeout time.Duration) (*Migrator, error) {
+	ms, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: ms, lockTimeout: lockTimeout}, nil
+}
+
+// Up applies every pending migration in order and returns those it applied.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func(conn *sql.Conn, applied map[int64]time.Time) error {
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			if err := execAll(ctx, conn, mig.Up); err != nil {
+				return fmt.Errorf("migration %d_%s up: %w", mig.Version, mig.Name, err)
+			}
+			if _, err := conn.ExecContext(ctx,
+				`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+				mig.Version, mig.Name, time.Now().UTC()); err != nil {
+				return err
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down rolls back the latest steps applied migrations, newest first, and returns those it rolled back.
+// It refuses, before changing anything, when the steps reach an irreversible migration.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func(conn *sql.Conn, applied map[int64]time.Time) error {
+		versions := make([]int64, 0, len(applied))
+		for v := range applied {
+			versions = append(versions, v)
+		}
+		sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+		versions = versions[:min(steps, len(versions))]
+		for i, v := range versions {
+			if v > int64(len(m.migrations)) {
+				return fmt.Errorf("migration %d was applied by a newer release; roll back with that binary", v)
+			}
+			if mig := m.migrations[v-1]; mig.Irreversible() {
+				return fmt.Errorf("migration %d_%s is irreversible; roll back at most %d", mig.Version, mig.Name, i)
+			}
+		}
+		for _, v := range versions {
+			mig := m.migrations[v-1]
+			if err := execAll(ctx, conn, mig.Down); err != nil {
+				return fmt.Errorf("migration %d_%s down: %w", mig.Version, mig.Name, err)
+			}
+			if _, err := conn.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = ?`, v); err != nil {
+				return err
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Status lists every known migration with when it was applied, followed by applied versions this
+// binary does not know. It reads without taking the lock and without creating schema_migrations.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, names, err := readApplied(ctx, m.db)
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) && myErr.Number == 1146 { // table doesn't exist: nothing applied yet
+		applied, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	out := make([]MigrationStatus, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		out = append(out, MigrationStatus{Version: mig.Version, Name: mig.Name, AppliedAt: applied[mig.Version]})
+	}
+	for v, at := range applied {
+		if v > int64(len(m.migrations)) {
+			out = append(out, MigrationStatus{Version: v, Name: names[v], AppliedAt: at, Unknown: true})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
+
+// locked runs fn on one connection holding the advisory lock, with schema_migrations in place and
+// read after the lock was taken.
+func (m *Migrator) locked(ctx context.Context, fn func(*sql.Conn, map[int64]time.Time) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// GET_LOCK is per session, hence the dedicated connection; it returns 1 when taken, 0 on timeout
+	var got sql.NullInt64
+	secs := max(int64(m.lockTimeout/time.Second), 0)
+	if err := conn.QueryRowContext(ctx, `SELECT GET_LOCK(?, ?)`, migrateLockName, secs).Scan(&got); err != nil {
+		return err
+	}
+	if got.Int64 != 1 {
+		return fmt.Errorf("migration lock %q still held by another process after %s", migrateLockName, m.lockTimeout)
+	}
+	defer func() { _, _ = conn.ExecContext(context.Background(), `DO RELEASE_LOCK(?)`, migrateLockName) }()
+
+	if _, err := conn.ExecContext(ctx, `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version    BIGINT       NOT NULL PRIMARY KEY,
+    name       VARCHAR(128) NOT NULL,
+    applied_at DATETIME(3)  NOT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`); err != nil {
+		return err
+	}
+	applied, _, err := readApplied(ctx, conn)
+	if err != nil {
+		return err
+	}
+	return fn(conn, applied)
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func readApplied(ctx context.Context, q queryer) (map[int64]time.Time, map[int64]string, error) {
+	rows, err := q.QueryContext(ctx, `SELECT version, name, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	applied, names := map[int64]time.Time{}, map[int64]string{}
+	for rows.Next() {
+		var (
+			v    int64
+			name string
+			at   time.Time
+		)
+		if err := rows.Scan(&v, &name, &at); err != nil {
+			return nil, nil, err
+		}
+		applied[v], names[v] = at, name
+	}
+	return applied, names, rows.Err()
+}
+
+func execAll(ctx context.Context, conn *sql.Conn, stmts []string) error {
+	for _, s := range stmts {
+		if _, err := conn.ExecContext(ctx, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+//go:build mysql
+
+package repo
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Run with a MySQL the test may create databases on:
+//
+//	ORDER_API_TEST_MYSQL_DSN="root:root@tcp(127.0.0.1:3306)/" go test -tags mysql ./internal/adapter/repo/
+
+// baselineOrdersDDL is the orders table of the former deployments/migrations.sql.
+const baselineOrdersDDL = `
+CREATE TABLE orders (
+    id              VARCHAR(64)  NOT NULL PRIMARY KEY,
+    user_id         VARCHAR(64)  NOT NULL,
+    status          VARCHAR(32)  NOT NULL,
+    amount_cents    BIGINT       NOT NULL,
+    currency        VARCHAR(8)   NOT NULL,
+    items_json      JSON         NOT NULL,
+    idempotency_key VARCHAR(64)  DEFAULT NULL,
+    version         INT          NOT NULL DEFAULT 0,
+    created_at      DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at      DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`
+
+// testDB opens a fresh, empty database that is dropped when the test ends.
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("ORDER_API_TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("ORDER_API_TEST_MYSQL_DSN not set")
+	}
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.DBName, cfg.ParseTime = "", true
+	admin, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = admin.Close() })
+
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	cfg.DBName = "order_api_migrate_" + hex.EncodeToString(suffix)
+	if _, err := admin.Exec("CREATE DATABASE " + cfg.DBName); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _, _ = admin.Exec("DROP DATABASE " + cfg.DBName) })
+
+	db, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func orderColumnsIn(t *testing.T, db *sql.DB) map[string]bool {
+	t.Helper()
+	rows, err := db.Query(`SELECT column_name FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = 'orders'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	cols := map[string]bool{}
+	for rows.Next() {
+		var c string
+		if err := rows.Scan(&c); err != nil {
+			t.Fatal(err)
+		}
+		cols[c] = true
+	}
+	return cols
+}
+
+// A database created from the former migrations.sql is adopted and brought up to date without losing
+// orders; down stops at the baseline, and up runs again cleanly.
+func TestMigratorUpDownUp(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	if _, err := db.Exec(baselineOrdersDDL); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO orders (id, user_id, status, amount_cents, currency, items_json)
+VALUES ('o-1', 'u-1', 'CONFIRMED', 100, 'USD', '[]')`); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewMigrator(db, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done, err := m.Up(ctx)
+	if err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if len(done) != len(m.migrations) {
+		t.Fatalf("applied %d migrations, want %d", len(done), len(m.migrations))
+	}
+	cols := orderColumnsIn(t, db)
+	for _, c := range strings.Split(orderColumns, ",") {
+		if !cols[c] {
+			t.Fatalf("orders.%s missing after up", c)
+		}
+	}
+	rec, err := NewMySQLOrderRepo(db).GetByID(ctx, "o-1")
+	if err != nil || rec.Status != "CONFIRMED" {
+		t.Fatalf("adopted order: %+v %v", rec, err)
+	}
+
+	// rolling back through the baseline is refused before anything changes
+	if _, err := m.Down(ctx, len(m.migrations)); err == nil || !strings.Contains(err.Error(), "irreversible") {
+		t.Fatalf("down through the baseline: %v", err)
+	}
+	done, err = m.Down(ctx, len(m.migrations)-1)
+	if err != nil || len(done) != len(m.migrations)-1 {
+		t.Fatalf("down: %d %v", len(done), err)
+	}
+	if cols := orderColumnsIn(t, db); cols["tenant_id"] || !cols["idempotency_key"] {
+		t.Fatalf("orders after down = %v, want the baseline", cols)
+	}
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM orders`).Scan(&n); err != nil || n != 1 {
+		t.Fatalf("orders after down: %d %v", n, err)
+	}
+
+	if done, err = m.Up(ctx); err != nil || len(done) != len(m.migrations)-1 {
+		t.Fatalf("second up: %d %v", len(done), err)
+	}
+	status, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range status {
+		if !s.Applied() || s.Unknown {
+			t.Fatalf("status after up: %+v", s)
+		}
+	}
+}
+
+// Replicas migrating together apply each migration once; a run gives up when the lock stays held.
+func TestMigratorLock(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		applied int
+		errs    []error
+	)
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m, err := NewMigrator(db, 30*time.Second)
+			if err == nil {
+				var done []Migration
+				done, err = m.Up(ctx)
+				mu.Lock()
+				applied += len(done)
+				mu.Unlock()
+			}
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	ms, _ := Migrations()
+	if len(errs) > 0 || applied != len(ms) {
+		t.Fatalf("concurrent up applied %d of %d migrations, errors %v", applied, len(ms), errs)
+	}
+
+	// another session holds the lock
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var got int
+	if err := conn.QueryRowContext(ctx, `SELECT GET_LOCK(?, 0)`, migrateLockName).Scan(&got); err != nil || got != 1 {
+		t.Fatalf("take lock: %d %v", got, err)
+	}
+	m, err := NewMigrator(db, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(ctx); err == nil || !strings.Contains(err.Error(), "still held") {
+		t.Fatalf("up while locked: %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package repo
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var (
+	createTableStmt = regexp.MustCompile(`(?is)^CREATE TABLE (?:IF NOT EXISTS )?(\w+) \((.*)\)`)
+	dropTableStmt   = regexp.MustCompile(`(?i)^DROP TABLE (?:IF EXISTS )?(\w+)$`)
+	alterTableStmt  = regexp.MustCompile(`(?is)^ALTER TABLE (\w+)\s+(.*)$`)
+	columnDef       = regexp.MustCompile(`(?m)^\s+(\w+)\s+[A-Z]+`)
+	alterClause     = regexp.MustCompile(`(?i)^(ADD|DROP) (?:UNIQUE )?(COLUMN|KEY) (\w+)`)
+	clauseSep       = regexp.MustCompile(`,\s*\n`) // one ALTER clause per line
+)
+
+// baselineOrderColumns are the orders columns of the former deployments/migrations.sql.
+var baselineOrderColumns = []string{
+	"id", "user_id", "status", "amount_cents", "currency", "items_json", "idempotency_key", "version",
+	"created_at", "updated_at",
+}
+
+// schemaChange is what one statement does: a table created or dropped, or columns and keys added or
+// dropped from one.
+type schemaChange struct {
+	table, op string   // op: create, drop, alter
+	columns   []string // create: the table's columns
+	adds      []string // alter: "column x" / "key x"
+	drops     []string
+}
+
+func parseStatement(t *testing.T, stmt string) schemaChange {
+	t.Helper()
+	if m := createTableStmt.FindStringSubmatch(stmt); m != nil {
+		c := schemaChange{table: m[1], op: "create"}
+		for _, col := range columnDef.FindAllStringSubmatch(m[2], -1) {
+			if name := strings.ToUpper(col[1]); name != "KEY" && name != "UNIQUE" && name != "PRIMARY" {
+				c.columns = append(c.columns, col[1])
+			}
+		}
+		return c
+	}
+	if m := dropTableStmt.FindStringSubmatch(stmt); m != nil {
+		return schemaChange{table: m[1], op: "drop"}
+	}
+	if m := alterTableStmt.FindStringSubmatch(stmt); m != nil {
+		c := schemaChange{table: m[1], op: "alter"}
+		for _, clause := range clauseSep.Split(m[2], -1) {
+			cm := alterClause.FindStringSubmatch(strings.TrimSpace(clause))
+			if cm == nil {
+				t.Fatalf("unexpected ALTER clause %q", clause)
+			}
+			item := strings.ToLower(cm[2]) + " " + cm[3]
+			if strings.EqualFold(cm[1], "ADD") {
+				c.adds = append(c.adds, item)
+			} else {
+				c.drops = append(c.drops, item)
+			}
+		}
+		return c
+	}
+	t.Fatalf("unexpected statement %q", stmt)
+	return schemaChange{}
+}
+
+// The embedded migrations start from the baseline orders table, bring it to what the adapters
+// query, create the other tables, and each reversible down undoes exactly its up.
+func TestMigrations(t *testing.T) {
+	ms, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := ms[0]
+	if !base.Irreversible() {
+		t.Fatal("baseline migration must not drop orders on the way down")
+	}
+	if len(base.Up) != 1 {
+		t.Fatalf("baseline has %d statements, want 1", len(base.Up))
+	}
+	if c := parseStatement(t, base.Up[0]); c.op != "create" || c.table != "orders" || !slices.Equal(c.columns, baselineOrderColumns) {
+		t.Fatalf("baseline = %+v, want orders with %v", c, baselineOrderColumns)
+	}
+
+	tables := map[string][]string{}
+	for _, m := range ms {
+		var created []string
+		added := map[string][]string{}
+		for _, stmt := range m.Up {
+			c := parseStatement(t, stmt)
+			switch c.op {
+			case "create":
+				if _, ok := tables[c.table]; ok {
+					t.Fatalf("%d_%s: creates %s again", m.Version, m.Name, c.table)
+				}
+				tables[c.table] = c.columns
+				created = append(created, c.table)
+			case "alter":
+				if _, ok := tables[c.table]; !ok || len(c.drops) > 0 {
+					t.Fatalf("%d_%s: up alters %s: %q", m.Version, m.Name, c.table, stmt)
+				}
+				for _, item := range c.adds {
+					if col, ok := strings.CutPrefix(item, "column "); ok {
+						tables[c.table] = append(tables[c.table], col)
+					}
+				}
+				added[c.table] = append(added[c.table], c.adds...)
+			default:
+				t.Fatalf("%d_%s: up statement %q", m.Version, m.Name, stmt)
+			}
+		}
+		if m.Irreversible() {
+			if m.Version != 1 {
+				t.Fatalf("%d_%s: only the baseline may be irreversible", m.Version, m.Name)
+			}
+			continue
+		}
+
+		for _, stmt := range m.Down {
+			c := parseStatement(t, stmt)
+			switch c.op {
+			case "drop":
+				i := slices.Index(created, c.table)
+				if i < 0 {
+					t.Fatalf("%d_%s: down drops %s, which its up did not create", m.Version, m.Name, c.table)
+				}
+				created = slices.Delete(created, i, i+1)
+			case "alter":
+				for _, item := range c.drops {
+					i := slices.Index(added[c.table], item)
+					if i < 0 {
+						t.Fatalf("%d_%s: down drops %s on %s, which its up did not add", m.Version, m.Name, item, c.table)
+					}
+					added[c.table] = slices.Delete(added[c.table], i, i+1)
+				}
+			default:
+				t.Fatalf("%d_%s: down statement %q", m.Version, m.Name, stmt)
+			}
+		}
+		if len(created) > 0 {
+			t.Fatalf("%d_%s: down leaves tables %v", m.Version, m.Name, created)
+		}
+		for table, items := range added {
+			if len(items) > 0 {
+				t.Fatalf("%d_%s: down leaves %v on %s", m.Version, m.Name, items, table)
+			}
+		}
+	}
+
+	for _, col := range strings.Split(orderColumns, ",") {
+		if !slices.Contains(tables["orders"], col) {
+			t.Errorf("orders.%s is queried but no migration adds it", col)
+		}
+	}
+	for _, table := range []string{
+		"order_discrepancies", "webhook_subscriptions", "webhook_deliveries", "webhook_delivery_attempts", "jobs",
+	} {
+		if _, ok := tables[table]; !ok {
+			t.Errorf("no migration creates %s", table)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/aq2208/gorder-api/internal/usecase"
+)
+
+// MySQLDiscrepancyRepo writes the reconciliation report to order_discrepancies.
+type MySQLDiscrepancyRepo struct{ db *sql.DB }
+
+func NewMySQLDiscrepancyRepo(db *sql.DB) *MySQLDiscrepancyRepo { return &MySQLDiscrepancyRepo{db: db} }
+
+func (r *MySQLDiscrepancyRepo) InsertDiscrepancies(ctx context.Context, ds []usecase.Discrepancy) error {
+	if len(ds) == 0 {
+		return nil
+	}
+	const cols = 10
+	q := `
+INSERT INTO order_discrepancies
+  (run_id,order_id,kind,action,local_status,gateway_status,local_amount_cents,gateway_amount_cents,local_currency,gateway_currency,detected_at)
+VALUES ` + strings.TrimSuffix(strings.Repeat("(?,?,?,?,?,?,?,?,?,?,NOW()),", len(ds)), ",")
+
+	args := make([]any, 0, cols*len(ds))
+	for _, d := range ds {
+		known := d.Kind != usecase.DiscrepancyMissing
+		args = append(args,
+			d.RunID, d.OrderID, d.Kind, d.Action,
+			d.LocalStatus, nullIfEmpty(d.GatewayStatus),
+			d.LocalAmountCents, sql.NullInt64{Int64: d.GatewayAmountCents, Valid: known},
+			d.LocalCurrency, nullIfEmpty(d.GatewayCurrency),
+		)
+	}
+	_, err := r.db.ExecContext(ctx, q, args...)
+	return err
+}
+
+var _ usecase.DiscrepancyRepo = (*MySQLDiscrepancyRepo)(nil)
@@ -0,0 +1,152 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aq2208/gorder-api/internal/usecase"
+	"github.com/google/uuid"
+)
+
+// MySQLJobRepo stores bulk jobs in the jobs table. Claims expire at a time computed by MySQL, as for
+// webhook deliveries, so runners on different hosts agree on it.
+type MySQLJobRepo struct{ db *sql.DB }
+
+func NewMySQLJobRepo(db *sql.DB) *MySQLJobRepo { return &MySQLJobRepo{db: db} }
+
+// jobFilter is OrderFilter as stored in jobs.filter.
+type jobFilter struct {
+	UserID      string    `json:"user_id,omitempty"`
+	TenantID    string    `json:"tenant_id,omitempty"`
+	Status      string    `json:"status,omitempty"`
+	CreatedFrom time.Time `json:"created_from,omitzero"`
+	CreatedTo   time.Time `json:"created_to,omitzero"`
+}
+
+func (r *MySQLJobRepo) CreateJob(ctx context.Context, j *usecase.Job) error {
+	filter, err := json.Marshal(jobFilter(j.Filter))
+	if err != nil {
+		return err
+	}
+	perms := make([]string, 0, len(j.Owner.Perms))
+	for p := range j.Owner.Perms {
+		perms = append(perms, p)
+	}
+	slices.Sort(perms)
+
+	j.CreatedAt = time.Now()
+	j.UpdatedAt = j.CreatedAt
+	_, err = r.db.ExecContext(ctx, `
+INSERT INTO jobs (id,kind,format,status,client_id,subject,tenant_id,perms,filter,created_at,updated_at)
+VALUES (?,?,?,?,?,?,?,?,?,NOW(3),NOW(3))`,
+		j.ID, j.Kind, j.Format, j.Status, j.Owner.ClientID, nullIfEmpty(j.Owner.Subject), nullIfEmpty(j.Owner.Tenant),
+		strings.Join(perms, ","), filter)
+	return err
+}
+
+func (r *MySQLJobRepo) GetJob(ctx context.Context, clientID, id string) (*usecase.Job, error) {
+	jobs, err := r.queryJobs(ctx, `
+SELECT `+jobColumns+`
+FROM jobs WHERE id=? AND client_id=?`, id, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, usecase.ErrJobNotFound
+	}
+	return &jobs[0], nil
+}
+
+func (r *MySQLJobRepo) ListJobs(ctx context.Context, clientID string, limit int) ([]usecase.Job, error) {
+	return r.queryJobs(ctx, `
+SELECT `+jobColumns+`
+FROM jobs WHERE client_id=?
+ORDER BY created_at DESC, id
+LIMIT ?`, clientID, limit)
+}
+
+// ClaimJob tags one claimable job with a fresh claim token in one UPDATE, then reads it back.
+func (r *MySQLJobRepo) ClaimJob(ctx context.Context, lease time.Duration) (*usecase.Job, error) {
+	token := uuid.NewString()
+	res, err := r.db.ExecContext(ctx, `
+UPDATE jobs
+SET status = 'running', claim_token = ?, claimed_until = NOW(3) + INTERVAL ? MICROSECOND,
+    started_at = COALESCE(started_at, NOW(3)), updated_at = NOW(3)
+WHERE status = 'queued' OR (status = 'running' AND claimed_until < NOW(3))
+ORDER BY created_at
+LIMIT 1`, token, lease.Microseconds())
+	if ok, err := affected(res, err); err != nil || !ok {
+		return nil, err
+	}
+	jobs, err := r.queryJobs(ctx, `
+SELECT `+jobColumns+`
+FROM jobs WHERE claim_token=?`, token)
+	if err != nil || len(jobs) == 0 {
+		return nil, err
+	}
+	return &jobs[0], nil
+}
+
+func (r *MySQLJobRepo) SaveJob(ctx context.Context, j *usecase.Job, lease time.Duration) error {
+	var finishedAt sql.NullTime
+	if !j.FinishedAt.IsZero() {
+		finishedAt = sql.NullTime{Time: j.FinishedAt, Valid: true}
+	}
+	j.UpdatedAt = time.Now()
+	_, err := r.db.ExecContext(ctx, `
+UPDATE jobs
+SET status = ?, total = ?, processed = ?, succeeded = ?, failed = ?, error = ?, finished_at = ?,
+    claimed_until = NOW(3) + INTERVAL ? MICROSECOND, updated_at = NOW(3)
+WHERE id = ?`,
+		j.Status, j.Total, j.Processed, j.Succeeded, j.Failed, nullIfEmpty(truncate(j.Error, 512)), finishedAt,
+		lease.Microseconds(), j.ID)
+	return err
+}
+
+const jobColumns = `id,kind,format,status,client_id,subject,tenant_id,perms,filter,` +
+	`total,processed,succeeded,failed,error,created_at,updated_at,started_at,finished_at`
+
+func (r *MySQLJobRepo) queryJobs(ctx context.Context, q string, args ...any) ([]usecase.Job, error) {
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []usecase.Job
+	for rows.Next() {
+		var j usecase.Job
+		var subject, tenant, msg sql.NullString
+		var perms string
+		var filter []byte
+		var startedAt, finishedAt sql.NullTime
+		if err := rows.Scan(&j.ID, &j.Kind, &j.Format, &j.Status, &j.Owner.ClientID, &subject, &tenant, &perms, &filter,
+			&j.Total, &j.Processed, &j.Succeeded, &j.Failed, &msg, &j.CreatedAt, &j.UpdatedAt, &startedAt, &finishedAt); err != nil {
+			return nil, err
+		}
+		j.Owner.Subject, j.Owner.Tenant, j.Error = subject.String, tenant.String, msg.String
+		j.StartedAt, j.FinishedAt = startedAt.Time, finishedAt.Time
+		j.Owner.Perms = map[string]struct{}{}
+		for _, p := range strings.Split(perms, ",") {
+			if p != "" {
+				j.Owner.Perms[p] = struct{}{}
+			}
+		}
+		if len(filter) > 0 {
+			var f jobFilter
+			if err := json.Unmarshal(filter, &f); err != nil {
+				return nil, fmt.Errorf("jobs.filter: %w", err)
+			}
+			j.Filter = usecase.OrderFilter(f)
+		}
+		out = append(out, j)
+	}
+	return out, rows.Err()
+}
+
+var _ usecase.JobRepo = (*MySQLJobRepo)(nil)
@@ -4,85 +4,257 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/aq2208/gorder-api/internal/usecase"
+	"github.com/go-sql-driver/mysql"
 )
 
 type MySQLOrderRepo struct{ db *sql.DB }
 
-func (r *MySQLOrderRepo) UpdateStatusIf(ctx context.Context, id string, fromStatus, toStatus string) (bool, error) {
+// UpdateStatus is a compare-and-swap on version: zero rows affected means the order moved on (or
+// does not exist).
+func (r *MySQLOrderRepo) UpdateStatus(ctx context.Context, id string, version int64, toStatus string) (bool, error) {
 	res, err := r.db.ExecContext(ctx, `
-        UPDATE orders 
-        SET status = ?, updated_at = NOW()
-        WHERE id = ? AND status = ?`,
-		toStatus, id, fromStatus,
+        UPDATE orders
+        SET status = ?, version = version + 1, updated_at = NOW()
+        WHERE id = ? AND version = ?`,
+		toStatus, id, version,
 	)
-	if err != nil {
-		return false, err
-	}
+	return affected(res, err)
+}
 
-	rows, err := res.RowsAffected()
-	if err != nil {
-		return false, err
+func NewMySQLOrderRepo(db *sql.DB) *MySQLOrderRepo { return &MySQLOrderRepo{db: db} }
+
+func (r *MySQLOrderRepo) Create(ctx context.Context, o *usecase.OrderRecord) error {
+	_, err := r.db.ExecContext(ctx, `
+INSERT INTO orders (id,user_id,tenant_id,client_id,status,amount_cents,currency,items_json,idempotency_key,version,created_at,updated_at)
+VALUES (?,?,?,?,?,?,?,?,?,0,NOW(),NOW())
+`, o.ID, o.UserID, nullIfEmpty(o.TenantID), nullIfEmpty(o.ClientID), o.Status, o.AmountCents, o.Currency, o.ItemsJSON, nullIfEmpty(o.IdempotencyKey))
+	return duplicateIdemKey(err)
+}
+
+// CreateBatch inserts all records with one multi-row INSERT, which InnoDB applies atomically.
+func (r *MySQLOrderRepo) CreateBatch(ctx context.Context, recs []*usecase.OrderRecord) error {
+	if len(recs) == 0 {
+		return nil
+	}
+	var q strings.Builder
+	q.WriteString(`
+INSERT INTO orders (id,user_id,tenant_id,client_id,status,amount_cents,currency,items_json,idempotency_key,version,created_at,updated_at)
+VALUES `)
+	args := make([]any, 0, len(recs)*9)
+	for i, o := range recs {
+		if i > 0 {
+			q.WriteString(",")
+		}
+		q.WriteString("(?,?,?,?,?,?,?,?,?,0,NOW(),NOW())")
+		args = append(args, o.ID, o.UserID, nullIfEmpty(o.TenantID), nullIfEmpty(o.ClientID), o.Status, o.AmountCents,
+			o.Currency, o.ItemsJSON, nullIfEmpty(o.IdempotencyKey))
 	}
+	_, err := r.db.ExecContext(ctx, q.String(), args...)
+	return duplicateIdemKey(err)
+}
 
-	// rows == 0 → nothing matched (either not found or status mismatch)
-	return rows > 0, nil
+func (r *MySQLOrderRepo) GetByID(ctx context.Context, id string) (*usecase.OrderRecord, error) {
+	row := r.db.QueryRowContext(ctx, `
+SELECT `+orderColumns+`
+FROM orders WHERE id=?`, id)
+	return scanOrder(row)
 }
 
-func (r *MySQLOrderRepo) UpdateStatus(ctx context.Context, id, toStatus string) error {
+func (r *MySQLOrderRepo) GetByIdemKey(ctx context.Context, clientID, userID, idemKey string) (*usecase.OrderRecord, error) {
+	row := r.db.QueryRowContext(ctx, `
+SELECT `+orderColumns+`
+FROM orders WHERE user_id=? AND idempotency_key=? AND client_id=?`, userID, idemKey, clientID)
+	return scanOrder(row)
+}
+
+// ListStuck uses the (status, updated_at) index; the cutoff is computed by MySQL so app and DB clocks
+// or time zones cannot disagree.
+func (r *MySQLOrderRepo) ListStuck(ctx context.Context, status string, olderThan time.Duration, limit int) ([]usecase.OrderRecord, error) {
+	return r.query(ctx, `
+SELECT `+orderColumns+`
+FROM orders
+WHERE status=? AND updated_at < NOW() - INTERVAL ? SECOND
+ORDER BY updated_at
+LIMIT ?`, status, int64(olderThan/time.Second), limit)
+}
+
+func (r *MySQLOrderRepo) MarkRedispatched(ctx context.Context, id string, version int64) (bool, error) {
 	res, err := r.db.ExecContext(ctx, `
-        UPDATE orders 
-        SET status = ?, updated_at = NOW()
-        WHERE id = ?`,
-		toStatus, id,
+        UPDATE orders
+        SET dispatch_attempts = dispatch_attempts + 1, version = version + 1, updated_at = NOW()
+        WHERE id = ? AND status = 'PROCESSING' AND version = ?`,
+		id, version,
 	)
+	return affected(res, err)
+}
+
+func (r *MySQLOrderRepo) FailStuck(ctx context.Context, id string, version int64, reason string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+        UPDATE orders
+        SET status = 'FAILED', failure_reason = ?, version = version + 1, updated_at = NOW()
+        WHERE id = ? AND status = 'PROCESSING' AND version = ?`,
+		reason, id, version,
+	)
+	return affected(res, err)
+}
+
+// ListRecent pages by (created_at, id) on idx_orders_created_id.
+func (r *MySQLOrderRepo) ListRecent(ctx context.Context, window, settle time.Duration, after usecase.OrderCursor, limit int) ([]usecase.OrderRecord, error) {
+	q := `
+SELECT ` + orderColumns + `
+FROM orders
+WHERE created_at >= NOW() - INTERVAL ? SECOND
+  AND updated_at < NOW() - INTERVAL ? SECOND`
+	args := []any{int64(window / time.Second), int64(settle / time.Second)}
+	if after.ID != "" {
+		q += `
+  AND (created_at > ? OR (created_at = ? AND id > ?))`
+		args = append(args, after.CreatedAt, after.CreatedAt, after.ID)
+	}
+	q += `
+ORDER BY created_at, id
+LIMIT ?`
+	args = append(args, limit)
+	return r.query(ctx, q, args...)
+}
+
+// ListByUser pages newest first on idx_orders_user_created.
+func (r *MySQLOrderRepo) ListByUser(ctx context.Context, userID, status string, before usecase.OrderCursor, limit int) ([]usecase.OrderRecord, error) {
+	q := `
+SELECT ` + orderColumns + `
+FROM orders
+WHERE user_id = ?`
+	args := []any{userID}
+	if status != "" {
+		q += ` AND status = ?`
+		args = append(args, status)
+	}
+	if before.ID != "" {
+		q += `
+  AND (created_at < ? OR (created_at = ? AND id < ?))`
+		args = append(args, before.CreatedAt, before.CreatedAt, before.ID)
+	}
+	q += `
+ORDER BY created_at DESC, id DESC
+LIMIT ?`
+	args = append(args, limit)
+	return r.query(ctx, q, args...)
+}
+
+// ExportOrders pages forward on idx_orders_user_created when the user is set, idx_orders_created_id
+// otherwise.
+func (r *MySQLOrderRepo) ExportOrders(ctx context.Context, f usecase.OrderFilter, after usecase.OrderCursor, limit int) ([]usecase.OrderRecord, error) {
+	q := `
+SELECT ` + orderColumns + `
+FROM orders
+WHERE 1=1`
+	var args []any
+	cond := func(c string, v any) {
+		q += `
+  AND ` + c
+		args = append(args, v)
+	}
+	if f.UserID != "" {
+		cond(`user_id = ?`, f.UserID)
+	}
+	if f.TenantID != "" {
+		cond(`tenant_id = ?`, f.TenantID)
+	}
+	if f.Status != "" {
+		cond(`status = ?`, f.Status)
+	}
+	if !f.CreatedFrom.IsZero() {
+		cond(`created_at >= ?`, f.CreatedFrom)
+	}
+	if !f.CreatedTo.IsZero() {
+		cond(`created_at < ?`, f.CreatedTo)
+	}
+	if after.ID != "" {
+		q += `
+  AND (created_at > ? OR (created_at = ? AND id > ?))`
+		args = append(args, after.CreatedAt, after.CreatedAt, after.ID)
+	}
+	q += `
+ORDER BY created_at, id
+LIMIT ?`
+	args = append(args, limit)
+	return r.query(ctx, q, args...)
+}
+
+func (r *MySQLOrderRepo) query(ctx context.Context, q string, args ...any) ([]usecase.OrderRecord, error) {
+	rows, err := r.db.QueryContext(ctx, q, args...)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer rows.Close()
 
-	rows, err := res.RowsAffected()
+	var out []usecase.OrderRecord
+	for rows.Next() {
+		rec, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *rec)
+	}
+	return out, rows.Err()
+}
+
+func affected(res sql.Result, err error) (bool, error) {
 	if err != nil {
-		return err
+		return false, err
 	}
-	if rows == 0 {
-		return ErrNotFound
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
 	}
-	return nil
+	return rows > 0, nil
 }
 
-func NewMySQLOrderRepo(db *sql.DB) *MySQLOrderRepo { return &MySQLOrderRepo{db: db} }
+const orderColumns = `id,user_id,tenant_id,client_id,status,amount_cents,currency,items_json,idempotency_key,` +
+	`dispatch_attempts,failure_reason,version,created_at,updated_at`
 
-func (r *MySQLOrderRepo) Create(ctx context.Context, o *usecase.OrderRecord) error {
-	_, err := r.db.ExecContext(ctx, `
-INSERT INTO orders (id,user_id,status,amount_cents,currency,items_json,idempotency_key,version,created_at,updated_at)
-VALUES (?,?,?,?,?,?,?,0,NOW(),NOW())
-`, o.ID, o.UserID, o.Status, o.AmountCents, o.Currency, o.ItemsJSON, o.IdempotencyKey)
-	return err
+type rowScanner interface {
+	Scan(dest ...any) error
 }
 
-func (r *MySQLOrderRepo) GetByID(ctx context.Context, id string) (*usecase.OrderRecord, error) {
-	row := r.db.QueryRowContext(ctx, `
-SELECT id,user_id,status,amount_cents,currency,items_json,idempotency_key
-FROM orders WHERE id=?`, id)
+func scanOrder(row rowScanner) (*usecase.OrderRecord, error) {
 	var rec usecase.OrderRecord
-	if err := row.Scan(&rec.ID, &rec.UserID, &rec.Status, &rec.AmountCents, &rec.Currency, &rec.ItemsJSON); err != nil {
+	var tenantID, clientID, idemKey, reason sql.NullString
+	if err := row.Scan(&rec.ID, &rec.UserID, &tenantID, &clientID, &rec.Status, &rec.AmountCents, &rec.Currency, &rec.ItemsJSON, &idemKey,
+		&rec.DispatchAttempts, &reason, &rec.Version, &rec.CreatedAt, &rec.UpdatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, usecase.ErrNotFound
+		}
 		return nil, err
 	}
+	rec.TenantID = tenantID.String
+	rec.ClientID = clientID.String
+	rec.IdempotencyKey = idemKey.String
+	rec.FailureReason = reason.String
 	return &rec, nil
 }
 
-func (r *MySQLOrderRepo) GetByUserAndIdemKey(ctx context.Context, userID, idemKey string) (*usecase.OrderRecord, error) {
-	row := r.db.QueryRowContext(ctx, `
-SELECT id,user_id,status,amount_cents,currency,items_json,idempotency_key
-FROM orders WHERE user_id=? AND idempotency_key=?`, userID, idemKey)
-	var rec usecase.OrderRecord
-	if err := row.Scan(&rec.ID, &rec.UserID, &rec.Status, &rec.AmountCents, &rec.Currency, &rec.ItemsJSON); err != nil {
-		return nil, err
+// duplicateIdemKey maps a violation of uk_orders_client_user_idem to usecase.ErrDuplicate.
+func duplicateIdemKey(err error) error {
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) && myErr.Number == 1062 && strings.Contains(myErr.Message, "uk_orders_client_user_idem") {
+		return usecase.ErrDuplicate
 	}
-	return &rec, nil
+	return err
 }
 
-var _ usecase.OrderRepo = (*MySQLOrderRepo)(nil)
+func nullIfEmpty(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
 
-var ErrNotFound = errors.New("not found")
+var (
+	_ usecase.OrderRepo      = (*MySQLOrderRepo)(nil)
+	_ usecase.StuckOrderRepo = (*MySQLOrderRepo)(nil)
+	_ usecase.ReconcileRepo  = (*MySQLOrderRepo)(nil)
+	_ usecase.OrderListRepo  = (*MySQLOrderRepo)(nil)
+	_ usecase.OrderBatchRepo = (*MySQLOrderRepo)(nil)
+)
@@ -0,0 +1,242 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/aq2208/gorder-api/internal/usecase"
+	"github.com/google/uuid"
+)
+
+// MySQLWebhookRepo stores webhook subscriptions, the delivery queue (webhook_deliveries) and the
+// attempt log. Retry times are computed by MySQL from a delay so the app and DB clocks cannot disagree.
+type MySQLWebhookRepo struct{ db *sql.DB }
+
+func NewMySQLWebhookRepo(db *sql.DB) *MySQLWebhookRepo { return &MySQLWebhookRepo{db: db} }
+
+func (r *MySQLWebhookRepo) CreateSubscription(ctx context.Context, s *usecase.WebhookSubscription) error {
+	s.CreatedAt = time.Now()
+	_, err := r.db.ExecContext(ctx, `
+INSERT INTO webhook_subscriptions (id,client_id,url,secret,events,created_at)
+VALUES (?,?,?,?,?,NOW())`, s.ID, s.ClientID, s.URL, s.Secret, strings.Join(s.Events, ","))
+	return err
+}
+
+const subscriptionColumns = `id,client_id,url,secret,events,created_at`
+
+func (r *MySQLWebhookRepo) ListSubscriptions(ctx context.Context, clientID string) ([]usecase.WebhookSubscription, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+subscriptionColumns+`
+FROM webhook_subscriptions WHERE client_id=? ORDER BY created_at, id`, clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []usecase.WebhookSubscription
+	for rows.Next() {
+		s, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *s)
+	}
+	return out, rows.Err()
+}
+
+func (r *MySQLWebhookRepo) GetSubscription(ctx context.Context, id string) (*usecase.WebhookSubscription, error) {
+	row := r.db.QueryRowContext(ctx, `
+SELECT `+subscriptionColumns+`
+FROM webhook_subscriptions WHERE id=?`, id)
+	return scanSubscription(row)
+}
+
+func (r *MySQLWebhookRepo) DeleteSubscription(ctx context.Context, clientID, id string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id=? AND client_id=?`, id, clientID)
+	return affected(res, err)
+}
+
+func scanSubscription(row rowScanner) (*usecase.WebhookSubscription, error) {
+	var s usecase.WebhookSubscription
+	var events string
+	if err := row.Scan(&s.ID, &s.ClientID, &s.URL, &s.Secret, &events, &s.CreatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, usecase.ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	s.Events = strings.Split(events, ",")
+	return &s, nil
+}
+
+// EnqueueDeliveries relies on uk_deliveries_sub_event to skip an event already queued.
+func (r *MySQLWebhookRepo) EnqueueDeliveries(ctx context.Context, ds []usecase.WebhookDelivery) error {
+	if len(ds) == 0 {
+		return nil
+	}
+	const cols = 8
+	q := `
+INSERT IGNORE INTO webhook_deliveries
+  (id,subscription_id,client_id,event_id,event_type,order_id,payload,status,attempts,next_attempt_at,created_at,updated_at)
+VALUES ` + strings.TrimSuffix(strings.Repeat("(?,?,?,?,?,?,?,?,0,NOW(3),NOW(3),NOW(3)),", len(ds)), ",")
+
+	args := make([]any, 0, cols*len(ds))
+	for _, d := range ds {
+		args = append(args, d.ID, d.SubscriptionID, d.ClientID, d.EventID, d.EventType, d.OrderID, d.Payload, d.Status)
+	}
+	_, err := r.db.ExecContext(ctx, q, args...)
+	return err
+}
+
+// ClaimDue marks a batch with a fresh claim token in one UPDATE (idx_deliveries_due), then reads it
+// back; concurrent workers never claim the same row.
+func (r *MySQLWebhookRepo) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]usecase.WebhookDelivery, error) {
+	token := uuid.NewString()
+	_, err := r.db.ExecContext(ctx, `
+UPDATE webhook_deliveries
+SET claim_token = ?, next_attempt_at = NOW(3) + INTERVAL ? SECOND
+WHERE status = 'pending' AND next_attempt_at <= NOW(3)
+ORDER BY next_attempt_at
+LIMIT ?`, token, int64(lease/time.Second), limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.queryDeliveries(ctx, `
+SELECT `+deliveryColumns+`
+FROM webhook_deliveries WHERE claim_token=?`, token)
+}
+
+func (r *MySQLWebhookRepo) RecordAttempt(ctx context.Context, d *usecase.WebhookDelivery, a usecase.WebhookAttempt) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	delay := max(time.Until(d.NextAttemptAt), 0)
+	var deliveredAt sql.NullTime
+	if !d.DeliveredAt.IsZero() {
+		deliveredAt = sql.NullTime{Time: d.DeliveredAt, Valid: true}
+	}
+	if _, err := tx.ExecContext(ctx, `
+UPDATE webhook_deliveries
+SET status = ?, attempts = ?, next_attempt_at = NOW(3) + INTERVAL ? MICROSECOND, claim_token = NULL,
+    last_status_code = ?, last_error = ?, delivered_at = ?, updated_at = NOW(3)
+WHERE id = ?`,
+		d.Status, d.Attempts, delay.Microseconds(),
+		nullIfZero(d.LastStatusCode), nullIfEmpty(truncate(d.LastError, 512)), deliveredAt, d.ID,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+INSERT INTO webhook_delivery_attempts (delivery_id,status_code,error,duration_ms,attempted_at)
+VALUES (?,?,?,?,?)`,
+		a.DeliveryID, nullIfZero(a.StatusCode), nullIfEmpty(truncate(a.Error, 512)), a.Duration.Milliseconds(), a.At,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *MySQLWebhookRepo) GetDelivery(ctx context.Context, clientID, id string) (*usecase.WebhookDelivery, error) {
+	ds, err := r.queryDeliveries(ctx, `
+SELECT `+deliveryColumns+`
+FROM webhook_deliveries WHERE id=? AND client_id=?`, id, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if len(ds) == 0 {
+		return nil, usecase.ErrWebhookNotFound
+	}
+	return &ds[0], nil
+}
+
+func (r *MySQLWebhookRepo) ListDeliveries(ctx context.Context, clientID, status string, limit int) ([]usecase.WebhookDelivery, error) {
+	q := `
+SELECT ` + deliveryColumns + `
+FROM webhook_deliveries WHERE client_id=?`
+	args := []any{clientID}
+	if status != "" {
+		q += ` AND status=?`
+		args = append(args, status)
+	}
+	q += `
+ORDER BY created_at DESC, id
+LIMIT ?`
+	args = append(args, limit)
+	return r.queryDeliveries(ctx, q, args...)
+}
+
+func (r *MySQLWebhookRepo) ListAttempts(ctx context.Context, deliveryID string) ([]usecase.WebhookAttempt, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT delivery_id,status_code,error,duration_ms,attempted_at
+FROM webhook_delivery_attempts WHERE delivery_id=? ORDER BY id`, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []usecase.WebhookAttempt
+	for rows.Next() {
+		var a usecase.WebhookAttempt
+		var code sql.NullInt64
+		var msg sql.NullString
+		var ms int64
+		if err := rows.Scan(&a.DeliveryID, &code, &msg, &ms, &a.At); err != nil {
+			return nil, err
+		}
+		a.StatusCode, a.Error, a.Duration = int(code.Int64), msg.String, time.Duration(ms)*time.Millisecond
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func (r *MySQLWebhookRepo) Redeliver(ctx context.Context, clientID, id string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+UPDATE webhook_deliveries
+SET status = 'pending', attempts = 0, next_attempt_at = NOW(3), claim_token = NULL, updated_at = NOW(3)
+WHERE id = ? AND client_id = ?`, id, clientID)
+	return affected(res, err)
+}
+
+const deliveryColumns = `id,subscription_id,client_id,event_id,event_type,order_id,payload,status,attempts,` +
+	`next_attempt_at,last_status_code,last_error,created_at,updated_at,delivered_at`
+
+func (r *MySQLWebhookRepo) queryDeliveries(ctx context.Context, q string, args ...any) ([]usecase.WebhookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []usecase.WebhookDelivery
+	for rows.Next() {
+		var d usecase.WebhookDelivery
+		var code sql.NullInt64
+		var msg sql.NullString
+		var deliveredAt sql.NullTime
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.ClientID, &d.EventID, &d.EventType, &d.OrderID, &d.Payload,
+			&d.Status, &d.Attempts, &d.NextAttemptAt, &code, &msg, &d.CreatedAt, &d.UpdatedAt, &deliveredAt); err != nil {
+			return nil, err
+		}
+		d.LastStatusCode, d.LastError, d.DeliveredAt = int(code.Int64), msg.String, deliveredAt.Time
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+func nullIfZero(n int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(n), Valid: n != 0}
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
+
+var _ usecase.WebhookRepo = (*MySQLWebhookRepo)(nil)
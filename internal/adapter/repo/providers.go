@@ -0,0 +1,51 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/aq2208/gorder-api/configs"
+	"github.com/aq2208/gorder-api/internal/usecase"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/google/wire"
+)
+
+// ProviderSet provides the MySQL-backed order repositories (OrderRepo, StuckOrderRepo, ReconcileRepo,
+// OrderListRepo, OrderBatchRepo, OrderExportRepo), the reconciliation report, the webhook store and
+// the jobs table.
+var ProviderSet = wire.NewSet(
+	NewDB,
+	NewMySQLOrderRepo,
+	wire.Bind(new(usecase.OrderRepo), new(*MySQLOrderRepo)),
+	wire.Bind(new(usecase.StuckOrderRepo), new(*MySQLOrderRepo)),
+	wire.Bind(new(usecase.ReconcileRepo), new(*MySQLOrderRepo)),
+	wire.Bind(new(usecase.OrderListRepo), new(*MySQLOrderRepo)),
+	wire.Bind(new(usecase.OrderBatchRepo), new(*MySQLOrderRepo)),
+	wire.Bind(new(usecase.OrderExportRepo), new(*MySQLOrderRepo)),
+	NewMySQLDiscrepancyRepo,
+	wire.Bind(new(usecase.DiscrepancyRepo), new(*MySQLDiscrepancyRepo)),
+	NewMySQLWebhookRepo,
+	wire.Bind(new(usecase.WebhookRepo), new(*MySQLWebhookRepo)),
+	NewMySQLJobRepo,
+	wire.Bind(new(usecase.JobRepo), new(*MySQLJobRepo)),
+)
+
+// NewDB opens and pings the pool configured in cfg.MySQL.
+func NewDB(cfg configs.Config) (*sql.DB, func(), error) {
+	db, err := sql.Open("mysql", cfg.MySQL.DSN)
+	if err != nil {
+		return nil, nil, err
+	}
+	db.SetConnMaxLifetime(cfg.MySQL.ConnMaxLifetime)
+	db.SetMaxOpenConns(cfg.MySQL.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MySQL.MaxIdleConns)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, nil, err
+	}
+	return db, func() { _ = db.Close() }, nil
+}
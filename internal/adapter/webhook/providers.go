@@ -0,0 +1,33 @@
+package webhook
+
+import (
+	"github.com/aq2208/gorder-api/configs"
+	"github.com/aq2208/gorder-api/internal/usecase"
+	"github.com/google/wire"
+)
+
+// ProviderSet provides the webhook delivery runner; it needs a WebhookRepo.
+var ProviderSet = wire.NewSet(
+	ProvideHTTPSender,
+	wire.Bind(new(usecase.WebhookSender), new(*HTTPSender)),
+	ProvideDeliverWebhooks,
+	ProvideRunner,
+)
+
+func ProvideHTTPSender(cfg configs.Config) *HTTPSender {
+	return NewHTTPSender(cfg.Webhooks.Timeout, cfg.Webhooks.AllowPrivate)
+}
+
+func ProvideDeliverWebhooks(cfg configs.Config, repo usecase.WebhookRepo, sender usecase.WebhookSender) *usecase.DeliverWebhooks {
+	wc := cfg.Webhooks
+	return usecase.NewDeliverWebhooks(repo, sender,
+		usecase.WithDeliveryBatch(wc.BatchSize),
+		usecase.WithDeliveryAttempts(wc.MaxAttempts),
+		usecase.WithBackoff(wc.BackoffBase, wc.BackoffMax),
+		usecase.WithClaimTTL(wc.ClaimTTL),
+	)
+}
+
+func ProvideRunner(cfg configs.Config, uc *usecase.DeliverWebhooks) *Runner {
+	return NewRunner(uc, cfg.Webhooks.Interval, cfg.Webhooks.BatchSize, cfg.Webhooks.Enabled)
+}
@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"github.com/aq2208/gorder-api/internal/logging"
+	"github.com/aq2208/gorder-api/internal/usecase"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var webhookDeliveries = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "webhook_deliveries_total",
+		Help: "Webhook delivery attempts by outcome",
+	},
+	[]string{"result"}, // delivered | retried | failed
+)
+
+// Runner sends due webhook deliveries every interval. Deliveries are claimed in the database, so every
+// worker replica runs one and no lease is needed. A full batch is followed by another at once.
+type Runner struct {
+	uc       *usecase.DeliverWebhooks
+	interval time.Duration
+	batch    int
+	enabled  bool
+}
+
+func NewRunner(uc *usecase.DeliverWebhooks, interval time.Duration, batch int, enabled bool) *Runner {
+	return &Runner{uc: uc, interval: interval, batch: batch, enabled: enabled}
+}
+
+// Run blocks until ctx is cancelled.
+func (r *Runner) Run(ctx context.Context) {
+	l := logging.New("webhooks")
+	if !r.enabled {
+		l.Info("webhook delivery disabled")
+		return
+	}
+
+	t := time.NewTicker(r.interval)
+	defer t.Stop()
+	for {
+		res, err := r.uc.Execute(ctx)
+		if err != nil {
+			l.Warn("webhook claim failed", "err", err)
+		}
+		webhookDeliveries.WithLabelValues("delivered").Add(float64(res.Delivered))
+		webhookDeliveries.WithLabelValues("retried").Add(float64(res.Retried))
+		webhookDeliveries.WithLabelValues("failed").Add(float64(res.Failed))
+		if res.Failed > 0 {
+			l.Warn("webhook deliveries failed", "count", res.Failed)
+		}
+		if err == nil && res.Delivered+res.Retried+res.Failed >= r.batch {
+			if ctx.Err() != nil {
+				return
+			}
+			continue // backlog: keep going
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/aq2208/gorder-api/internal/usecase"
+)
+
+// Headers sent with every delivery. Receivers verify X-Webhook-Signature and deduplicate on X-Webhook-Id.
+const (
+	HeaderID        = "X-Webhook-Id" // event id: the same for retries and redeliveries
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp" // unix seconds, part of the signed content
+	HeaderSignature = "X-Webhook-Signature" // "v1=" + hex HMAC-SHA256(secret, timestamp + "." + body)
+)
+
+// Sign returns the X-Webhook-Signature value for body sent at ts.
+func Sign(secret string, ts int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(ts, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature made by Sign in constant time; receivers should also reject stale timestamps.
+func Verify(secret string, ts int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, ts, body)), []byte(signature))
+}
+
+// ErrBlockedAddress is returned for a delivery whose host resolves to a loopback, private, link-local
+// or otherwise internal address.
+var ErrBlockedAddress = errors.New("webhook host resolves to a non-public address")
+
+// HTTPSender POSTs deliveries with a per-request timeout. Redirects are not followed: a subscriber
+// must register the final URL. Unless allowPrivate is set, connections to internal addresses are
+// refused after DNS resolution, so a subscriber cannot point a name at 127.0.0.1 or 169.254.169.254.
+type HTTPSender struct {
+	client *http.Client
+}
+
+func NewHTTPSender(timeout time.Duration, allowPrivate bool) *HTTPSender {
+	dialer := &net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}
+	if !allowPrivate {
+		dialer.Control = refuseInternal
+	}
+	return &HTTPSender{client: &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:                 nil, // a proxy would dial on our behalf, past the address check
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}}
+}
+
+// cgnat is the shared address space (RFC 6598), used inside some cloud networks.
+var cgnat = netip.MustParsePrefix("100.64.0.0/10")
+
+// refuseInternal is a net.Dialer Control hook: it sees the resolved address of every connection.
+func refuseInternal(network, address string, _ syscall.RawConn) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+	}
+	ip := ap.Addr().Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() ||
+		cgnat.Contains(ip) || (ip.Is4() && ip.As4()[0] == 0) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, ip)
+	}
+	return nil
+}
+
+func (s *HTTPSender) Send(ctx context.Context, url, secret string, d usecase.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	ts := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gorder-api-webhooks/1")
+	req.Header.Set(HeaderID, d.EventID)
+	req.Header.Set(HeaderEvent, d.EventType)
+	req.Header.Set(HeaderDelivery, d.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderSignature, Sign(secret, ts, d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // let the connection be reused
+	return resp.StatusCode, nil
+}
+
+var _ usecase.WebhookSender = (*HTTPSender)(nil)
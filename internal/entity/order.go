@@ -7,8 +7,14 @@ const (
 	StatusProcessing Status = "PROCESSING"
 	StatusConfirmed  Status = "CONFIRMED"
 	StatusFailed     Status = "FAILED"
+	StatusCancelled  Status = "CANCELLED" // by the client while PROCESSING; a later order-gw outcome still wins
 )
 
+// IsFinal reports whether no further status change is expected.
+func (s Status) IsFinal() bool {
+	return s == StatusConfirmed || s == StatusFailed || s == StatusCancelled
+}
+
 type Money struct {
 	Cents    int64
 	Currency string
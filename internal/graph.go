@@ -0,0 +1,85 @@
+package internal
+
+import (
+	"context"
+
+	"github.com/aq2208/gorder-api/internal/adapter/cache"
+	"github.com/aq2208/gorder-api/internal/adapter/grpcapi"
+	"github.com/aq2208/gorder-api/internal/adapter/http/middleware"
+	"github.com/aq2208/gorder-api/internal/adapter/jobs"
+	"github.com/aq2208/gorder-api/internal/adapter/kafka"
+	"github.com/aq2208/gorder-api/internal/adapter/memory"
+	"github.com/aq2208/gorder-api/internal/adapter/queue"
+	"github.com/aq2208/gorder-api/internal/adapter/reaper"
+	"github.com/aq2208/gorder-api/internal/adapter/webhook"
+	"github.com/aq2208/gorder-api/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// The process graphs built by the injectors in wire.go. Besides the entry point each one exposes the
+// components that accept live config changes (see app.Reloadable).
+
+// API is the HTTP and gRPC API: MySQL, Redis, the Rabbit producer and the request crypto. It also
+// runs the bulk import/export jobs.
+type API struct {
+	Router    *gin.Engine
+	GRPC      *grpcapi.Server
+	Jobs      *jobs.Runner
+	Cache     *cache.RedisCache
+	Idem      *cache.RedisIdempotencyStore
+	RateLimit *middleware.RateLimiter
+}
+
+// OrderWorker consumes order.created commands from Rabbit and submits them to order-gw, and runs the
+// stuck-order reaper (MySQL + a Redis lease, so only one replica scans) and the webhook delivery loop.
+type OrderWorker struct {
+	Router   *queue.Router
+	Reaper   *reaper.Runner
+	Webhooks *webhook.Runner
+}
+
+// StatusConsumer applies order status changes from Kafka to MySQL and the cache.
+type StatusConsumer struct {
+	Consumer *kafka.Consumer
+	Cache    *cache.RedisCache
+}
+
+// Reconciler is the one-shot `order-api reconcile` job: MySQL, Redis and the order-gw client.
+type Reconciler struct {
+	Job *usecase.ReconcileOrders
+}
+
+// Combined runs all three in one process, sharing connections.
+type Combined struct {
+	API    *API
+	Worker *OrderWorker
+	Status *StatusConsumer
+}
+
+// LocalMem is the whole create -> dispatch -> confirm flow in one process on in-memory adapters
+// (APP_ENV=local-mem): no MySQL, Redis, RabbitMQ, Kafka or order-gw needed.
+type LocalMem struct {
+	Router        *gin.Engine
+	GRPC          *grpcapi.Server
+	Cache         *memory.Cache
+	Idem          *memory.IdempotencyStore
+	RateLimit     *middleware.RateLimiter
+	Commands      *memory.CommandBus
+	Statuses      *memory.StatusBus
+	Gateway       *memory.Gateway
+	Worker        *queue.OrderCreatedHandler
+	StatusHandler *kafka.OrderStatusChangedHandler
+	Reaper        *reaper.Runner
+	Webhooks      *webhook.Runner
+	Jobs          *jobs.Runner
+}
+
+// Start runs the in-memory worker, status consumer, reaper, webhook delivery and job runner until ctx
+// is cancelled.
+func (m *LocalMem) Start(ctx context.Context) {
+	go m.Commands.Run(ctx, m.Worker.HandleCreate)
+	go m.Statuses.Run(ctx, m.StatusHandler.Handle)
+	go m.Reaper.Run(ctx)
+	go m.Webhooks.Run(ctx)
+	go m.Jobs.Run(ctx)
+}
@@ -0,0 +1,48 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aq2208/gorder-api/configs"
+)
+
+func localMemConfig(t *testing.T) configs.Config {
+	t.Helper()
+	cfg, err := configs.Load("../configs", "local-mem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Jobs.Dir = t.TempDir()
+	return cfg
+}
+
+// The local-mem graph needs no infrastructure, so it is the one injector that can be built here.
+func TestInitializeLocalMem(t *testing.T) {
+	m, cleanup, err := InitializeLocalMem(localMemConfig(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	v := reflect.ValueOf(m).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if v.Field(i).IsNil() {
+			t.Errorf("LocalMem.%s is not wired", v.Type().Field(i).Name)
+		}
+	}
+}
+
+func TestInitializeLocalMemReportsProviderErrors(t *testing.T) {
+	cfg := localMemConfig(t)
+	cfg.CryptoConfig.ServerKeyFromLegacy = false // LoadServerSigner now has no key
+
+	m, cleanup, err := InitializeLocalMem(cfg)
+	if err == nil {
+		cleanup()
+		t.Fatal("InitializeLocalMem succeeded without a server key")
+	}
+	if m != nil || cleanup != nil {
+		t.Fatal("failed injector returned a partial graph")
+	}
+}
@@ -14,8 +14,9 @@ import (
 type ctxKey struct{}
 
 var (
-	once sync.Once
-	base *slog.Logger
+	once  sync.Once
+	base  *slog.Logger
+	level = new(slog.LevelVar) // adjustable at runtime via SetLevel
 )
 
 // Init configures the global logger exactly once.
@@ -33,12 +34,25 @@ func Init(component, filePath string) *slog.Logger {
 		}
 		mw := io.MultiWriter(os.Stdout, rot)
 
-		h := slog.NewJSONHandler(mw, &slog.HandlerOptions{Level: slog.LevelInfo})
+		h := slog.NewJSONHandler(mw, &slog.HandlerOptions{Level: level})
 		base = slog.New(h).With("component", component)
 	})
 	return base
 }
 
+// SetLevel changes the level of the global logger (debug|info|warn|error); safe to call at any time.
+func SetLevel(name string) error {
+	if name == "" {
+		name = "info"
+	}
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(name)); err != nil {
+		return err
+	}
+	level.Set(l)
+	return nil
+}
+
 // Base returns the global logger (Init if not already called).
 func Base() *slog.Logger {
 	if base == nil {
@@ -0,0 +1,39 @@
+package internal
+
+import (
+	"github.com/aq2208/gorder-api/configs"
+	"github.com/aq2208/gorder-api/internal/usecase"
+)
+
+func provideReconcileOrders(cfg configs.Config, repo usecase.ReconcileRepo, orders usecase.OrderRepo, cache usecase.OrderCache,
+	gw usecase.OrderGatewayReader, reports usecase.DiscrepancyRepo, events usecase.OrderEventPublisher,
+	webhooks *usecase.NotifyWebhooks) *usecase.ReconcileOrders {
+	rc := cfg.Reconcile
+	return usecase.NewReconcileOrders(repo, orders, cache, gw, reports,
+		usecase.WithWindow(rc.Window),
+		usecase.WithSettle(rc.Settle),
+		usecase.WithPageSize(rc.PageSize),
+		usecase.WithAutoCorrect(rc.AutoCorrect),
+		usecase.WithReconcileEvents(events),
+		usecase.WithReconcileWebhooks(webhooks),
+	)
+}
+
+func provideCreateOrderBatch(cfg configs.Config, repo usecase.OrderBatchRepo, cache usecase.OrderCache,
+	idem usecase.IdempotencyStore, queue usecase.OrderBatchQueue) *usecase.CreateOrderBatch {
+	return usecase.NewCreateOrderBatch(repo, cache, idem, queue,
+		usecase.WithBatchMaxItems(cfg.Batch.MaxItems),
+		usecase.WithBatchMode(cfg.Batch.Mode),
+	)
+}
+
+func provideManageWebhooks(cfg configs.Config, repo usecase.WebhookRepo) *usecase.ManageWebhooks {
+	return usecase.NewManageWebhooks(repo,
+		usecase.WithInsecureWebhookURLs(cfg.Webhooks.AllowHTTP),
+		usecase.WithPrivateWebhookHosts(cfg.Webhooks.AllowPrivate),
+	)
+}
+
+func provideManageJobs(cfg configs.Config, repo usecase.JobRepo, files usecase.JobFiles) *usecase.ManageJobs {
+	return usecase.NewManageJobs(repo, files, usecase.WithMaxUpload(cfg.Jobs.MaxUploadBytes))
+}
@@ -1,15 +1,34 @@
 package security
 
+import "crypto/x509"
+
 // In-memory client registry (replace with DB/config later)
 type Client struct {
 	ID      string
 	Secret  string
 	Perms   []string // e.g. {"orders.read","orders.write"}
 	Enabled bool
+	UserID  string // optional: binds tokens to one end user (emitted as "sub")
+	Tenant  string // optional: tenant the client belongs to
+
+	EncryptResponses bool   // always wrap responses in the encrypted + signed envelope
+	CertSubject      string // optional: mTLS certificate CN (or full subject DN) identifying this client
 }
 
 var Clients = map[string]Client{
 	"simulated-client": {ID: "simulated-client", Secret: "simulated-client-secret", Perms: []string{"orders.read", "orders.write"}, Enabled: true},
-	"svc-order-gw":     {ID: "svc-order-gw", Secret: "gw-secret", Perms: []string{"orders.read", "orders.write"}, Enabled: true},
+	"svc-order-gw":     {ID: "svc-order-gw", Secret: "gw-secret", Perms: []string{"orders.read", "orders.write"}, Enabled: true, CertSubject: "svc-order-gw"},
 	"svc-analytics":    {ID: "svc-analytics", Secret: "ana-secret", Perms: []string{"orders.read"}, Enabled: true},
+	"demo-user":        {ID: "demo-user", Secret: "demo-user-secret", Perms: []string{"orders.read", "orders.write", "webhooks.manage", "jobs.manage"}, Enabled: true, UserID: "user-demo", Tenant: "demo"},
+}
+
+// ClientByCert maps a verified client certificate to a registry client by CN or full subject DN.
+func ClientByCert(cert *x509.Certificate) (Client, bool) {
+	cn, dn := cert.Subject.CommonName, cert.Subject.String()
+	for _, cl := range Clients {
+		if cl.Enabled && cl.CertSubject != "" && (cl.CertSubject == cn || cl.CertSubject == dn) {
+			return cl, true
+		}
+	}
+	return Client{}, false
 }
@@ -5,8 +5,6 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
-	"crypto/rsa"
-	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io"
@@ -15,8 +13,12 @@ import (
 type CryptoService interface {
 	Encrypt(plaintext []byte) ([]byte, error)
 	Decrypt(ciphertext []byte) ([]byte, error)
+	// EncryptWithAAD / DecryptWithAAD bind the ciphertext to context (route, client, kid) via GCM additional data.
+	EncryptWithAAD(plaintext, aad []byte) ([]byte, error)
+	DecryptWithAAD(ciphertext, aad []byte) ([]byte, error)
 	Sign(payload []byte) ([]byte, error)
 	Verify(payload, signature []byte) error
+	SigAlg() SigAlg
 }
 
 // ---- Implementation ----
@@ -24,16 +26,38 @@ type CryptoService interface {
 type cryptoService struct {
 	aead      cipher.AEAD // AES-256-GCM
 	nonceSize int         // e.g., 12 (nonce = iv "intialization vector")
-	rsaPub    *rsa.PublicKey
-	rsaPriv   *rsa.PrivateKey // optional; nil => verify-only
+	alg       SigAlg
+	pub       crypto.PublicKey
+	priv      crypto.Signer // optional; nil => verify-only
 }
 
 func NewCryptoService(cm *CryptoMaterial) (CryptoService, error) {
 	if len(cm.AESKey) != 32 {
 		return nil, fmt.Errorf("aes key must be 32 bytes, got %d", len(cm.AESKey))
 	}
-	if cm.RSAPub == nil {
-		return nil, errors.New("rsa public key required")
+	alg, err := ParseSigAlg(string(cm.SigAlg))
+	if err != nil {
+		return nil, err
+	}
+
+	var pub crypto.PublicKey
+	var priv crypto.Signer
+	if alg == SigEdDSA {
+		if cm.EdPub == nil {
+			return nil, errors.New("ed25519 public key required")
+		}
+		pub = cm.EdPub
+		if cm.EdPri != nil {
+			priv = cm.EdPri
+		}
+	} else {
+		if cm.RSAPub == nil {
+			return nil, errors.New("rsa public key required")
+		}
+		pub = cm.RSAPub
+		if cm.RSAPri != nil {
+			priv = cm.RSAPri
+		}
 	}
 
 	block, err := aes.NewCipher(cm.AESKey)
@@ -48,17 +72,28 @@ func NewCryptoService(cm *CryptoMaterial) (CryptoService, error) {
 	return &cryptoService{
 		aead:      aead,
 		nonceSize: aead.NonceSize(),
-		rsaPub:    cm.RSAPub,
-		rsaPriv:   cm.RSAPri,
+		alg:       alg,
+		pub:       pub,
+		priv:      priv,
 	}, nil
 }
 
+func (cs *cryptoService) SigAlg() SigAlg { return cs.alg }
+
 func (cs *cryptoService) Encrypt(plaintext []byte) ([]byte, error) {
+	return cs.EncryptWithAAD(plaintext, nil)
+}
+
+func (cs *cryptoService) Decrypt(ciphertext []byte) ([]byte, error) {
+	return cs.DecryptWithAAD(ciphertext, nil)
+}
+
+func (cs *cryptoService) EncryptWithAAD(plaintext, aad []byte) ([]byte, error) {
 	nonce := make([]byte, cs.nonceSize)
 	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
 		return nil, fmt.Errorf("rand nonce: %w", err)
 	}
-	ct := cs.aead.Seal(nil, nonce, plaintext, nil)
+	ct := cs.aead.Seal(nil, nonce, plaintext, aad)
 
 	// concat: nonce || ct
 	out := make([]byte, 0, len(nonce)+len(ct))
@@ -67,13 +102,13 @@ func (cs *cryptoService) Encrypt(plaintext []byte) ([]byte, error) {
 	return out, nil
 }
 
-func (cs *cryptoService) Decrypt(ciphertext []byte) ([]byte, error) {
+func (cs *cryptoService) DecryptWithAAD(ciphertext, aad []byte) ([]byte, error) {
 	if len(ciphertext) < cs.nonceSize+cs.aead.Overhead() {
 		return nil, errors.New("ciphertext too short")
 	}
 	nonce := ciphertext[:cs.nonceSize]
 	ct := ciphertext[cs.nonceSize:]
-	pt, err := cs.aead.Open(nil, nonce, ct, nil)
+	pt, err := cs.aead.Open(nil, nonce, ct, aad)
 	if err != nil {
 		return nil, fmt.Errorf("gcm open: %w", err)
 	}
@@ -81,21 +116,19 @@ func (cs *cryptoService) Decrypt(ciphertext []byte) ([]byte, error) {
 }
 
 func (cs *cryptoService) Sign(payload []byte) ([]byte, error) {
-	if cs.rsaPriv == nil {
-		return nil, errors.New("signing not configured (no RSA private key)")
+	if cs.priv == nil {
+		return nil, errors.New("signing not configured (no private key)")
 	}
-	sum := sha256.Sum256(payload)
-	sig, err := rsa.SignPKCS1v15(rand.Reader, cs.rsaPriv, crypto.SHA256, sum[:])
+	sig, err := signPayload(cs.alg, cs.priv, payload)
 	if err != nil {
-		return nil, fmt.Errorf("rsa sign: %w", err)
+		return nil, fmt.Errorf("%s sign: %w", cs.alg, err)
 	}
 	return sig, nil
 }
 
 func (cs *cryptoService) Verify(payload, signature []byte) error {
-	sum := sha256.Sum256(payload)
-	if err := rsa.VerifyPKCS1v15(cs.rsaPub, crypto.SHA256, sum[:], signature); err != nil {
-		return fmt.Errorf("rsa verify: %w", err)
+	if err := verifyPayload(cs.alg, cs.pub, payload, signature); err != nil {
+		return fmt.Errorf("%s verify: %w", cs.alg, err)
 	}
 	return nil
 }
@@ -0,0 +1,118 @@
+package security
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSignVerify(t *testing.T) {
+	for _, alg := range []SigAlg{SigRS256, SigPS256, SigEdDSA} {
+		t.Run(string(alg), func(t *testing.T) {
+			cs, err := NewCryptoService(testMaterial(t, "v1", alg))
+			if err != nil {
+				t.Fatal(err)
+			}
+			input := SigningInput(1_700_000_000, "AAAAAAAAAAAAAAAAAAAAAA", []byte("ciphertext"))
+			sig, err := cs.Sign(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := cs.Verify(input, sig); err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			input[0] ^= 1
+			if err := cs.Verify(input, sig); err == nil {
+				t.Fatal("tampered input verified")
+			}
+		})
+	}
+}
+
+func TestNewCryptoServiceRejectsKeyForOtherAlg(t *testing.T) {
+	cm := testMaterial(t, "v1", SigEdDSA)
+	cm.SigAlg = SigPS256
+	if _, err := NewCryptoService(cm); err == nil {
+		t.Fatal("Ed25519 key accepted for PS256")
+	}
+}
+
+// A v2 envelope only opens under the exact context it was sealed for.
+func TestEnvelopeAADBinding(t *testing.T) {
+	cs, err := NewCryptoService(testMaterial(t, "2025-01", SigEdDSA))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed := EnvelopeContext{Version: EnvelopeV2, Method: "POST", Path: "/v1/orders", ClientID: "client-a", KeyID: "2025-01"}
+	const ts, nonce = int64(1_700_000_000), "AAAAAAAAAAAAAAAAAAAAAA"
+	ct, err := cs.EncryptWithAAD([]byte(`{"amount":1}`), sealed.RequestAAD())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := cs.Sign(SigningInputAAD(sealed.Version, ts, nonce, sealed.RequestAAD(), ct))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	with := func(f func(*EnvelopeContext)) EnvelopeContext { e := sealed; f(&e); return e }
+	for _, tc := range []struct {
+		name string
+		aad  []byte
+		ok   bool
+	}{
+		{"same context", sealed.RequestAAD(), true},
+		{"method is case-insensitive", with(func(e *EnvelopeContext) { e.Method = "post" }).RequestAAD(), true},
+		{"wrong kid", with(func(e *EnvelopeContext) { e.KeyID = "2024-12" }).RequestAAD(), false},
+		{"other client", with(func(e *EnvelopeContext) { e.ClientID = "client-b" }).RequestAAD(), false},
+		{"other route", with(func(e *EnvelopeContext) { e.Path = "/v1/orders/batch" }).RequestAAD(), false},
+		{"other method", with(func(e *EnvelopeContext) { e.Method = "PUT" }).RequestAAD(), false},
+		{"response direction", sealed.ResponseAAD(), false},
+		{"downgraded to v1", nil, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := cs.DecryptWithAAD(ct, tc.aad)
+			if (err == nil) != tc.ok {
+				t.Fatalf("DecryptWithAAD = %v, want ok=%v", err, tc.ok)
+			}
+			version := EnvelopeV2
+			if tc.aad == nil {
+				version = EnvelopeV1
+			}
+			err = cs.Verify(SigningInputAAD(version, ts, nonce, tc.aad, ct), sig)
+			if (err == nil) != tc.ok {
+				t.Fatalf("Verify = %v, want ok=%v", err, tc.ok)
+			}
+		})
+	}
+}
+
+func TestSigningInputAADIsV1CompatibleWithoutAAD(t *testing.T) {
+	ct := []byte("ciphertext")
+	if got, want := string(SigningInputAAD(EnvelopeV1, 1, "n", nil, ct)), string(SigningInput(1, "n", ct)); got != want {
+		t.Fatalf("v1 input = %q, want %q", got, want)
+	}
+}
+
+func TestNormalizeEnvelopeVersion(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		v, min  int
+		want    int
+		wantErr bool
+	}{
+		{"missing means v1", 0, 0, EnvelopeV1, false},
+		{"v2", EnvelopeV2, 0, EnvelopeV2, false},
+		{"v1 below minimum", 0, EnvelopeV2, 0, true},
+		{"newer than latest", LatestEnvelopeVersion + 1, 0, 0, true},
+		{"negative", -1, 0, 0, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := NormalizeEnvelopeVersion(tc.v, tc.min)
+			if (err != nil) != tc.wantErr || got != tc.want {
+				t.Fatalf("NormalizeEnvelopeVersion(%d, %d) = %d, %v", tc.v, tc.min, got, err)
+			}
+			if err != nil && !errors.Is(err, ErrEnvelopeVersion) {
+				t.Fatalf("err = %v, want ErrEnvelopeVersion", err)
+			}
+		})
+	}
+}
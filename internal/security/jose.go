@@ -0,0 +1,221 @@
+package security
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Minimal JOSE support (RFC 7515/7516) for the algorithms we accept:
+//   - JWS: RS256, PS256, EdDSA, verified with the client's key from the KeyStore.
+//   - JWE: RSA-OAEP-256 key wrapping to the server key + A256GCM content encryption.
+// Only compact serialization is supported.
+
+const (
+	JWEAlgRSAOAEP256 = "RSA-OAEP-256"
+	JWEEncA256GCM    = "A256GCM"
+)
+
+var (
+	ErrMalformedJOSE = errors.New("malformed JOSE object")
+	ErrJOSEHeader    = errors.New("unsupported JOSE header")
+)
+
+// JOSEHeader holds the protected header fields we read or write.
+// iat/nonce feed the replay guard; htm/htu bind a request JWS to a route (as in DPoP).
+type JOSEHeader struct {
+	Alg   string `json:"alg"`
+	Enc   string `json:"enc,omitempty"`
+	Kid   string `json:"kid,omitempty"`
+	Typ   string `json:"typ,omitempty"`
+	Cty   string `json:"cty,omitempty"`
+	Iat   int64  `json:"iat,omitempty"`
+	Nonce string `json:"nonce,omitempty"`
+	Htm   string `json:"htm,omitempty"`
+	Htu   string `json:"htu,omitempty"`
+}
+
+var b64 = base64.RawURLEncoding
+
+func encodeHeader(h JOSEHeader) (string, error) {
+	b, err := json.Marshal(h)
+	if err != nil {
+		return "", err
+	}
+	return b64.EncodeToString(b), nil
+}
+
+func decodeHeader(seg string) (JOSEHeader, error) {
+	var h JOSEHeader
+	b, err := b64.DecodeString(seg)
+	if err != nil {
+		return h, ErrMalformedJOSE
+	}
+	if err := json.Unmarshal(b, &h); err != nil {
+		return h, ErrMalformedJOSE
+	}
+	return h, nil
+}
+
+// PeekJWSHeader returns the protected header of a compact or detached JWS without verifying it,
+// so the caller can pick the key by kid.
+func PeekJWSHeader(jws string) (JOSEHeader, error) {
+	parts := strings.Split(jws, ".")
+	if len(parts) != 3 {
+		return JOSEHeader{}, ErrMalformedJOSE
+	}
+	return decodeHeader(parts[0])
+}
+
+// SignJWS produces a compact JWS over payload with cs (h.Alg is set from the key).
+func SignJWS(cs CryptoService, h JOSEHeader, payload []byte) (string, error) {
+	h.Alg = string(cs.SigAlg())
+	hdr, err := encodeHeader(h)
+	if err != nil {
+		return "", err
+	}
+	input := hdr + "." + b64.EncodeToString(payload)
+	sig, err := cs.Sign([]byte(input))
+	if err != nil {
+		return "", err
+	}
+	return input + "." + b64.EncodeToString(sig), nil
+}
+
+// DetachJWS turns a compact JWS into its detached form "<header>..<signature>" (RFC 7515 Appendix F).
+func DetachJWS(jws string) string {
+	parts := strings.Split(jws, ".")
+	if len(parts) != 3 {
+		return jws
+	}
+	return parts[0] + ".." + parts[2]
+}
+
+// VerifyJWS checks a compact JWS and returns its header and payload. For a detached JWS pass the payload;
+// for an attached one pass nil. The header alg must equal the key's algorithm.
+func VerifyJWS(cs CryptoService, jws string, detachedPayload []byte) (JOSEHeader, []byte, error) {
+	parts := strings.Split(jws, ".")
+	if len(parts) != 3 {
+		return JOSEHeader{}, nil, ErrMalformedJOSE
+	}
+	h, err := decodeHeader(parts[0])
+	if err != nil {
+		return h, nil, err
+	}
+	if SigAlg(h.Alg) != cs.SigAlg() {
+		return h, nil, fmt.Errorf("%w: alg %q", ErrJOSEHeader, h.Alg)
+	}
+
+	payloadSeg := parts[1]
+	var payload []byte
+	if detachedPayload != nil {
+		if payloadSeg != "" {
+			return h, nil, ErrMalformedJOSE
+		}
+		payload = detachedPayload
+		payloadSeg = b64.EncodeToString(detachedPayload)
+	} else if payload, err = b64.DecodeString(payloadSeg); err != nil {
+		return h, nil, ErrMalformedJOSE
+	}
+
+	sig, err := b64.DecodeString(parts[2])
+	if err != nil {
+		return h, nil, ErrMalformedJOSE
+	}
+	if err := cs.Verify([]byte(parts[0]+"."+payloadSeg), sig); err != nil {
+		return h, nil, err
+	}
+	return h, payload, nil
+}
+
+// EncryptJWE wraps a fresh CEK to pub with RSA-OAEP-256 and encrypts plaintext with A256GCM.
+func EncryptJWE(pub *rsa.PublicKey, h JOSEHeader, plaintext []byte) (string, error) {
+	h.Alg, h.Enc = JWEAlgRSAOAEP256, JWEEncA256GCM
+	hdr, err := encodeHeader(h)
+	if err != nil {
+		return "", err
+	}
+	cek := make([]byte, 32)
+	if _, err := rand.Read(cek); err != nil {
+		return "", err
+	}
+	encKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, cek, nil)
+	if err != nil {
+		return "", fmt.Errorf("wrap cek: %w", err)
+	}
+	aead, err := newGCM(cek)
+	if err != nil {
+		return "", err
+	}
+	iv := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nil, iv, plaintext, []byte(hdr))
+	ct, tag := sealed[:len(sealed)-aead.Overhead()], sealed[len(sealed)-aead.Overhead():]
+	return strings.Join([]string{
+		hdr, b64.EncodeToString(encKey), b64.EncodeToString(iv), b64.EncodeToString(ct), b64.EncodeToString(tag),
+	}, "."), nil
+}
+
+// DecryptJWE opens a compact JWE addressed to the server key.
+func DecryptJWE(s *ServerSigner, jwe string) (JOSEHeader, []byte, error) {
+	parts := strings.Split(jwe, ".")
+	if len(parts) != 5 {
+		return JOSEHeader{}, nil, ErrMalformedJOSE
+	}
+	h, err := decodeHeader(parts[0])
+	if err != nil {
+		return h, nil, err
+	}
+	if h.Alg != JWEAlgRSAOAEP256 || h.Enc != JWEEncA256GCM {
+		return h, nil, fmt.Errorf("%w: alg %q enc %q", ErrJOSEHeader, h.Alg, h.Enc)
+	}
+	priv, ok := s.rsaKey()
+	if !ok {
+		return h, nil, errors.New("server key is not RSA; cannot unwrap JWE")
+	}
+
+	var segs [4][]byte
+	for i := range segs {
+		if segs[i], err = b64.DecodeString(parts[i+1]); err != nil {
+			return h, nil, ErrMalformedJOSE
+		}
+	}
+	encKey, iv, ct, tag := segs[0], segs[1], segs[2], segs[3]
+
+	cek, err := rsa.DecryptOAEP(sha256.New(), nil, priv, encKey, nil)
+	if err != nil {
+		return h, nil, fmt.Errorf("unwrap cek: %w", err)
+	}
+	aead, err := newGCM(cek)
+	if err != nil {
+		return h, nil, err
+	}
+	if len(iv) != aead.NonceSize() {
+		return h, nil, ErrMalformedJOSE
+	}
+	pt, err := aead.Open(nil, iv, append(ct, tag...), []byte(parts[0]))
+	if err != nil {
+		return h, nil, fmt.Errorf("gcm open: %w", err)
+	}
+	return h, pt, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("A256GCM key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
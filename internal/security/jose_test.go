@@ -0,0 +1,101 @@
+package security
+
+import (
+	"crypto/rsa"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestVerifyJWS(t *testing.T) {
+	cs, err := NewCryptoService(testMaterial(t, "v1", SigPS256))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewCryptoService(testMaterial(t, "v1", SigPS256))
+	if err != nil {
+		t.Fatal(err)
+	}
+	eddsa, err := NewCryptoService(testMaterial(t, "v1", SigEdDSA))
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := []byte(`{"amount":1}`)
+	jws, err := SignJWS(cs, JOSEHeader{Kid: "v1", Iat: 1_700_000_000, Nonce: "AAAAAAAAAAAAAAAAAAAAAA"}, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(jws, ".")
+	tampered := parts[0] + "." + b64.EncodeToString([]byte(`{"amount":9}`)) + "." + parts[2]
+
+	for _, tc := range []struct {
+		name     string
+		cs       CryptoService
+		jws      string
+		detached []byte
+		wantErr  error // nil with ok=false means "any error"
+		ok       bool
+	}{
+		{"attached", cs, jws, nil, nil, true},
+		{"detached", cs, DetachJWS(jws), payload, nil, true},
+		{"detached, other payload", cs, DetachJWS(jws), []byte(`{"amount":9}`), nil, false},
+		{"detached JWS without payload", cs, DetachJWS(jws), nil, nil, false},
+		{"payload given for attached JWS", cs, jws, payload, ErrMalformedJOSE, false},
+		{"tampered payload", cs, tampered, nil, nil, false},
+		{"wrong client key", other, jws, nil, nil, false},
+		{"alg differs from key", eddsa, jws, nil, ErrJOSEHeader, false},
+		{"two segments", cs, parts[0] + "." + parts[1], nil, ErrMalformedJOSE, false},
+		{"header not base64url", cs, "!." + parts[1] + "." + parts[2], nil, ErrMalformedJOSE, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h, got, err := VerifyJWS(tc.cs, tc.jws, tc.detached)
+			if (err == nil) != tc.ok {
+				t.Fatalf("VerifyJWS = %v, want ok=%v", err, tc.ok)
+			}
+			if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+				t.Fatalf("VerifyJWS = %v, want %v", err, tc.wantErr)
+			}
+			if tc.ok && (string(got) != string(payload) || h.Kid != "v1" || h.Alg != string(SigPS256)) {
+				t.Fatalf("VerifyJWS = %+v %q", h, got)
+			}
+		})
+	}
+}
+
+func TestJWERoundTrip(t *testing.T) {
+	cm := testMaterial(t, "server-v1", SigPS256)
+	server, err := NewServerSigner("server-v1", SigPS256, cm.RSAPri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stranger, err := NewServerSigner("server-v1", SigPS256, testMaterial(t, "x", SigPS256).RSAPri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := server.PublicKey().(*rsa.PublicKey)
+	jwe, err := EncryptJWE(pub, JOSEHeader{Kid: "server-v1", Cty: "application/json"}, []byte(`{"amount":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h, pt, err := DecryptJWE(server, jwe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(pt) != `{"amount":1}` || h.Alg != JWEAlgRSAOAEP256 || h.Enc != JWEEncA256GCM {
+		t.Fatalf("DecryptJWE = %+v %q", h, pt)
+	}
+
+	if _, _, err := DecryptJWE(stranger, jwe); err == nil {
+		t.Fatal("JWE opened with another server key")
+	}
+	parts := strings.Split(jwe, ".")
+	hdr, _ := encodeHeader(JOSEHeader{Alg: JWEAlgRSAOAEP256, Enc: JWEEncA256GCM, Kid: "other"})
+	if _, _, err := DecryptJWE(server, hdr+"."+strings.Join(parts[1:], ".")); err == nil {
+		t.Fatal("JWE opened with a swapped protected header")
+	}
+	hdr, _ = encodeHeader(JOSEHeader{Alg: "RSA1_5", Enc: JWEEncA256GCM})
+	if _, _, err := DecryptJWE(server, hdr+"."+strings.Join(parts[1:], ".")); !errors.Is(err, ErrJOSEHeader) {
+		t.Fatalf("RSA1_5 = %v, want ErrJOSEHeader", err)
+	}
+}
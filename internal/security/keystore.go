@@ -1,12 +1,19 @@
 package security
 
 import (
+	"crypto"
+	"crypto/ed25519"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/aq2208/gorder-api/configs"
 )
@@ -14,8 +21,11 @@ import (
 type CryptoMaterial struct {
 	KeyID  string
 	AESKey []byte
+	SigAlg SigAlg // empty = RS256
 	RSAPub *rsa.PublicKey
 	RSAPri *rsa.PrivateKey
+	EdPub  ed25519.PublicKey // for SigEdDSA
+	EdPri  ed25519.PrivateKey
 }
 
 func NewCryptoMaterial(c configs.Config) (*CryptoMaterial, error) {
@@ -101,3 +111,294 @@ func parseRSAPrivateKeyFromPEM(pemBytes []byte) (*rsa.PrivateKey, error) {
 	}
 	return rsaKey, nil
 }
+
+// LegacyClient is the keystore scope for the single service-wide key from crypto.aes256_b64url/rsa_pub_pem.
+// With crypto.legacy_key_fallback it is used for any client that has no keys of its own, so existing
+// callers keep working until they get per-client keys; a key found that way has this ClientID.
+const LegacyClient = "*"
+
+var (
+	ErrUnknownKey  = errors.New("unknown client key")
+	ErrKeyRequired = errors.New("kid required: client has multiple active keys")
+)
+
+// ClientKey is the verification + decryption material for one (client, kid).
+type ClientKey struct {
+	ClientID string
+	KeyID    string
+	Service  CryptoService
+}
+
+// KeyStore maps client ID + kid to that client's key material.
+// Several kids may be active per client at once, which is how rotation works:
+// add the new kid, move the client over, then drop the old one.
+type KeyStore struct {
+	mu             sync.RWMutex
+	keys           map[string]map[string]*ClientKey // clientID -> kid -> key
+	legacyFallback bool
+}
+
+type KeyStoreOption func(*KeyStore)
+
+// WithLegacyFallback lets clients without keys of their own use the LegacyClient key (default off).
+func WithLegacyFallback(on bool) KeyStoreOption {
+	return func(ks *KeyStore) { ks.legacyFallback = on }
+}
+
+func NewKeyStore(opts ...KeyStoreOption) *KeyStore {
+	ks := &KeyStore{keys: map[string]map[string]*ClientKey{}}
+	for _, opt := range opts {
+		opt(ks)
+	}
+	return ks
+}
+
+// Add registers material for (clientID, kid), replacing any previous entry.
+func (ks *KeyStore) Add(clientID, kid string, cm *CryptoMaterial) error {
+	cs, err := NewCryptoService(cm)
+	if err != nil {
+		return fmt.Errorf("client %s kid %s: %w", clientID, kid, err)
+	}
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if ks.keys[clientID] == nil {
+		ks.keys[clientID] = map[string]*ClientKey{}
+	}
+	ks.keys[clientID][kid] = &ClientKey{ClientID: clientID, KeyID: kid, Service: cs}
+	return nil
+}
+
+// Lookup resolves the key for a request. An empty kid is accepted only when the client has exactly one key.
+// Clients without their own keys get ErrUnknownKey, or the legacy service-wide key when the fallback is on.
+func (ks *KeyStore) Lookup(clientID, kid string) (*ClientKey, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	byKid := ks.keys[clientID]
+	if len(byKid) == 0 && ks.legacyFallback && clientID != LegacyClient {
+		byKid = ks.keys[LegacyClient]
+	}
+	if len(byKid) == 0 || clientID == LegacyClient {
+		return nil, ErrUnknownKey
+	}
+	if kid == "" {
+		if len(byKid) != 1 {
+			return nil, ErrKeyRequired
+		}
+		for _, k := range byKid {
+			return k, nil
+		}
+	}
+	k, ok := byKid[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return k, nil
+}
+
+// LoadKeyStore builds the keystore from config: the legacy inline key (if set),
+// crypto.clients entries, and the crypto.keys_dir tree.
+func LoadKeyStore(c configs.Config) (*KeyStore, error) {
+	ks := NewKeyStore(WithLegacyFallback(c.CryptoConfig.LegacyKeyFallback))
+
+	if c.CryptoConfig.AES256B64 != "" || c.CryptoConfig.RSAPubPEM != "" {
+		cm, err := LoadCryptoMaterial(c)
+		if err != nil {
+			return nil, err
+		}
+		if err := ks.Add(LegacyClient, cm.KeyID, &cm); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, kc := range c.CryptoConfig.Clients {
+		cm, err := loadClientKey(kc)
+		if err != nil {
+			return nil, fmt.Errorf("crypto.clients[%s/%s]: %w", kc.ClientID, kc.KeyID, err)
+		}
+		if err := ks.Add(kc.ClientID, kc.KeyID, cm); err != nil {
+			return nil, err
+		}
+	}
+
+	if dir := c.CryptoConfig.KeysDir; dir != "" {
+		if err := ks.loadDir(dir); err != nil {
+			return nil, fmt.Errorf("crypto.keys_dir: %w", err)
+		}
+	}
+	return ks, nil
+}
+
+func loadClientKey(kc configs.CryptoClientKey) (*CryptoMaterial, error) {
+	if kc.ClientID == "" || kc.KeyID == "" {
+		return nil, errors.New("client_id and kid required")
+	}
+	aesB64, err := inlineOrFile(kc.AES256B64, kc.AES256File)
+	if err != nil {
+		return nil, err
+	}
+	pubPEM, err := inlineOrFile(kc.RSAPubPEM, kc.RSAPubPEMFile)
+	if err != nil {
+		return nil, err
+	}
+	priPEM, err := inlineOrFile("", kc.RSAPriPEMFile)
+	if err != nil {
+		return nil, err
+	}
+	return buildMaterial(kc.KeyID, kc.SigAlg, aesB64, pubPEM, priPEM)
+}
+
+// loadDir reads <dir>/<client_id>/<kid>/{aes256.key,rsa_pub.pem|pub.pem[,rsa_pri.pem|pri.pem][,sig_alg]}.
+func (ks *KeyStore) loadDir(dir string) error {
+	clients, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, cl := range clients {
+		if !cl.IsDir() {
+			continue
+		}
+		kids, err := os.ReadDir(filepath.Join(dir, cl.Name()))
+		if err != nil {
+			return err
+		}
+		for _, kd := range kids {
+			if !kd.IsDir() {
+				continue
+			}
+			base := filepath.Join(dir, cl.Name(), kd.Name())
+			aesB64, err := readTrimmed(filepath.Join(base, "aes256.key"))
+			if err != nil {
+				return err
+			}
+			pubPEM, err := readFirst(base, "rsa_pub.pem", "pub.pem")
+			if err != nil {
+				return err
+			}
+			priPEM, err := readFirst(base, "rsa_pri.pem", "pri.pem")
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			alg, err := readTrimmed(filepath.Join(base, "sig_alg"))
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			cm, err := buildMaterial(kd.Name(), alg, aesB64, pubPEM, priPEM)
+			if err != nil {
+				return fmt.Errorf("%s: %w", base, err)
+			}
+			if err := ks.Add(cl.Name(), kd.Name(), cm); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func buildMaterial(kid, algName, aesB64, pubPEM, priPEM string) (*CryptoMaterial, error) {
+	if aesB64 == "" || pubPEM == "" {
+		return nil, errors.New("missing aes key or public key")
+	}
+	alg, err := ParseSigAlg(algName)
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.RawURLEncoding.DecodeString(aesB64)
+	if err != nil {
+		return nil, fmt.Errorf("decode aes key: %w", err)
+	}
+	cm := &CryptoMaterial{KeyID: kid, AESKey: key, SigAlg: alg}
+
+	pub, err := parsePublicKeyFromPEM([]byte(pubPEM))
+	if err != nil {
+		return nil, fmt.Errorf("parse pub pem: %w", err)
+	}
+	if err := checkKeyMatchesAlg(alg, pub); err != nil {
+		return nil, err
+	}
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		cm.RSAPub = k
+	case ed25519.PublicKey:
+		cm.EdPub = k
+	}
+
+	if priPEM != "" {
+		pri, err := parsePrivateKeyFromPEM([]byte(priPEM))
+		if err != nil {
+			return nil, fmt.Errorf("parse pri pem: %w", err)
+		}
+		switch k := pri.(type) {
+		case *rsa.PrivateKey:
+			cm.RSAPri = k
+		case ed25519.PrivateKey:
+			cm.EdPri = k
+		}
+	}
+	return cm, nil
+}
+
+// parsePublicKeyFromPEM accepts PKIX RSA or Ed25519 public keys.
+func parsePublicKeyFromPEM(pemBytes []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("no pem block")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	switch pub.(type) {
+	case *rsa.PublicKey, ed25519.PublicKey:
+		return pub, nil
+	}
+	return nil, fmt.Errorf("unsupported public key type %T", pub)
+}
+
+// parsePrivateKeyFromPEM accepts PKCS#8 RSA/Ed25519 keys and PKCS#1 RSA keys.
+func parsePrivateKeyFromPEM(pemBytes []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("no pem block in private key")
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		switch k := key.(type) {
+		case *rsa.PrivateKey:
+			return k, nil
+		case ed25519.PrivateKey:
+			return k, nil
+		}
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return parseRSAPrivateKeyFromPEM(pemBytes)
+}
+
+func inlineOrFile(inline, path string) (string, error) {
+	if inline != "" || path == "" {
+		return inline, nil
+	}
+	return readTrimmed(path)
+}
+
+// readFirst returns the first of names that exists in dir.
+func readFirst(dir string, names ...string) (string, error) {
+	var err error
+	for _, n := range names {
+		var s string
+		if s, err = readTrimmed(filepath.Join(dir, n)); err == nil {
+			return s, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+	}
+	return "", err
+}
+
+func readTrimmed(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
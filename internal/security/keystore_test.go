@@ -0,0 +1,108 @@
+package security
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+)
+
+// testMaterial generates a fresh AES key and signing key pair for alg.
+func testMaterial(t *testing.T, kid string, alg SigAlg) *CryptoMaterial {
+	t.Helper()
+	cm := &CryptoMaterial{KeyID: kid, AESKey: make([]byte, 32), SigAlg: alg}
+	if _, err := rand.Read(cm.AESKey); err != nil {
+		t.Fatal(err)
+	}
+	if alg == SigEdDSA {
+		pub, pri, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cm.EdPub, cm.EdPri = pub, pri
+		return cm
+	}
+	pri, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cm.RSAPub, cm.RSAPri = &pri.PublicKey, pri
+	return cm
+}
+
+func TestKeyStoreLookup(t *testing.T) {
+	build := func(fallback bool) *KeyStore {
+		ks := NewKeyStore(WithLegacyFallback(fallback))
+		for _, k := range []struct{ client, kid string }{
+			{"rotating", "2024-12"}, {"rotating", "2025-01"}, {"single", "v1"}, {LegacyClient, "legacy"},
+		} {
+			if err := ks.Add(k.client, k.kid, testMaterial(t, k.kid, SigEdDSA)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return ks
+	}
+	strict, lenient := build(false), build(true)
+
+	for _, tc := range []struct {
+		name        string
+		ks          *KeyStore
+		client, kid string
+		wantClient  string // ClientID of the key found
+		wantKid     string
+		wantErr     error
+	}{
+		{"kid selects among active keys", strict, "rotating", "2025-01", "rotating", "2025-01", nil},
+		{"old kid still active", strict, "rotating", "2024-12", "rotating", "2024-12", nil},
+		{"no kid with several keys", strict, "rotating", "", "", "", ErrKeyRequired},
+		{"no kid with one key", strict, "single", "", "single", "v1", nil},
+		{"unknown kid", strict, "single", "v2", "", "", ErrUnknownKey},
+		{"another client's kid", strict, "single", "2025-01", "", "", ErrUnknownKey},
+		{"client without keys", strict, "newcomer", "", "", "", ErrUnknownKey},
+		{"client without keys, fallback on", lenient, "newcomer", "", LegacyClient, "legacy", nil},
+		{"own keys win over the fallback", lenient, "single", "", "single", "v1", nil},
+		{"legacy scope is not a client", lenient, LegacyClient, "", "", "", ErrUnknownKey},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			k, err := tc.ks.Lookup(tc.client, tc.kid)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Lookup = %v, want %v", err, tc.wantErr)
+			}
+			if err == nil && (k.ClientID != tc.wantClient || k.KeyID != tc.wantKid) {
+				t.Fatalf("Lookup = %s/%s, want %s/%s", k.ClientID, k.KeyID, tc.wantClient, tc.wantKid)
+			}
+		})
+	}
+}
+
+// One client's key material neither verifies nor decrypts another client's envelope.
+func TestWrongClientKey(t *testing.T) {
+	ks := NewKeyStore()
+	for _, client := range []string{"client-a", "client-b"} {
+		if err := ks.Add(client, "v1", testMaterial(t, "v1", SigPS256)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a, _ := ks.Lookup("client-a", "v1")
+	b, _ := ks.Lookup("client-b", "v1")
+
+	ct, err := a.Service.Encrypt([]byte(`{"amount":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := SigningInput(1_700_000_000, "AAAAAAAAAAAAAAAAAAAAAA", ct)
+	sig, err := a.Service.Sign(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Service.Verify(input, sig); err != nil {
+		t.Fatalf("own key: %v", err)
+	}
+	if err := b.Service.Verify(input, sig); err == nil {
+		t.Fatal("client-b's key verified client-a's signature")
+	}
+	if _, err := b.Service.Decrypt(ct); err == nil {
+		t.Fatal("client-b's key decrypted client-a's ciphertext")
+	}
+}
@@ -0,0 +1,18 @@
+package security
+
+import (
+	"github.com/aq2208/gorder-api/configs"
+	"github.com/google/wire"
+)
+
+// ProviderSet provides the request crypto: client keys, the server signer and the replay guard
+// (which needs a NonceStore from the cache set).
+var ProviderSet = wire.NewSet(
+	LoadKeyStore,
+	LoadServerSigner,
+	ProvideReplayGuard,
+)
+
+func ProvideReplayGuard(store NonceStore, cfg configs.Config) *ReplayGuard {
+	return NewReplayGuard(store, cfg.CryptoConfig.ReplayWindow)
+}
@@ -0,0 +1,78 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrStaleRequest = errors.New("request timestamp outside freshness window")
+	ErrReplayed     = errors.New("nonce already used")
+	ErrBadNonce     = errors.New("nonce must be 16-64 base64url characters")
+)
+
+// Nonce length bounds; 16 random bytes encode to 22 base64url characters.
+const (
+	MinNonceLen = 16
+	MaxNonceLen = 64
+)
+
+// CheckNonce accepts MinNonceLen..MaxNonceLen characters of the unpadded base64url alphabet. The nonce
+// is a "."-separated field of the signing input, so it must never contain a ".".
+func CheckNonce(nonce string) error {
+	if len(nonce) < MinNonceLen || len(nonce) > MaxNonceLen {
+		return ErrBadNonce
+	}
+	for i := 0; i < len(nonce); i++ {
+		switch ch := nonce[i]; {
+		case 'A' <= ch && ch <= 'Z', 'a' <= ch && ch <= 'z', '0' <= ch && ch <= '9', ch == '-', ch == '_':
+		default:
+			return ErrBadNonce
+		}
+	}
+	return nil
+}
+
+// NonceStore remembers nonces for a bounded time. Claim returns false if the nonce was already seen.
+type NonceStore interface {
+	Claim(ctx context.Context, scope, nonce string, ttl time.Duration) (bool, error)
+}
+
+// ReplayGuard enforces a freshness window on signed timestamps and single use of nonces.
+type ReplayGuard struct {
+	store  NonceStore
+	window time.Duration
+	now    func() time.Time
+}
+
+// NewReplayGuard builds a guard; window <= 0 defaults to 5 minutes.
+func NewReplayGuard(store NonceStore, window time.Duration) *ReplayGuard {
+	if window <= 0 {
+		window = 5 * time.Minute
+	}
+	return &ReplayGuard{store: store, window: window, now: time.Now}
+}
+
+// Check validates ts (unix seconds) and claims nonce within scope (typically the client id).
+func (g *ReplayGuard) Check(ctx context.Context, scope string, ts int64, nonce string) error {
+	if nonce == "" || ts == 0 {
+		return ErrStaleRequest
+	}
+	if err := CheckNonce(nonce); err != nil {
+		return err
+	}
+	skew := g.now().Sub(time.Unix(ts, 0))
+	if skew < -g.window || skew > g.window {
+		return ErrStaleRequest
+	}
+	// a nonce only needs to be remembered for as long as its timestamp could still pass the window check
+	ok, err := g.store.Claim(ctx, scope, nonce, 2*g.window)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrReplayed
+	}
+	return nil
+}
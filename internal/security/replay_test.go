@@ -0,0 +1,85 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mapNonceStore is a NonceStore that never forgets, enough for the window checks here.
+type mapNonceStore struct {
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+func (s *mapNonceStore) Claim(_ context.Context, scope, nonce string, _ time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.seen == nil {
+		s.seen = map[string]bool{}
+	}
+	k := scope + ":" + nonce
+	if s.seen[k] {
+		return false, nil
+	}
+	s.seen[k] = true
+	return true, nil
+}
+
+func TestCheckNonce(t *testing.T) {
+	for _, tc := range []struct {
+		name, nonce string
+		ok          bool
+	}{
+		{"16 random bytes", "q1w2e3r4t5y6u7i8o9p0-_", true},
+		{"min length", strings.Repeat("a", MinNonceLen), true},
+		{"max length", strings.Repeat("a", MaxNonceLen), true},
+		{"too short", strings.Repeat("a", MinNonceLen-1), false},
+		{"too long", strings.Repeat("a", MaxNonceLen+1), false},
+		{"dot splits the signing input", "aaaaaaaa.bbbbbbbb", false},
+		{"padding", "aaaaaaaaaaaaaaaaaaaa==", false},
+		{"standard base64", "aaaaaaaaaaaaaaaa+/aa", false},
+		{"empty", "", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := CheckNonce(tc.nonce); (err == nil) != tc.ok {
+				t.Fatalf("CheckNonce(%q) = %v, want ok=%v", tc.nonce, err, tc.ok)
+			}
+		})
+	}
+}
+
+func TestReplayGuard(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	g := NewReplayGuard(&mapNonceStore{}, 5*time.Minute)
+	g.now = func() time.Time { return now }
+	ctx := context.Background()
+	const nonce = "AAAAAAAAAAAAAAAAAAAAAA"
+
+	if err := g.Check(ctx, "client-a", now.Unix(), nonce); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+	for _, tc := range []struct {
+		name, scope, nonce string
+		ts                 int64
+		want               error
+	}{
+		{"replayed nonce", "client-a", nonce, now.Unix(), ErrReplayed},
+		{"same nonce, other client", "client-b", nonce, now.Unix(), nil},
+		{"stale ts", "client-a", "BBBBBBBBBBBBBBBBBBBBBB", now.Add(-6 * time.Minute).Unix(), ErrStaleRequest},
+		{"future ts", "client-a", "CCCCCCCCCCCCCCCCCCCCCC", now.Add(6 * time.Minute).Unix(), ErrStaleRequest},
+		{"edge of the window", "client-a", "DDDDDDDDDDDDDDDDDDDDDD", now.Add(-5 * time.Minute).Unix(), nil},
+		{"missing ts", "client-a", "EEEEEEEEEEEEEEEEEEEEEE", 0, ErrStaleRequest},
+		{"missing nonce", "client-a", "", now.Unix(), ErrStaleRequest},
+		{"malformed nonce", "client-a", "short.nonce", now.Unix(), ErrBadNonce},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := g.Check(ctx, tc.scope, tc.ts, tc.nonce); !errors.Is(err, tc.want) {
+				t.Fatalf("Check = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
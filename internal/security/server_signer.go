@@ -0,0 +1,75 @@
+package security
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+
+	"github.com/aq2208/gorder-api/configs"
+)
+
+// ServerSigner signs outbound payloads (e.g. encrypted responses) with the service's own private key.
+type ServerSigner struct {
+	KeyID string
+	Alg   SigAlg
+	priv  crypto.Signer
+}
+
+func NewServerSigner(kid string, alg SigAlg, priv crypto.Signer) (*ServerSigner, error) {
+	if err := checkKeyMatchesAlg(alg, priv.Public()); err != nil {
+		return nil, err
+	}
+	return &ServerSigner{KeyID: kid, Alg: alg, priv: priv}, nil
+}
+
+// LoadServerSigner reads crypto.server_rsa_pri_pem(_file). Only with crypto.server_key_from_legacy (dev)
+// does it fall back to crypto.rsa_pri_pem, a key the clients hold as well.
+func LoadServerSigner(c configs.Config) (*ServerSigner, error) {
+	pemStr, err := inlineOrFile(c.CryptoConfig.ServerRSAPriPEM, c.CryptoConfig.ServerRSAPriPEMFile)
+	if err != nil {
+		return nil, fmt.Errorf("server key: %w", err)
+	}
+	if pemStr == "" && c.CryptoConfig.ServerKeyFromLegacy {
+		pemStr = c.CryptoConfig.RSAPriPEM
+	}
+	if pemStr == "" {
+		return nil, errors.New("server key: crypto.server_rsa_pri_pem or server_rsa_pri_pem_file required")
+	}
+	priv, err := parsePrivateKeyFromPEM([]byte(pemStr))
+	if err != nil {
+		return nil, fmt.Errorf("parse server key: %w", err)
+	}
+	alg, err := ParseSigAlg(c.CryptoConfig.ServerSigAlg)
+	if err != nil {
+		return nil, err
+	}
+	kid := c.CryptoConfig.ServerKeyID
+	if kid == "" {
+		kid = "server-v1"
+	}
+	return NewServerSigner(kid, alg, priv)
+}
+
+func (s *ServerSigner) Sign(payload []byte) ([]byte, error) {
+	sig, err := signPayload(s.Alg, s.priv, payload)
+	if err != nil {
+		return nil, fmt.Errorf("%s sign: %w", s.Alg, err)
+	}
+	return sig, nil
+}
+
+func (s *ServerSigner) Verify(payload, signature []byte) error {
+	if err := verifyPayload(s.Alg, s.priv.Public(), payload, signature); err != nil {
+		return fmt.Errorf("%s verify: %w", s.Alg, err)
+	}
+	return nil
+}
+
+// PublicKey is what partners use to verify our signatures (and, for RSA keys, to encrypt JWEs to us).
+func (s *ServerSigner) PublicKey() crypto.PublicKey { return s.priv.Public() }
+
+func (s *ServerSigner) rsaKey() (*rsa.PrivateKey, bool) {
+	k, ok := s.priv.(*rsa.PrivateKey)
+	return k, ok
+}
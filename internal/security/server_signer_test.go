@@ -0,0 +1,80 @@
+package security
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aq2208/gorder-api/configs"
+)
+
+func TestLoadServerSigner(t *testing.T) {
+	pemOf := func(cm *CryptoMaterial) string {
+		der, err := x509.MarshalPKCS8PrivateKey(cm.RSAPri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	}
+	server, legacy := pemOf(testMaterial(t, "server", SigPS256)), pemOf(testMaterial(t, "legacy", SigRS256))
+	keyFile := filepath.Join(t.TempDir(), "server.pem")
+	if err := os.WriteFile(keyFile, []byte(server), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		set     func(c *configs.Config)
+		wantKey string // PEM the signer must hold; empty means LoadServerSigner must fail
+		wantKid string
+	}{
+		{"inline server key", func(c *configs.Config) {
+			c.CryptoConfig.ServerRSAPriPEM, c.CryptoConfig.RSAPriPEM = server, legacy
+		}, server, "server-v1"},
+		{"server key file", func(c *configs.Config) {
+			c.CryptoConfig.ServerRSAPriPEMFile, c.CryptoConfig.ServerKeyID = keyFile, "srv-2025"
+		}, server, "srv-2025"},
+		{"server key wins over legacy", func(c *configs.Config) {
+			c.CryptoConfig.ServerRSAPriPEM, c.CryptoConfig.RSAPriPEM, c.CryptoConfig.ServerKeyFromLegacy = server, legacy, true
+		}, server, "server-v1"},
+		{"legacy only when opted in", func(c *configs.Config) {
+			c.CryptoConfig.RSAPriPEM, c.CryptoConfig.ServerKeyFromLegacy = legacy, true
+		}, legacy, "server-v1"},
+		{"legacy key is not used by default", func(c *configs.Config) { c.CryptoConfig.RSAPriPEM = legacy }, "", ""},
+		{"no key at all", func(c *configs.Config) { c.CryptoConfig.ServerKeyFromLegacy = true }, "", ""},
+		{"missing key file", func(c *configs.Config) {
+			c.CryptoConfig.ServerRSAPriPEMFile = filepath.Join(t.TempDir(), "nope.pem")
+		}, "", ""},
+		{"unknown alg", func(c *configs.Config) {
+			c.CryptoConfig.ServerRSAPriPEM, c.CryptoConfig.ServerSigAlg = server, "HS256"
+		}, "", ""},
+		{"alg does not fit the key", func(c *configs.Config) {
+			c.CryptoConfig.ServerRSAPriPEM, c.CryptoConfig.ServerSigAlg = server, string(SigEdDSA)
+		}, "", ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var c configs.Config
+			tc.set(&c)
+			s, err := LoadServerSigner(c)
+			if tc.wantKey == "" {
+				if err == nil {
+					t.Fatal("LoadServerSigner succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, _ := parsePrivateKeyFromPEM([]byte(tc.wantKey))
+			if !want.Public().(interface{ Equal(crypto.PublicKey) bool }).Equal(s.PublicKey()) {
+				t.Fatal("signer holds the wrong key")
+			}
+			if s.KeyID != tc.wantKid {
+				t.Fatalf("kid = %q, want %q", s.KeyID, tc.wantKid)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package security
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+// SigAlg selects the signature scheme of a key. Names follow JOSE.
+type SigAlg string
+
+const (
+	SigRS256 SigAlg = "RS256" // RSA PKCS#1 v1.5 + SHA-256 (legacy default)
+	SigPS256 SigAlg = "PS256" // RSA-PSS + SHA-256
+	SigEdDSA SigAlg = "EdDSA" // Ed25519
+)
+
+var ErrUnsupportedAlg = errors.New("unsupported signature algorithm")
+
+// ParseSigAlg maps a config value to a SigAlg; empty means RS256.
+func ParseSigAlg(s string) (SigAlg, error) {
+	switch SigAlg(s) {
+	case "", SigRS256:
+		return SigRS256, nil
+	case SigPS256, SigEdDSA:
+		return SigAlg(s), nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnsupportedAlg, s)
+}
+
+var pssOpts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256}
+
+func signPayload(alg SigAlg, priv crypto.Signer, payload []byte) ([]byte, error) {
+	switch alg {
+	case SigRS256, SigPS256:
+		k, ok := priv.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%s needs an RSA private key", alg)
+		}
+		sum := sha256.Sum256(payload)
+		if alg == SigPS256 {
+			return rsa.SignPSS(rand.Reader, k, crypto.SHA256, sum[:], pssOpts)
+		}
+		return rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, sum[:])
+	case SigEdDSA:
+		k, ok := priv.(ed25519.PrivateKey)
+		if !ok {
+			return nil, errors.New("EdDSA needs an Ed25519 private key")
+		}
+		return ed25519.Sign(k, payload), nil
+	}
+	return nil, ErrUnsupportedAlg
+}
+
+func verifyPayload(alg SigAlg, pub crypto.PublicKey, payload, signature []byte) error {
+	switch alg {
+	case SigRS256, SigPS256:
+		k, ok := pub.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%s needs an RSA public key", alg)
+		}
+		sum := sha256.Sum256(payload)
+		if alg == SigPS256 {
+			return rsa.VerifyPSS(k, crypto.SHA256, sum[:], signature, pssOpts)
+		}
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, sum[:], signature)
+	case SigEdDSA:
+		k, ok := pub.(ed25519.PublicKey)
+		if !ok {
+			return errors.New("EdDSA needs an Ed25519 public key")
+		}
+		if !ed25519.Verify(k, payload, signature) {
+			return errors.New("ed25519: invalid signature")
+		}
+		return nil
+	}
+	return ErrUnsupportedAlg
+}
+
+// checkKeyMatchesAlg fails fast at load time instead of on the first request.
+func checkKeyMatchesAlg(alg SigAlg, pub crypto.PublicKey) error {
+	switch pub.(type) {
+	case *rsa.PublicKey:
+		if alg == SigRS256 || alg == SigPS256 {
+			return nil
+		}
+	case ed25519.PublicKey:
+		if alg == SigEdDSA {
+			return nil
+		}
+	}
+	return fmt.Errorf("key type %T cannot be used with %s", pub, alg)
+}
@@ -0,0 +1,209 @@
+package security
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aq2208/gorder-api/internal/logging"
+	"github.com/fsnotify/fsnotify"
+)
+
+var ErrBadCAPEM = errors.New("unable to parse CA cert")
+
+// CertReloader holds a certificate/key pair and an optional CA bundle, and re-reads them when the files change.
+// It plugs into tls.Config through callbacks, so new handshakes pick up rotated files without a restart.
+type CertReloader struct {
+	certFile, keyFile, caFile string
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+	pool *x509.CertPool
+
+	watcher *fsnotify.Watcher
+}
+
+// NewCertReloader loads the files once. certFile/keyFile may be empty (CA only), caFile may be empty (no peer CA).
+func NewCertReloader(certFile, keyFile, caFile string) (*CertReloader, error) {
+	r := &CertReloader{certFile: certFile, keyFile: keyFile, caFile: caFile}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *CertReloader) reload() error {
+	var cert *tls.Certificate
+	if r.certFile != "" || r.keyFile != "" {
+		c, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+		if err != nil {
+			return fmt.Errorf("load key pair: %w", err)
+		}
+		cert = &c
+	}
+	var pool *x509.CertPool
+	if r.caFile != "" {
+		pem, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return fmt.Errorf("read ca: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return ErrBadCAPEM
+		}
+	}
+	r.mu.Lock()
+	r.cert, r.pool = cert, pool
+	r.mu.Unlock()
+	return nil
+}
+
+// Watch reloads on changes to any of the files until Close. Parent directories are watched rather than
+// the files, so atomic replaces (rename, Kubernetes secret symlink swaps) are seen too.
+func (r *CertReloader) Watch() error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	dirs := map[string]struct{}{}
+	for _, f := range []string{r.certFile, r.keyFile, r.caFile} {
+		if f != "" {
+			dirs[filepath.Dir(f)] = struct{}{}
+		}
+	}
+	for d := range dirs {
+		if err := w.Add(d); err != nil {
+			_ = w.Close()
+			return err
+		}
+	}
+	r.watcher = w
+
+	l := logging.New("tls")
+	go func() {
+		var debounce <-chan time.Time
+		for {
+			select {
+			case ev, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				if ev.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) != 0 {
+					// cert and key are usually written one after the other; wait for both
+					debounce = time.After(500 * time.Millisecond)
+				}
+			case <-debounce:
+				if err := r.reload(); err != nil {
+					// keep serving the previous material
+					l.Error("tls reload failed", "cert", r.certFile, "err", err)
+					continue
+				}
+				l.Info("tls material reloaded", "cert", r.certFile, "ca", r.caFile)
+			case err, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+				l.Error("tls watch error", "err", err)
+			}
+		}
+	}()
+	return nil
+}
+
+func (r *CertReloader) Close() error {
+	if r.watcher == nil {
+		return nil
+	}
+	return r.watcher.Close()
+}
+
+func (r *CertReloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, r.pool
+}
+
+// ServerTLSConfig serves the current certificate and verifies client certs against the current CA bundle.
+// nextProtos are the ALPN protocols to offer (e.g. "h2", "http/1.1"); servers that add their own to a
+// clone of the returned config never reach the per-handshake config, so they must be listed here.
+func (r *CertReloader) ServerTLSConfig(clientAuth tls.ClientAuthType, nextProtos ...string) *tls.Config {
+	base := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: nextProtos,
+		ClientAuth: clientAuth,
+	}
+	cfg := base.Clone()
+	cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		cert, pool := r.current()
+		if cert == nil {
+			return nil, errors.New("no server certificate loaded")
+		}
+		hc := base.Clone()
+		hc.Certificates = []tls.Certificate{*cert}
+		hc.ClientCAs = pool
+		return hc, nil
+	}
+	return cfg
+}
+
+// ClientTLSConfig presents the current client certificate (if any) and verifies the server against the
+// current CA bundle (system roots when no CA file is configured).
+func (r *CertReloader) ClientTLSConfig(serverName string) *tls.Config {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _ := r.current()
+			if cert == nil {
+				return &tls.Certificate{}, nil // no client cert
+			}
+			return cert, nil
+		},
+	}
+	if r.caFile == "" {
+		return cfg
+	}
+	// RootCAs is fixed per tls.Config, so verify by hand against the latest pool.
+	cfg.InsecureSkipVerify = true
+	cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+		_, pool := r.current()
+		if len(cs.PeerCertificates) == 0 {
+			return errors.New("server presented no certificate")
+		}
+		inter := x509.NewCertPool()
+		for _, c := range cs.PeerCertificates[1:] {
+			inter.AddCert(c)
+		}
+		name := serverName
+		if name == "" {
+			name = cs.ServerName
+		}
+		_, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+			Roots:         pool,
+			Intermediates: inter,
+			DNSName:       name,
+		})
+		return err
+	}
+	return cfg
+}
+
+// ParseClientAuth maps config values to tls.ClientAuthType.
+func ParseClientAuth(s string) (tls.ClientAuthType, error) {
+	switch s {
+	case "", "none":
+		return tls.NoClientCert, nil
+	case "request":
+		return tls.RequestClientCert, nil
+	case "verify_if_given":
+		return tls.VerifyClientCertIfGiven, nil
+	case "require":
+		return tls.RequireAndVerifyClientCert, nil
+	}
+	return tls.NoClientCert, fmt.Errorf("unknown client_auth %q (none|request|verify_if_given|require)", s)
+}
@@ -0,0 +1,256 @@
+package security
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// issue creates a certificate for name, self-signed when parent is nil.
+func issue(t *testing.T, name string, serial int64, parent *testCert) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA, tmpl.BasicConstraintsValid = true, true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{cert: c, key: key}
+}
+
+// write stores c (and its key, when keyFile is set) as PEM.
+func (c *testCert) write(t *testing.T, certFile, keyFile string) {
+	t.Helper()
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if keyFile == "" {
+		return
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCertReloaderRotation(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	ca := issue(t, "ca", 1, nil)
+	issue(t, "api.local", 10, ca).write(t, certFile, keyFile)
+
+	r, err := NewCertReloader(certFile, keyFile, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial := func() int64 {
+		cert, _ := r.current()
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return leaf.SerialNumber.Int64()
+	}
+
+	issue(t, "api.local", 11, ca).write(t, certFile, keyFile)
+	if err := r.reload(); err != nil {
+		t.Fatal(err)
+	}
+	if got := serial(); got != 11 {
+		t.Fatalf("serial after rotation = %d, want 11", got)
+	}
+
+	// a half-written rotation (new cert, old key) must not replace working material
+	issue(t, "api.local", 12, ca).write(t, certFile, "")
+	if err := r.reload(); err == nil {
+		t.Fatal("reload accepted a cert that does not match its key")
+	}
+	if got := serial(); got != 11 {
+		t.Fatalf("serial after failed reload = %d, want 11", got)
+	}
+}
+
+// mTLS handshakes against a server that requires client certs from its CA bundle.
+func TestServerTLSConfigRequiresClientCert(t *testing.T) {
+	dir := t.TempDir()
+	ca, rogue := issue(t, "ca", 1, nil), issue(t, "rogue-ca", 2, nil)
+	caFile := filepath.Join(dir, "ca.crt")
+	ca.write(t, caFile, "")
+	srvCert, srvKey := filepath.Join(dir, "srv.crt"), filepath.Join(dir, "srv.key")
+	issue(t, "api.local", 10, ca).write(t, srvCert, srvKey)
+	srv, err := NewCertReloader(srvCert, srvKey, caFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := func(name string, parent *testCert) *CertReloader {
+		if parent == nil {
+			r, err := NewCertReloader("", "", caFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return r
+		}
+		cf, kf := filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key")
+		issue(t, name, 20, parent).write(t, cf, kf)
+		r, err := NewCertReloader(cf, kf, caFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	for _, tc := range []struct {
+		name   string
+		client *CertReloader
+		ok     bool
+	}{
+		{"cert from the trusted CA", client("svc-order-gw", ca), true},
+		{"no client cert", client("anonymous", nil), false},
+		{"cert from another CA", client("intruder", rogue), false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := handshake(srv.ServerTLSConfig(tls.RequireAndVerifyClientCert), tc.client.ClientTLSConfig("api.local")); (err == nil) != tc.ok {
+				t.Fatalf("handshake = %v, want ok=%v", err, tc.ok)
+			}
+		})
+	}
+}
+
+// The client verifies the server against the CA bundle as of the handshake, not as of construction.
+func TestClientTLSConfigFollowsCARotation(t *testing.T) {
+	dir := t.TempDir()
+	oldCA, newCA := issue(t, "old-ca", 1, nil), issue(t, "new-ca", 2, nil)
+	srvCert, srvKey, caFile := filepath.Join(dir, "srv.crt"), filepath.Join(dir, "srv.key"), filepath.Join(dir, "ca.crt")
+	issue(t, "api.local", 10, oldCA).write(t, srvCert, srvKey)
+	oldCA.write(t, caFile, "")
+	srv, err := NewCertReloader(srvCert, srvKey, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli, err := NewCertReloader("", "", caFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := cli.ClientTLSConfig("api.local")
+
+	if err := handshake(srv.ServerTLSConfig(tls.NoClientCert), cfg); err != nil {
+		t.Fatalf("before rotation: %v", err)
+	}
+	newCA.write(t, caFile, "")
+	if err := cli.reload(); err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(srv.ServerTLSConfig(tls.NoClientCert), cfg); err == nil {
+		t.Fatal("server cert from the retired CA still accepted")
+	}
+}
+
+// handshake runs both sides over loopback TCP. A net.Pipe would deadlock on failures: it has no
+// buffer, so the failing side blocks writing its alert while the other side is still writing.
+func handshake(server, client *tls.Config) error {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+
+	errc := make(chan error, 1)
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			errc <- err
+			return
+		}
+		defer c.Close()
+		_ = c.SetDeadline(time.Now().Add(5 * time.Second))
+		sc := tls.Server(c, server)
+		if err := sc.Handshake(); err != nil {
+			errc <- err
+			return
+		}
+		// one byte through proves both sides accepted the handshake
+		_, err = sc.Read(make([]byte, 1))
+		errc <- err
+	}()
+
+	c, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	_ = c.SetDeadline(time.Now().Add(5 * time.Second))
+	cc := tls.Client(c, client)
+	if err := cc.Handshake(); err != nil {
+		return err
+	}
+	if _, err := cc.Write([]byte{1}); err != nil {
+		return err
+	}
+	if err := <-errc; err != nil {
+		return err
+	}
+	return nil
+}
+
+func TestParseClientAuth(t *testing.T) {
+	for _, tc := range []struct {
+		in      string
+		want    tls.ClientAuthType
+		wantErr bool
+	}{
+		{"", tls.NoClientCert, false},
+		{"none", tls.NoClientCert, false},
+		{"request", tls.RequestClientCert, false},
+		{"verify_if_given", tls.VerifyClientCertIfGiven, false},
+		{"require", tls.RequireAndVerifyClientCert, false},
+		{"Require", tls.NoClientCert, true},
+		{"mutual", tls.NoClientCert, true},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			got, err := ParseClientAuth(tc.in)
+			if got != tc.want || (err != nil) != tc.wantErr {
+				t.Fatalf("ParseClientAuth(%q) = %v, %v", tc.in, got, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	domain "github.com/aq2208/gorder-api/internal/entity"
+)
+
+var ErrNotCancellable = errors.New("order is not cancellable")
+
+// CancelOrder stops an order that is still PROCESSING. order-gw has no cancel call, so an order it
+// already accepted may still be confirmed; the status consumer then applies that outcome and the
+// reconciliation job reports nothing, since both sides agree.
+type CancelOrder struct {
+	repo   OrderRepo
+	cache  OrderCache
+	events OrderEventPublisher // optional
+}
+
+func NewCancelOrder(repo OrderRepo, cache OrderCache, events OrderEventPublisher) *CancelOrder {
+	return &CancelOrder{repo: repo, cache: cache, events: events}
+}
+
+// Execute cancels id for a caller that may read it and holds orders.write.
+func (uc *CancelOrder) Execute(ctx context.Context, id string) (*OrderRecord, error) {
+	return uc.ExecuteIf(ctx, id, nil)
+}
+
+// ExecuteIf cancels id only while match accepts the order's current version (If-Match); nil matches
+// any version.
+func (uc *CancelOrder) ExecuteIf(ctx context.Context, id string, match VersionMatcher) (*OrderRecord, error) {
+	err := updateStatus(ctx, uc.repo, id, string(domain.StatusCancelled), func(rec *OrderRecord) error {
+		if err := authorizeRead(ctx, rec); err != nil {
+			return err
+		}
+		if p, _ := PrincipalFrom(ctx); !p.Has(PermOrdersWrite) {
+			return ErrForbidden
+		}
+		// preconditions come before the state check, as in HTTP
+		if match != nil && !match(rec.Version) {
+			return ErrPreconditionFailed
+		}
+		if rec.Status != string(domain.StatusProcessing) {
+			return ErrNotCancellable
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if uc.cache != nil {
+		_ = uc.cache.SetStatus(ctx, id, string(domain.StatusCancelled))
+	}
+	if uc.events != nil {
+		_, _ = uc.events.PublishStatus(ctx, id, string(domain.StatusCancelled))
+	}
+	return uc.repo.GetByID(ctx, id)
+}
@@ -3,6 +3,7 @@ package usecase
 import (
 	"context"
 	"errors"
+	"net/url"
 
 	domain "github.com/aq2208/gorder-api/internal/entity"
 	"github.com/google/uuid"
@@ -11,6 +12,8 @@ import (
 type CreateOrderInput struct {
 	UserID, IdempotencyKey, Currency, ItemsJSON string
 	AmountCents                                 int64
+	TenantID                                    string // set from the caller, not the request body
+	ClientID                                    string // set from the caller, not the request body
 }
 
 type CreateOrderOutput struct {
@@ -34,16 +37,22 @@ func NewCreateOrder(repo OrderRepo, cache OrderCache, idem IdempotencyStore, que
 	return &CreateOrder{repo: repo, cache: cache, idem: idem, queue: queue}
 }
 
-// Execute orchestrates: validate -> idempotency -> persist -> enqueue -> return PROCESSING
+// Execute orchestrates: authorize -> validate -> idempotency -> persist -> enqueue -> return PROCESSING
 func (uc *CreateOrder) Execute(ctx context.Context, in CreateOrderInput) (CreateOrderOutput, error) {
+	// Bind owner/tenant to the caller
+	if err := authorizeCreate(ctx, &in); err != nil {
+		return CreateOrderOutput{}, err
+	}
+
 	// Input validation
-	if in.UserID == "" || in.AmountCents <= 0 || in.Currency == "" || in.ItemsJSON == "" {
-		return CreateOrderOutput{}, ErrValidation
+	if err := validateCreate(in); err != nil {
+		return CreateOrderOutput{}, err
 	}
 
 	// Idempotency recall
+	key := ScopedIdempotencyKey(in.ClientID, in.UserID, in.IdempotencyKey)
 	if in.IdempotencyKey != "" && uc.idem != nil {
-		if val, ok, err := uc.idem.Recall(ctx, in.IdempotencyKey); err == nil && ok {
+		if val, ok, err := uc.idem.Recall(ctx, key); err == nil && ok {
 			// Found an existing orderId for this key → return same response (PROCESSING)
 			return CreateOrderOutput{
 				OrderID: val,
@@ -52,38 +61,63 @@ func (uc *CreateOrder) Execute(ctx context.Context, in CreateOrderInput) (Create
 		}
 	}
 
-	// Try lock
-	ok, err := uc.idem.TryLock(ctx, in.IdempotencyKey)
-	if err != nil {
-		return CreateOrderOutput{}, err
+	// Try lock (only meaningful with a key; an empty key would serialize all keyless requests)
+	locked := false
+	if in.IdempotencyKey != "" && uc.idem != nil {
+		ok, err := uc.idem.TryLock(ctx, key)
+		if err != nil {
+			return CreateOrderOutput{}, err
+		}
+		if !ok {
+			return CreateOrderOutput{}, ErrDuplicate
+		}
+		locked = true
 	}
-	if !ok {
-		return CreateOrderOutput{}, ErrDuplicate
+	// release the key when this request does not complete, so the client can retry at once
+	fail := func(err error) (CreateOrderOutput, error) {
+		if locked {
+			_ = uc.idem.Unlock(context.WithoutCancel(ctx), key)
+		}
+		return CreateOrderOutput{}, err
 	}
 
-	// Build order record and validate
-	orderID := uuid.NewString()
-	rec := &OrderRecord{
-		ID:             orderID,
-		UserID:         in.UserID,
-		Status:         string(domain.StatusProcessing),
-		AmountCents:    in.AmountCents,
-		Currency:       in.Currency,
-		ItemsJSON:      in.ItemsJSON,
-		IdempotencyKey: in.IdempotencyKey,
+	// A row already stored under the key is the order of an earlier attempt: the recall above misses
+	// it once the key has expired from the store, or when its publish failed.
+	rec, err := storedOrder(ctx, uc.repo, in)
+	if err != nil {
+		return fail(err)
 	}
-	if err := rec.Validate(); err != nil {
-		return CreateOrderOutput{}, err
+	if rec == nil {
+		// Build order record and validate
+		rec = newOrderRecord(in)
+		if err := rec.Validate(); err != nil {
+			return fail(err)
+		}
+
+		// Persist; a duplicate key means an order was stored under it in the meantime
+		err := uc.repo.Create(ctx, rec)
+		if errors.Is(err, ErrDuplicate) {
+			if rec, err = storedOrder(ctx, uc.repo, in); err == nil && rec == nil {
+				err = ErrDuplicate
+			}
+		}
+		if err != nil {
+			return fail(err)
+		}
 	}
+	orderID := rec.ID
 
-	// Persist
-	if err := uc.repo.Create(ctx, rec); err != nil {
-		return CreateOrderOutput{}, err
+	// An order that has left PROCESSING was published long ago: replay it as it is now
+	if rec.Status != string(domain.StatusProcessing) {
+		if locked {
+			_ = uc.idem.Remember(ctx, key, orderID)
+		}
+		return CreateOrderOutput{OrderID: orderID, Status: rec.Status}, nil
 	}
 
 	// Cache
-	if err := uc.cache.SetStatus(ctx, orderID, string(domain.StatusProcessing)); err != nil {
-		return CreateOrderOutput{}, err
+	if err := uc.cache.SetStatus(ctx, orderID, rec.Status); err != nil {
+		return fail(err)
 	}
 
 	// Enqueue event
@@ -94,15 +128,60 @@ func (uc *CreateOrder) Execute(ctx context.Context, in CreateOrderInput) (Create
 		Currency: rec.Currency,
 	}
 	if err := uc.queue.PublishCreated(ctx, msg); err != nil {
-		// At this point, the row exists as PROCESSING.
-		// Optionally: update to FAILED or rely on ops/retry/outbox (future improvement).
-		return CreateOrderOutput{}, err
+		// The row stays PROCESSING: a retry with the same key publishes it, and the reaper
+		// re-dispatches it if the client never retries.
+		return fail(err)
 	}
 
 	// Remember idempotency key
-	if in.IdempotencyKey != "" && uc.idem != nil {
-		_ = uc.idem.Remember(ctx, in.IdempotencyKey, orderID)
+	if locked {
+		_ = uc.idem.Remember(ctx, key, orderID)
 	}
 
-	return CreateOrderOutput{OrderID: orderID, Status: string(domain.StatusProcessing)}, nil
+	return CreateOrderOutput{OrderID: orderID, Status: rec.Status}, nil
+}
+
+// ScopedIdempotencyKey is the IdempotencyStore key for a request's idempotency key: keys are
+// namespaced by the calling client and the order's user, so one caller cannot recall or block
+// another's.
+func ScopedIdempotencyKey(clientID, userID, key string) string {
+	return url.QueryEscape(clientID) + ":" + url.QueryEscape(userID) + ":" + key
+}
+
+// storedOrder returns the caller's order stored under in's idempotency key, whatever its status, nil
+// if there is none.
+func storedOrder(ctx context.Context, repo OrderRepo, in CreateOrderInput) (*OrderRecord, error) {
+	if in.IdempotencyKey == "" {
+		return nil, nil
+	}
+	rec, err := repo.GetByIdemKey(ctx, in.ClientID, in.UserID, in.IdempotencyKey)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+func validateCreate(in CreateOrderInput) error {
+	if in.UserID == "" || in.AmountCents <= 0 || in.Currency == "" || in.ItemsJSON == "" {
+		return ErrValidation
+	}
+	return nil
+}
+
+// newOrderRecord builds a new PROCESSING order with a fresh id.
+func newOrderRecord(in CreateOrderInput) *OrderRecord {
+	return &OrderRecord{
+		ID:             uuid.NewString(),
+		UserID:         in.UserID,
+		Status:         string(domain.StatusProcessing),
+		AmountCents:    in.AmountCents,
+		Currency:       in.Currency,
+		ItemsJSON:      in.ItemsJSON,
+		IdempotencyKey: in.IdempotencyKey,
+		TenantID:       in.TenantID,
+		ClientID:       in.ClientID,
+	}
 }
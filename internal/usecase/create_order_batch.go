@@ -0,0 +1,267 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	domain "github.com/aq2208/gorder-api/internal/entity"
+)
+
+// Batch persistence modes.
+const (
+	BatchAtomic  = "atomic"   // every valid item is created in one transaction, or none is
+	BatchPerItem = "per_item" // each item is created on its own; failures do not affect the others
+)
+
+var (
+	ErrBatchTooLarge = errors.New("too many orders in batch")
+	// ErrBatchAborted is the result of an otherwise valid item of an atomic batch in which another item failed.
+	ErrBatchAborted = errors.New("not created: another item of the atomic batch failed")
+)
+
+// BatchItemResult is the outcome of one item, in request order. Err is nil when the order was
+// created, or already existed under the item's idempotency key (Replayed).
+type BatchItemResult struct {
+	OrderID  string
+	Status   string
+	Replayed bool
+	Err      error
+}
+
+// CreateOrderBatch creates many orders in one call with the same rules as CreateOrder per item,
+// then publishes all order.created commands as one confirmed batch.
+type CreateOrderBatch struct {
+	repo     OrderBatchRepo
+	cache    OrderCache
+	idem     IdempotencyStore
+	queue    OrderBatchQueue
+	maxItems int
+	mode     string
+}
+
+type BatchOption func(*CreateOrderBatch)
+
+// WithBatchMaxItems caps the items per call (default 100).
+func WithBatchMaxItems(n int) BatchOption { return func(uc *CreateOrderBatch) { uc.maxItems = n } }
+
+// WithBatchMode sets the mode used when the caller does not choose one (default BatchPerItem).
+func WithBatchMode(m string) BatchOption { return func(uc *CreateOrderBatch) { uc.mode = m } }
+
+func NewCreateOrderBatch(repo OrderBatchRepo, cache OrderCache, idem IdempotencyStore, queue OrderBatchQueue, opts ...BatchOption) *CreateOrderBatch {
+	uc := &CreateOrderBatch{repo: repo, cache: cache, idem: idem, queue: queue, maxItems: 100, mode: BatchPerItem}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// Execute returns one result per item. Item problems (validation, ownership, an idempotency key in
+// flight or repeated within the batch, a per-item insert error) are reported in the results; the
+// error is for the call as a whole: bad batch, atomic insert failure, or the publish failing.
+//
+// Idempotency keys behave as in CreateOrder: they are remembered only after the publish, and
+// released when it fails, so a retry with the same keys publishes the orders already stored under
+// them instead of creating new ones.
+func (uc *CreateOrderBatch) Execute(ctx context.Context, items []CreateOrderInput, mode string) ([]BatchItemResult, error) {
+	if len(items) == 0 {
+		return nil, ErrValidation
+	}
+	if len(items) > uc.maxItems {
+		return nil, ErrBatchTooLarge
+	}
+	if mode == "" {
+		mode = uc.mode
+	}
+	if mode != BatchAtomic && mode != BatchPerItem {
+		return nil, ErrValidation
+	}
+
+	res := make([]BatchItemResult, len(items))
+	recs := make([]*OrderRecord, len(items)) // nil: nothing to create for this item
+	idemKeys := make([]string, len(items))   // scoped store keys, "" without an idempotency key
+	seen := map[string]bool{}
+	for i := range items {
+		in := &items[i]
+		if err := authorizeCreate(ctx, in); err != nil {
+			res[i].Err = err
+			continue
+		}
+		if err := validateCreate(*in); err != nil {
+			res[i].Err = err
+			continue
+		}
+		rec := newOrderRecord(*in)
+		if err := rec.Validate(); err != nil {
+			res[i].Err = err
+			continue
+		}
+		if in.IdempotencyKey != "" {
+			k := ScopedIdempotencyKey(in.ClientID, in.UserID, in.IdempotencyKey)
+			if seen[k] {
+				res[i].Err = ErrDuplicate
+				continue
+			}
+			seen[k] = true
+			if uc.idem != nil {
+				if id, ok, err := uc.idem.Recall(ctx, k); err == nil && ok {
+					res[i] = BatchItemResult{OrderID: id, Status: string(domain.StatusProcessing), Replayed: true}
+					continue
+				}
+				idemKeys[i] = k
+			}
+		}
+		recs[i] = rec
+	}
+	if mode == BatchAtomic && abortOnError(res, recs) {
+		return res, nil
+	}
+
+	// Lock the keys of everything still to be created
+	locked := make([]bool, len(items))
+	unlock := func() {
+		for i, k := range idemKeys {
+			if locked[i] {
+				_ = uc.idem.Unlock(context.WithoutCancel(ctx), k)
+				locked[i] = false
+			}
+		}
+	}
+	for i, rec := range recs {
+		if rec == nil || idemKeys[i] == "" {
+			continue
+		}
+		ok, err := uc.idem.TryLock(ctx, idemKeys[i])
+		if err == nil && !ok {
+			err = ErrDuplicate
+		}
+		if err != nil {
+			res[i].Err, recs[i] = err, nil
+			continue
+		}
+		locked[i] = true
+	}
+	if mode == BatchAtomic && abortOnError(res, recs) {
+		unlock()
+		return res, nil
+	}
+
+	// Orders stored by an earlier attempt are not re-created: published again while still PROCESSING
+	// (their publish failed), replayed as they are once they have moved on
+	stored := make([]bool, len(items))
+	for i, rec := range recs {
+		if rec == nil || !locked[i] {
+			continue
+		}
+		prev, err := storedOrder(ctx, uc.repo, items[i])
+		if err != nil {
+			res[i].Err, recs[i] = err, nil
+			continue
+		}
+		switch {
+		case prev == nil:
+		case prev.Status == string(domain.StatusProcessing):
+			recs[i], stored[i] = prev, true
+		default:
+			res[i] = BatchItemResult{OrderID: prev.ID, Status: prev.Status, Replayed: true}
+			_ = uc.idem.Remember(ctx, idemKeys[i], prev.ID)
+			recs[i], locked[i] = nil, false
+		}
+	}
+	if mode == BatchAtomic && abortOnError(res, recs) {
+		unlock()
+		return res, nil
+	}
+
+	// Persist
+	if mode == BatchAtomic {
+		var all []*OrderRecord
+		for i, rec := range recs {
+			if rec != nil && !stored[i] {
+				all = append(all, rec)
+			}
+		}
+		if len(all) > 0 {
+			if err := uc.repo.CreateBatch(ctx, all); err != nil {
+				unlock()
+				return nil, err
+			}
+		}
+	} else {
+		for i, rec := range recs {
+			if rec == nil || stored[i] {
+				continue
+			}
+			err := uc.repo.Create(ctx, rec)
+			if errors.Is(err, ErrDuplicate) {
+				// stored under the key in the meantime: replay that order
+				var prev *OrderRecord
+				if prev, err = storedOrder(ctx, uc.repo, items[i]); err == nil && prev == nil {
+					err = ErrDuplicate
+				}
+				if err == nil {
+					res[i] = BatchItemResult{OrderID: prev.ID, Status: prev.Status, Replayed: true}
+					if locked[i] {
+						_ = uc.idem.Remember(ctx, idemKeys[i], prev.ID)
+					}
+					recs[i], locked[i] = nil, false
+					continue
+				}
+			}
+			if err != nil {
+				res[i].Err, recs[i] = err, nil
+				if locked[i] {
+					_ = uc.idem.Unlock(context.WithoutCancel(ctx), idemKeys[i])
+					locked[i] = false
+				}
+			}
+		}
+	}
+
+	// Cache (best effort), publish, then remember keys
+	var msgs []CreatedMsg
+	for i, rec := range recs {
+		if rec == nil {
+			continue
+		}
+		res[i] = BatchItemResult{OrderID: rec.ID, Status: rec.Status}
+		if uc.cache != nil {
+			_ = uc.cache.SetStatus(ctx, rec.ID, rec.Status)
+		}
+		msgs = append(msgs, CreatedMsg{OrderID: rec.ID, UserID: rec.UserID, Cents: rec.AmountCents, Currency: rec.Currency})
+	}
+	if len(msgs) > 0 {
+		if err := uc.queue.PublishCreatedBatch(ctx, msgs); err != nil {
+			// the rows stay PROCESSING: a retry with the same keys publishes them, and the reaper
+			// re-dispatches them if the client never retries
+			unlock()
+			return nil, err
+		}
+	}
+	for i, rec := range recs {
+		if rec != nil && locked[i] {
+			_ = uc.idem.Remember(ctx, idemKeys[i], rec.ID)
+		}
+	}
+	return res, nil
+}
+
+// abortOnError reports whether any item failed and, if so, marks the items that would have been
+// created as aborted (atomic mode).
+func abortOnError(res []BatchItemResult, recs []*OrderRecord) bool {
+	failed := false
+	for _, r := range res {
+		if r.Err != nil {
+			failed = true
+			break
+		}
+	}
+	if !failed {
+		return false
+	}
+	for i, rec := range recs {
+		if rec != nil {
+			res[i].Err, recs[i] = ErrBatchAborted, nil
+		}
+	}
+	return true
+}
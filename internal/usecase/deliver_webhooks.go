@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DeliverWebhooks sends due deliveries. A 2xx response completes a delivery; anything else is retried
+// with exponential backoff until the attempts run out, then the delivery is failed (it can still be
+// redelivered). Every attempt is logged.
+type DeliverWebhooks struct {
+	repo        WebhookRepo
+	sender      WebhookSender
+	batchSize   int
+	maxAttempts int
+	backoffBase time.Duration
+	backoffMax  time.Duration
+	claimTTL    time.Duration
+}
+
+type DeliverOption func(*DeliverWebhooks)
+
+// WithDeliveryBatch caps the deliveries sent per Execute (default 20).
+func WithDeliveryBatch(n int) DeliverOption { return func(uc *DeliverWebhooks) { uc.batchSize = n } }
+
+// WithDeliveryAttempts sets the attempts before a delivery is failed (default 8).
+func WithDeliveryAttempts(n int) DeliverOption {
+	return func(uc *DeliverWebhooks) { uc.maxAttempts = n }
+}
+
+// WithBackoff sets the delay after the first failure, doubled per attempt up to max (default 30s, 1h).
+func WithBackoff(base, max time.Duration) DeliverOption {
+	return func(uc *DeliverWebhooks) { uc.backoffBase, uc.backoffMax = base, max }
+}
+
+// WithClaimTTL sets how long claimed deliveries are hidden from other workers (default 2m); it must
+// cover sending a whole batch.
+func WithClaimTTL(d time.Duration) DeliverOption {
+	return func(uc *DeliverWebhooks) { uc.claimTTL = d }
+}
+
+func NewDeliverWebhooks(repo WebhookRepo, sender WebhookSender, opts ...DeliverOption) *DeliverWebhooks {
+	uc := &DeliverWebhooks{
+		repo: repo, sender: sender,
+		batchSize: 20, maxAttempts: 8, backoffBase: 30 * time.Second, backoffMax: time.Hour, claimTTL: 2 * time.Minute,
+	}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+type DeliverResult struct {
+	Delivered, Retried, Failed int
+}
+
+// Execute sends one batch. Errors saving an attempt are skipped (the claim expires and the delivery is
+// retried); only a failing claim is returned.
+func (uc *DeliverWebhooks) Execute(ctx context.Context) (DeliverResult, error) {
+	var res DeliverResult
+	due, err := uc.repo.ClaimDue(ctx, uc.batchSize, uc.claimTTL)
+	if err != nil {
+		return res, err
+	}
+
+	for i := range due {
+		d := &due[i]
+		a, gone := uc.attempt(ctx, d)
+		now := a.At.Add(a.Duration)
+		d.Attempts++
+		d.LastStatusCode = a.StatusCode
+		d.LastError = a.Error
+		d.UpdatedAt = now
+		switch {
+		case a.Error == "" && a.StatusCode >= 200 && a.StatusCode < 300:
+			d.Status = DeliveryDelivered
+			d.DeliveredAt = now
+			res.Delivered++
+		case gone || d.Attempts >= uc.maxAttempts:
+			d.Status = DeliveryFailed
+			res.Failed++
+		default:
+			d.NextAttemptAt = now.Add(uc.backoff(d.Attempts))
+			res.Retried++
+		}
+		_ = uc.repo.RecordAttempt(ctx, d, a)
+	}
+	return res, nil
+}
+
+// attempt sends d once; gone reports that its subscription was deleted, so retrying is pointless.
+func (uc *DeliverWebhooks) attempt(ctx context.Context, d *WebhookDelivery) (a WebhookAttempt, gone bool) {
+	a = WebhookAttempt{DeliveryID: d.ID, At: time.Now()}
+	sub, err := uc.repo.GetSubscription(ctx, d.SubscriptionID)
+	if err != nil {
+		a.Error = "subscription unavailable: " + err.Error()
+		return a, errors.Is(err, ErrWebhookNotFound)
+	}
+	code, err := uc.sender.Send(ctx, sub.URL, sub.Secret, *d)
+	a.Duration = time.Since(a.At)
+	a.StatusCode = code
+	switch {
+	case err != nil:
+		a.Error = err.Error()
+	case code < 200 || code >= 300:
+		a.Error = fmt.Sprintf("unexpected status %d", code)
+	}
+	return a, false
+}
+
+// backoff is the delay after the given number of failed attempts.
+func (uc *DeliverWebhooks) backoff(attempts int) time.Duration {
+	d := uc.backoffBase
+	for i := 1; i < attempts && d < uc.backoffMax; i++ {
+		d *= 2
+	}
+	return min(d, uc.backoffMax)
+}
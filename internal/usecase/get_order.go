@@ -0,0 +1,31 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrNotFound = errors.New("order not found")
+
+// GetOrder loads a single order and applies the read policy for the caller.
+type GetOrder struct {
+	repo OrderRepo
+}
+
+func NewGetOrder(repo OrderRepo) *GetOrder {
+	return &GetOrder{repo: repo}
+}
+
+func (uc *GetOrder) Execute(ctx context.Context, id string) (*OrderRecord, error) {
+	rec, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rec == nil {
+		return nil, ErrNotFound
+	}
+	if err := authorizeRead(ctx, rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
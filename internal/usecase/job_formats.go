@@ -0,0 +1,299 @@
+package usecase
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Import rows have the columns user_id, amount_cents, currency, items_json and idempotency_key
+// (only amount_cents, currency and items_json are required; user_id defaults to an end user's own).
+// CSV files start with a header row naming them in any order; NDJSON files hold one object per line
+// with the same keys. Other columns are ignored, so an export can be imported again.
+//
+// Exports write orderColumns. CSV and NDJSON use RFC 3339 UTC timestamps; Parquet files use UTC
+// millisecond timestamps, a JSON logical type for items_json and one row group per export page.
+
+var errBadRow = errors.New("malformed row")
+
+// importRow is one data row; Err is set when the row could not be read into an order.
+type importRow struct {
+	Line int // line in the file, 1-based
+	In   CreateOrderInput
+	Err  error
+}
+
+type rowReader interface {
+	// next returns io.EOF after the last row; other errors end the job.
+	next() (importRow, error)
+}
+
+func newRowReader(format string, r io.Reader) (rowReader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVRows(r)
+	case FormatNDJSON:
+		sc := bufio.NewScanner(r)
+		sc.Buffer(make([]byte, 64<<10), 1<<20)
+		return &ndjsonRows{sc: sc}, nil
+	}
+	return nil, ErrInvalidJob
+}
+
+type csvRows struct {
+	r   *csv.Reader
+	col map[string]int
+}
+
+func newCSVRows(r io.Reader) (*csvRows, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("csv header: %w", err)
+	}
+	col := map[string]int{}
+	for i, name := range header {
+		col[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range []string{"amount_cents", "currency", "items_json"} {
+		if _, ok := col[name]; !ok {
+			return nil, fmt.Errorf("csv header: missing column %s", name)
+		}
+	}
+	return &csvRows{r: cr, col: col}, nil
+}
+
+func (c *csvRows) next() (importRow, error) {
+	rec, err := c.r.Read()
+	var perr *csv.ParseError
+	switch {
+	case errors.As(err, &perr):
+		return importRow{Line: perr.Line, Err: fmt.Errorf("%w: %v", errBadRow, perr.Err)}, nil
+	case err != nil:
+		return importRow{}, err
+	}
+	line, _ := c.r.FieldPos(0)
+	field := func(name string) string {
+		if i, ok := c.col[name]; ok && i < len(rec) {
+			return rec[i]
+		}
+		return ""
+	}
+	row := importRow{Line: line, In: CreateOrderInput{
+		UserID:         field("user_id"),
+		Currency:       field("currency"),
+		ItemsJSON:      field("items_json"),
+		IdempotencyKey: field("idempotency_key"),
+	}}
+	if row.In.AmountCents, err = strconv.ParseInt(strings.TrimSpace(field("amount_cents")), 10, 64); err != nil {
+		row.Err = fmt.Errorf("%w: amount_cents is not an integer", errBadRow)
+	}
+	return row, nil
+}
+
+type ndjsonRows struct {
+	sc   *bufio.Scanner
+	line int
+}
+
+type ndjsonRow struct {
+	UserID         string `json:"user_id"`
+	AmountCents    int64  `json:"amount_cents"`
+	Currency       string `json:"currency"`
+	ItemsJSON      string `json:"items_json"`
+	IdempotencyKey string `json:"idempotency_key"`
+}
+
+func (n *ndjsonRows) next() (importRow, error) {
+	for n.sc.Scan() {
+		n.line++
+		b := bytes.TrimSpace(n.sc.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+		row := importRow{Line: n.line}
+		var v ndjsonRow
+		if err := json.Unmarshal(b, &v); err != nil {
+			row.Err = fmt.Errorf("%w: %v", errBadRow, err)
+			return row, nil
+		}
+		row.In = CreateOrderInput{UserID: v.UserID, AmountCents: v.AmountCents, Currency: v.Currency,
+			ItemsJSON: v.ItemsJSON, IdempotencyKey: v.IdempotencyKey}
+		return row, nil
+	}
+	if err := n.sc.Err(); err != nil {
+		return importRow{}, err
+	}
+	return importRow{}, io.EOF
+}
+
+// orderColumns are the exported fields, in output order (parquetOrder follows it).
+var orderColumns = []struct {
+	Name     string
+	Optional bool
+}{
+	{Name: "id"},
+	{Name: "user_id"},
+	{Name: "tenant_id", Optional: true},
+	{Name: "status"},
+	{Name: "amount_cents"},
+	{Name: "currency"},
+	{Name: "items_json"},
+	{Name: "idempotency_key", Optional: true},
+	{Name: "failure_reason", Optional: true},
+	{Name: "created_at"},
+	{Name: "updated_at"},
+}
+
+// orderValues returns rec's values in orderColumns order: strings, int64 for amount_cents and
+// time.Time for the timestamps.
+func orderValues(rec *OrderRecord) []any {
+	return []any{rec.ID, rec.UserID, rec.TenantID, rec.Status, rec.AmountCents, rec.Currency, rec.ItemsJSON,
+		rec.IdempotencyKey, rec.FailureReason, rec.CreatedAt, rec.UpdatedAt}
+}
+
+type rowWriter interface {
+	write(rec *OrderRecord) error
+	// flush writes buffered rows (and a Parquet footer) after the last row; the underlying writer
+	// stays open.
+	flush() error
+}
+
+func newRowWriter(format string, w io.Writer, rowGroup int) (rowWriter, error) {
+	bw := bufio.NewWriter(w)
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(bw)
+		header := make([]string, len(orderColumns))
+		for i, c := range orderColumns {
+			header[i] = c.Name
+		}
+		if err := cw.Write(header); err != nil {
+			return nil, err
+		}
+		return &csvWriter{w: cw, bw: bw}, nil
+	case FormatNDJSON:
+		return &ndjsonWriter{enc: json.NewEncoder(bw), bw: bw}, nil
+	case FormatParquet:
+		return newParquetWriter(bw, rowGroup), nil
+	}
+	return nil, ErrInvalidJob
+}
+
+func textValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
+
+type csvWriter struct {
+	w   *csv.Writer
+	bw  *bufio.Writer
+	row []string
+}
+
+func (c *csvWriter) write(rec *OrderRecord) error {
+	c.row = c.row[:0]
+	for _, v := range orderValues(rec) {
+		c.row = append(c.row, textValue(v))
+	}
+	return c.w.Write(c.row)
+}
+
+func (c *csvWriter) flush() error {
+	c.w.Flush()
+	if err := c.w.Error(); err != nil {
+		return err
+	}
+	return c.bw.Flush()
+}
+
+type ndjsonWriter struct {
+	enc *json.Encoder
+	bw  *bufio.Writer
+}
+
+func (n *ndjsonWriter) write(rec *OrderRecord) error {
+	vals := orderValues(rec)
+	obj := make(map[string]any, len(vals))
+	for i, v := range vals {
+		if s, ok := v.(string); ok && s == "" && orderColumns[i].Optional {
+			continue
+		}
+		if t, ok := v.(time.Time); ok {
+			v = textValue(t)
+		}
+		obj[orderColumns[i].Name] = v
+	}
+	return n.enc.Encode(obj)
+}
+
+func (n *ndjsonWriter) flush() error { return n.bw.Flush() }
+
+// parquetOrder is one exported row; fields follow orderColumns. Optional columns are null when empty.
+type parquetOrder struct {
+	ID             string    `parquet:"id"`
+	UserID         string    `parquet:"user_id"`
+	TenantID       string    `parquet:"tenant_id,optional"`
+	Status         string    `parquet:"status"`
+	AmountCents    int64     `parquet:"amount_cents"`
+	Currency       string    `parquet:"currency"`
+	ItemsJSON      string    `parquet:"items_json,json"`
+	IdempotencyKey string    `parquet:"idempotency_key,optional"`
+	FailureReason  string    `parquet:"failure_reason,optional"`
+	CreatedAt      time.Time `parquet:"created_at,timestamp(millisecond)"`
+	UpdatedAt      time.Time `parquet:"updated_at,timestamp(millisecond)"`
+}
+
+// parquetWriter writes a Parquet file with one row group per rowGroup rows.
+type parquetWriter struct {
+	w    *parquet.GenericWriter[parquetOrder]
+	bw   *bufio.Writer
+	size int
+	n    int
+	row  [1]parquetOrder
+}
+
+func newParquetWriter(bw *bufio.Writer, rowGroup int) *parquetWriter {
+	return &parquetWriter{w: parquet.NewGenericWriter[parquetOrder](bw), bw: bw, size: max(rowGroup, 1)}
+}
+
+func (p *parquetWriter) write(rec *OrderRecord) error {
+	p.row[0] = parquetOrder{ID: rec.ID, UserID: rec.UserID, TenantID: rec.TenantID, Status: rec.Status,
+		AmountCents: rec.AmountCents, Currency: rec.Currency, ItemsJSON: rec.ItemsJSON,
+		IdempotencyKey: rec.IdempotencyKey, FailureReason: rec.FailureReason,
+		CreatedAt: rec.CreatedAt.UTC(), UpdatedAt: rec.UpdatedAt.UTC()}
+	if _, err := p.w.Write(p.row[:]); err != nil {
+		return err
+	}
+	if p.n++; p.n >= p.size {
+		p.n = 0
+		return p.w.Flush() // ends the row group
+	}
+	return nil
+}
+
+// flush writes the last row group and the footer; nothing can be written after it.
+func (p *parquetWriter) flush() error {
+	if err := p.w.Close(); err != nil {
+		return err
+	}
+	return p.bw.Flush()
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidListQuery: missing user_id for a service caller, or a malformed page token.
+var ErrInvalidListQuery = errors.New("invalid list orders query")
+
+// ListOrders pages through one user's orders, newest first.
+type ListOrders struct {
+	repo OrderListRepo
+}
+
+func NewListOrders(repo OrderListRepo) *ListOrders {
+	return &ListOrders{repo: repo}
+}
+
+type ListOrdersInput struct {
+	UserID    string // defaults to the caller for end-user tokens; required otherwise
+	Status    string // optional filter
+	PageSize  int    // 1..100, default 20
+	PageToken string // from the previous page's NextPageToken
+}
+
+type ListOrdersOutput struct {
+	Orders        []OrderRecord
+	NextPageToken string // empty on the last page
+}
+
+// Execute lists the orders the caller may read: end users only their own, other callers need
+// orders.read.all. Tenant-bound callers only see their tenant's orders.
+func (uc *ListOrders) Execute(ctx context.Context, in ListOrdersInput) (ListOrdersOutput, error) {
+	p, ok := PrincipalFrom(ctx)
+	if !ok {
+		return ListOrdersOutput{}, ErrForbidden
+	}
+	switch {
+	case p.IsEndUser():
+		if in.UserID != "" && in.UserID != p.Subject && !p.Has(PermOrdersReadAll) {
+			return ListOrdersOutput{}, ErrForbidden
+		}
+		if in.UserID == "" {
+			in.UserID = p.Subject
+		}
+	case !p.Has(PermOrdersReadAll):
+		return ListOrdersOutput{}, ErrForbidden
+	case in.UserID == "":
+		return ListOrdersOutput{}, ErrInvalidListQuery
+	}
+	if in.PageSize <= 0 || in.PageSize > 100 {
+		in.PageSize = 20
+	}
+	cursor, err := decodePageToken(in.PageToken)
+	if err != nil {
+		return ListOrdersOutput{}, ErrInvalidListQuery
+	}
+
+	recs, err := uc.repo.ListByUser(ctx, in.UserID, in.Status, cursor, in.PageSize)
+	if err != nil {
+		return ListOrdersOutput{}, err
+	}
+	var out ListOrdersOutput
+	for _, rec := range recs {
+		if authorizeRead(ctx, &rec) == nil {
+			out.Orders = append(out.Orders, rec)
+		}
+	}
+	if n := len(recs); n == in.PageSize {
+		out.NextPageToken = encodePageToken(OrderCursor{CreatedAt: recs[n-1].CreatedAt, ID: recs[n-1].ID})
+	}
+	return out, nil
+}
+
+// Page tokens are opaque to clients: base64url("<created_at unix ns>:<id>").
+func encodePageToken(c OrderCursor) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(c.CreatedAt.UnixNano(), 10) + ":" + c.ID))
+}
+
+func decodePageToken(tok string) (OrderCursor, error) {
+	if tok == "" {
+		return OrderCursor{}, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(tok)
+	if err != nil {
+		return OrderCursor{}, err
+	}
+	ns, id, ok := strings.Cut(string(raw), ":")
+	n, err := strconv.ParseInt(ns, 10, 64)
+	if !ok || err != nil || id == "" {
+		return OrderCursor{}, ErrInvalidListQuery
+	}
+	return OrderCursor{CreatedAt: time.Unix(0, n), ID: id}, nil
+}
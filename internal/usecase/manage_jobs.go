@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+
+	"github.com/google/uuid"
+)
+
+// Job kinds.
+const (
+	JobImport = "import"
+	JobExport = "export"
+)
+
+// Job statuses.
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobCompleted = "completed" // every row handled; import rows may still have failed (see the error report)
+	JobFailed    = "failed"    // the job as a whole could not run; see Job.Error
+)
+
+// Job file formats. Imports read csv or ndjson; exports also write parquet (see job_formats.go).
+const (
+	FormatCSV     = "csv"
+	FormatNDJSON  = "ndjson"
+	FormatParquet = "parquet"
+)
+
+var (
+	ErrInvalidJob     = errors.New("invalid job")
+	ErrJobNotFound    = errors.New("job not found")
+	ErrJobNotFinished = errors.New("job has not completed")
+	ErrUploadTooLarge = errors.New("upload too large")
+)
+
+// ManageJobs is the client-facing side of bulk jobs: submitting imports and exports, their status
+// and their output. Everything is scoped to the calling client.
+type ManageJobs struct {
+	repo      JobRepo
+	files     JobFiles
+	maxUpload int64
+}
+
+type ManageJobsOption func(*ManageJobs)
+
+// WithMaxUpload caps the size of an import file in bytes (default 64 MiB).
+func WithMaxUpload(n int64) ManageJobsOption { return func(uc *ManageJobs) { uc.maxUpload = n } }
+
+func NewManageJobs(repo JobRepo, files JobFiles, opts ...ManageJobsOption) *ManageJobs {
+	uc := &ManageJobs{repo: repo, files: files, maxUpload: 64 << 20}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// SubmitImport stores the uploaded file and queues a job that creates one order per row, as the
+// caller, with the same rules as CreateOrder.
+func (uc *ManageJobs) SubmitImport(ctx context.Context, format string, file io.Reader) (*Job, error) {
+	p, ok := PrincipalFrom(ctx)
+	if !ok {
+		return nil, ErrForbidden
+	}
+	if format != FormatCSV && format != FormatNDJSON {
+		return nil, ErrInvalidJob
+	}
+	j := &Job{ID: uuid.NewString(), Kind: JobImport, Format: format, Status: JobQueued, Owner: p}
+
+	w, err := uc.files.Create(jobInputFile(j))
+	if err != nil {
+		return nil, err
+	}
+	n, err := io.Copy(w, io.LimitReader(file, uc.maxUpload+1))
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	switch {
+	case err != nil:
+		return nil, err
+	case n > uc.maxUpload:
+		return nil, ErrUploadTooLarge
+	case n == 0:
+		return nil, ErrInvalidJob
+	}
+
+	if err := uc.repo.CreateJob(ctx, j); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// SubmitExport queues a job writing the orders matching f, limited to what the caller may read.
+func (uc *ManageJobs) SubmitExport(ctx context.Context, format string, f OrderFilter) (*Job, error) {
+	p, ok := PrincipalFrom(ctx)
+	if !ok {
+		return nil, ErrForbidden
+	}
+	if format != FormatCSV && format != FormatNDJSON && format != FormatParquet {
+		return nil, ErrInvalidJob
+	}
+	if !f.CreatedFrom.IsZero() && !f.CreatedTo.IsZero() && !f.CreatedFrom.Before(f.CreatedTo) {
+		return nil, ErrInvalidJob
+	}
+	if err := authorizeExport(ctx, &f); err != nil {
+		return nil, err
+	}
+	j := &Job{ID: uuid.NewString(), Kind: JobExport, Format: format, Status: JobQueued, Owner: p, Filter: f}
+	if err := uc.repo.CreateJob(ctx, j); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func (uc *ManageJobs) Get(ctx context.Context, id string) (*Job, error) {
+	p, ok := PrincipalFrom(ctx)
+	if !ok {
+		return nil, ErrForbidden
+	}
+	return uc.repo.GetJob(ctx, p.ClientID, id)
+}
+
+// List returns the caller's newest jobs (limit 1..100, default 50).
+func (uc *ManageJobs) List(ctx context.Context, limit int) ([]Job, error) {
+	p, ok := PrincipalFrom(ctx)
+	if !ok {
+		return nil, ErrForbidden
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	return uc.repo.ListJobs(ctx, p.ClientID, limit)
+}
+
+// Result opens the output of a completed export.
+func (uc *ManageJobs) Result(ctx context.Context, id string) (*Job, io.ReadCloser, error) {
+	j, err := uc.Get(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if j.Kind != JobExport {
+		return nil, nil, ErrJobNotFound
+	}
+	if j.Status != JobCompleted {
+		return j, nil, ErrJobNotFinished
+	}
+	return uc.open(j, jobOutputFile(j))
+}
+
+// ErrorReport opens the rejected rows of an import, one JSON object per line; it grows while the
+// job runs and is empty when every row was created.
+func (uc *ManageJobs) ErrorReport(ctx context.Context, id string) (*Job, io.ReadCloser, error) {
+	j, err := uc.Get(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if j.Kind != JobImport {
+		return nil, nil, ErrJobNotFound
+	}
+	if j.Status == JobQueued {
+		return j, nil, ErrJobNotFinished
+	}
+	return uc.open(j, jobErrorFile(j))
+}
+
+func (uc *ManageJobs) open(j *Job, name string) (*Job, io.ReadCloser, error) {
+	rc, err := uc.files.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		err = ErrJobNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return j, rc, nil
+}
+
+// Progress is the share of rows handled, 0..1; 0 while the total is unknown.
+func (j *Job) Progress() float64 {
+	switch {
+	case j.Status == JobCompleted:
+		return 1
+	case j.Total <= 0:
+		return 0
+	}
+	return min(float64(j.Processed)/float64(j.Total), 1)
+}
+
+func jobInputFile(j *Job) string { return j.ID + ".input." + j.Format }
+func jobErrorFile(j *Job) string { return j.ID + ".errors.ndjson" }
+
+func jobOutputFile(j *Job) string { return j.ID + "." + j.Format }
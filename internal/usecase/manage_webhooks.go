@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Webhook event types (one per final order status).
+const (
+	WebhookOrderConfirmed = "order.confirmed"
+	WebhookOrderFailed    = "order.failed"
+)
+
+// Webhook delivery statuses.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed" // retries exhausted; can be redelivered
+)
+
+var WebhookEvents = []string{WebhookOrderConfirmed, WebhookOrderFailed}
+
+var (
+	ErrInvalidWebhook  = errors.New("invalid webhook subscription")
+	ErrWebhookNotFound = errors.New("webhook not found")
+)
+
+// ManageWebhooks is the client-facing side of webhooks: subscriptions, delivery logs and redelivery.
+// Everything is scoped to the calling client.
+type ManageWebhooks struct {
+	repo         WebhookRepo
+	allowHTTP    bool
+	allowPrivate bool
+}
+
+type ManageWebhooksOption func(*ManageWebhooks)
+
+// WithInsecureWebhookURLs also accepts http:// callback URLs (local development only).
+func WithInsecureWebhookURLs(on bool) ManageWebhooksOption {
+	return func(uc *ManageWebhooks) { uc.allowHTTP = on }
+}
+
+// WithPrivateWebhookHosts also accepts IP-literal and localhost callback hosts (local development only).
+// The sender separately refuses to dial private addresses a host name resolves to.
+func WithPrivateWebhookHosts(on bool) ManageWebhooksOption {
+	return func(uc *ManageWebhooks) { uc.allowPrivate = on }
+}
+
+func NewManageWebhooks(repo WebhookRepo, opts ...ManageWebhooksOption) *ManageWebhooks {
+	uc := &ManageWebhooks{repo: repo}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// Subscribe registers rawURL for events and returns the subscription with its signing secret.
+func (uc *ManageWebhooks) Subscribe(ctx context.Context, rawURL string, events []string) (*WebhookSubscription, error) {
+	p, ok := PrincipalFrom(ctx)
+	if !ok {
+		return nil, ErrForbidden
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && !(uc.allowHTTP && u.Scheme == "http")) {
+		return nil, ErrInvalidWebhook
+	}
+	if !uc.allowPrivate && !publicHost(u.Hostname()) {
+		return nil, ErrInvalidWebhook
+	}
+	if len(events) == 0 {
+		return nil, ErrInvalidWebhook
+	}
+	var evs []string
+	for _, ev := range events {
+		if !slices.Contains(WebhookEvents, ev) {
+			return nil, ErrInvalidWebhook
+		}
+		if !slices.Contains(evs, ev) {
+			evs = append(evs, ev)
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	s := &WebhookSubscription{
+		ID:       uuid.NewString(),
+		ClientID: p.ClientID,
+		URL:      u.String(),
+		Secret:   "whsec_" + base64.RawURLEncoding.EncodeToString(secret),
+		Events:   evs,
+	}
+	if err := uc.repo.CreateSubscription(ctx, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// publicHost rejects IP literals (10.0.0.1, [::1], 169.254.169.254) and localhost names; a subscriber
+// must register a DNS name.
+func publicHost(host string) bool {
+	if _, err := netip.ParseAddr(host); err == nil {
+		return false
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	return host != "localhost" && !strings.HasSuffix(host, ".localhost")
+}
+
+// List returns the caller's subscriptions without their secrets.
+func (uc *ManageWebhooks) List(ctx context.Context) ([]WebhookSubscription, error) {
+	p, ok := PrincipalFrom(ctx)
+	if !ok {
+		return nil, ErrForbidden
+	}
+	subs, err := uc.repo.ListSubscriptions(ctx, p.ClientID)
+	for i := range subs {
+		subs[i].Secret = ""
+	}
+	return subs, err
+}
+
+// Unsubscribe deletes a subscription; its pending deliveries fail on their next attempt.
+func (uc *ManageWebhooks) Unsubscribe(ctx context.Context, id string) error {
+	p, ok := PrincipalFrom(ctx)
+	if !ok {
+		return ErrForbidden
+	}
+	found, err := uc.repo.DeleteSubscription(ctx, p.ClientID, id)
+	if err == nil && !found {
+		err = ErrWebhookNotFound
+	}
+	return err
+}
+
+// Deliveries lists the caller's newest deliveries (limit 1..100, default 50), optionally by status.
+func (uc *ManageWebhooks) Deliveries(ctx context.Context, status string, limit int) ([]WebhookDelivery, error) {
+	p, ok := PrincipalFrom(ctx)
+	if !ok {
+		return nil, ErrForbidden
+	}
+	if status != "" && status != DeliveryPending && status != DeliveryDelivered && status != DeliveryFailed {
+		return nil, ErrValidation
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	return uc.repo.ListDeliveries(ctx, p.ClientID, status, limit)
+}
+
+// Delivery returns one of the caller's deliveries with its attempt log, oldest first.
+func (uc *ManageWebhooks) Delivery(ctx context.Context, id string) (*WebhookDelivery, []WebhookAttempt, error) {
+	p, ok := PrincipalFrom(ctx)
+	if !ok {
+		return nil, nil, ErrForbidden
+	}
+	d, err := uc.repo.GetDelivery(ctx, p.ClientID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	attempts, err := uc.repo.ListAttempts(ctx, d.ID)
+	return d, attempts, err
+}
+
+// Redeliver queues a delivery again, whatever its state; the event id stays the same so receivers can
+// deduplicate.
+func (uc *ManageWebhooks) Redeliver(ctx context.Context, id string) error {
+	p, ok := PrincipalFrom(ctx)
+	if !ok {
+		return ErrForbidden
+	}
+	found, err := uc.repo.Redeliver(ctx, p.ClientID, id)
+	if err == nil && !found {
+		err = ErrWebhookNotFound
+	}
+	return err
+}
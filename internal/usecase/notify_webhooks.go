@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+
+	domain "github.com/aq2208/gorder-api/internal/entity"
+	"github.com/google/uuid"
+)
+
+// WebhookPayload is the JSON body POSTed to subscribers.
+type WebhookPayload struct {
+	ID        string             `json:"id"`
+	Type      string             `json:"type"`
+	CreatedAt time.Time          `json:"created_at"`
+	Data      WebhookOrderChange `json:"data"`
+}
+
+type WebhookOrderChange struct {
+	OrderID       string `json:"order_id"`
+	UserID        string `json:"user_id"`
+	Status        string `json:"status"`
+	AmountCents   int64  `json:"amount_cents"`
+	Currency      string `json:"currency"`
+	FailureReason string `json:"failure_reason,omitempty"`
+}
+
+// NotifyWebhooks queues deliveries for an order's status change to the subscriptions of the client
+// that created the order. Delivery itself is asynchronous (DeliverWebhooks).
+type NotifyWebhooks struct {
+	orders OrderRepo
+	repo   WebhookRepo
+}
+
+func NewNotifyWebhooks(orders OrderRepo, repo WebhookRepo) *NotifyWebhooks {
+	return &NotifyWebhooks{orders: orders, repo: repo}
+}
+
+// Execute queues the deliveries for orderID reaching status. Non-final statuses, orders without a
+// client and clients without a matching subscription are no-ops.
+func (uc *NotifyWebhooks) Execute(ctx context.Context, orderID, status string) error {
+	event := webhookEventFor(status)
+	if event == "" {
+		return nil
+	}
+	rec, err := uc.orders.GetByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if rec.ClientID == "" {
+		return nil
+	}
+	subs, err := uc.repo.ListSubscriptions(ctx, rec.ClientID)
+	if err != nil {
+		return err
+	}
+
+	// one event per order and final status: retries of the same change map to the same id
+	eventID := orderID + ":" + event
+	payload, err := json.Marshal(WebhookPayload{
+		ID:        eventID,
+		Type:      event,
+		CreatedAt: time.Now().UTC(),
+		Data: WebhookOrderChange{
+			OrderID:       rec.ID,
+			UserID:        rec.UserID,
+			Status:        status,
+			AmountCents:   rec.AmountCents,
+			Currency:      rec.Currency,
+			FailureReason: rec.FailureReason,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	var ds []WebhookDelivery
+	for _, s := range subs {
+		if !slices.Contains(s.Events, event) {
+			continue
+		}
+		ds = append(ds, WebhookDelivery{
+			ID:             uuid.NewString(),
+			SubscriptionID: s.ID,
+			ClientID:       s.ClientID,
+			EventID:        eventID,
+			EventType:      event,
+			OrderID:        orderID,
+			Payload:        payload,
+			Status:         DeliveryPending,
+		})
+	}
+	if len(ds) == 0 {
+		return nil
+	}
+	return uc.repo.EnqueueDeliveries(ctx, ds)
+}
+
+func webhookEventFor(status string) string {
+	switch domain.Status(status) {
+	case domain.StatusConfirmed:
+		return WebhookOrderConfirmed
+	case domain.StatusFailed:
+		return WebhookOrderFailed
+	}
+	return ""
+}
@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrPreconditionFailed means the caller's If-Match (or etag) names a version the order is no longer at.
+	ErrPreconditionFailed = errors.New("order has changed (etag mismatch)")
+	// ErrVersionConflict means other writers kept moving the version; the update was not applied.
+	ErrVersionConflict = errors.New("order was modified concurrently")
+)
+
+// casAttempts bounds the re-read-and-retry loop; only a burst of writes to one order exhausts it.
+const casAttempts = 5
+
+// ETag is the order's entity tag: its version, quoted as in an HTTP ETag header.
+func (rec *OrderRecord) ETag() string { return `"` + strconv.FormatInt(rec.Version, 10) + `"` }
+
+// VersionMatcher reports whether a version satisfies a caller's precondition.
+type VersionMatcher func(version int64) bool
+
+// MatchETags parses an If-Match list: "*" or comma-separated entity tags. Weak tags never match, since
+// If-Match uses strong comparison. An empty list is no precondition and returns nil.
+func MatchETags(list string) VersionMatcher {
+	list = strings.TrimSpace(list)
+	switch list {
+	case "":
+		return nil
+	case "*":
+		return func(int64) bool { return true }
+	}
+	want := map[int64]bool{}
+	for _, tag := range strings.Split(list, ",") {
+		tag = strings.TrimSpace(tag)
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue // weak or malformed
+		}
+		if v, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64); err == nil {
+			want[v] = true
+		}
+	}
+	return func(v int64) bool { return want[v] }
+}
+
+// updateStatus moves id to toStatus by compare-and-swap, re-reading the order when another write bumps
+// the version first. check sees the order as it is now and may refuse the change. An order already at
+// toStatus is left alone, so redelivered events do not bump the version (and with it the ETag).
+func updateStatus(ctx context.Context, repo OrderRepo, id, toStatus string, check func(*OrderRecord) error) error {
+	for range casAttempts {
+		rec, err := repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if check != nil {
+			if err := check(rec); err != nil {
+				return err
+			}
+		}
+		if rec.Status == toStatus {
+			return nil
+		}
+		ok, err := repo.UpdateStatus(ctx, id, rec.Version, toStatus)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+	}
+	return ErrVersionConflict
+}
+
+// ApplyStatus records a status decided elsewhere (order-gw's outcome) whatever the order's current
+// status; a concurrent write is re-read and applied over, never lost in between.
+func ApplyStatus(ctx context.Context, repo OrderRepo, id, toStatus string) error {
+	return updateStatus(ctx, repo, id, toStatus, nil)
+}
@@ -3,6 +3,8 @@ package usecase
 import (
 	"context"
 	"errors"
+	"io"
+	"time"
 )
 
 // OrderRecord - Persistence shape (kept out of domain).
@@ -10,6 +12,12 @@ type OrderRecord struct {
 	ID, UserID, Status, ItemsJSON, Currency string
 	AmountCents                             int64
 	IdempotencyKey                          string
+	TenantID                                string
+	ClientID                                string // API client that created the order (webhook routing)
+	DispatchAttempts                        int    // re-dispatches by the reaper
+	FailureReason                           string // why the order ended FAILED, if known
+	Version                                 int64  // bumped by every update; writers compare-and-swap on it
+	CreatedAt, UpdatedAt                    time.Time
 }
 
 var ErrInvalidAmount = errors.New("invalid amount")
@@ -22,11 +30,217 @@ func (rec *OrderRecord) Validate() error {
 }
 
 type OrderRepo interface {
+	// Create stores a new order. ErrDuplicate means the caller already has an order under
+	// o's idempotency key (unique per client, user and key).
 	Create(ctx context.Context, o *OrderRecord) error
-	UpdateStatus(ctx context.Context, id, toStatus string) error
-	UpdateStatusIf(ctx context.Context, id string, fromStatus, toStatus string) (bool, error)
+	// UpdateStatus sets the status only if the order is still at version, and bumps the version. False
+	// means another write got there first (or the order does not exist): re-read and decide again.
+	UpdateStatus(ctx context.Context, id string, version int64, toStatus string) (bool, error)
 	GetByID(ctx context.Context, id string) (*OrderRecord, error)
-	GetByUserAndIdemKey(ctx context.Context, userID, idemKey string) (*OrderRecord, error)
+	GetByIdemKey(ctx context.Context, clientID, userID, idemKey string) (*OrderRecord, error)
+}
+
+// OrderBatchRepo adds an all-or-nothing insert of several orders (one transaction).
+type OrderBatchRepo interface {
+	OrderRepo
+	CreateBatch(ctx context.Context, recs []*OrderRecord) error
+}
+
+// StuckOrderRepo is what the reaper needs on top of OrderRepo. The version argument is the Version
+// the caller read; updates only apply if the order is still PROCESSING at that version, so concurrent
+// reapers or a late status change win over a stale read.
+type StuckOrderRepo interface {
+	// ListStuck returns orders in status whose last update is older than olderThan, oldest first.
+	ListStuck(ctx context.Context, status string, olderThan time.Duration, limit int) ([]OrderRecord, error)
+	MarkRedispatched(ctx context.Context, id string, version int64) (bool, error)
+	FailStuck(ctx context.Context, id string, version int64, reason string) (bool, error)
+}
+
+// LeaderLease elects one instance among replicas for singleton background jobs.
+type LeaderLease interface {
+	// Acquire takes the lease or renews it if already held; false means another instance holds it.
+	Acquire(ctx context.Context) (bool, error)
+	Release(ctx context.Context) error
+}
+
+// OrderCursor is a keyset position in (created_at, id) order; the zero value starts at the beginning.
+type OrderCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// ReconcileRepo pages through our orders for the reconciliation job.
+type ReconcileRepo interface {
+	// ListRecent returns orders created within window and not updated for at least settle
+	// (younger ones may still be in flight), after the cursor, in (created_at, id) order.
+	ListRecent(ctx context.Context, window, settle time.Duration, after OrderCursor, limit int) ([]OrderRecord, error)
+}
+
+// OrderListRepo lists one user's orders newest first, keyset-paged by (created_at, id).
+type OrderListRepo interface {
+	// ListByUser returns orders of userID (only those in status, if set) before the cursor; the zero
+	// cursor starts at the newest.
+	ListByUser(ctx context.Context, userID, status string, before OrderCursor, limit int) ([]OrderRecord, error)
+}
+
+// OrderFilter selects orders for an export. Zero fields do not filter; CreatedTo is exclusive.
+type OrderFilter struct {
+	UserID, TenantID, Status string
+	CreatedFrom, CreatedTo   time.Time
+}
+
+// OrderExportRepo pages through the orders matching a filter for export jobs.
+type OrderExportRepo interface {
+	// ExportOrders returns orders matching f after the cursor, in (created_at, id) order.
+	ExportOrders(ctx context.Context, f OrderFilter, after OrderCursor, limit int) ([]OrderRecord, error)
+}
+
+// GatewayOrder is order-gw's record of an order, its status already translated to ours
+// (GatewayStatusUnknown when there is no safe translation). RawStatus is order-gw's own value.
+type GatewayOrder struct {
+	OrderID, Status, Currency string
+	RawStatus                 string
+	AmountCents               int64
+}
+
+// GatewayStatusUnknown is a GatewayOrder status that maps to none of ours.
+const GatewayStatusUnknown = "UNKNOWN"
+
+// OrderGatewayReader reads order-gw's records.
+type OrderGatewayReader interface {
+	// ListOrders returns the orders order-gw knows among ids, keyed by id.
+	ListOrders(ctx context.Context, ids []string) (map[string]GatewayOrder, error)
+}
+
+// DiscrepancyRepo stores the reconciliation report.
+type DiscrepancyRepo interface {
+	InsertDiscrepancies(ctx context.Context, ds []Discrepancy) error
+}
+
+// OrderEvent is one applied status change. IDs increase per order ("<unix ms>-<seq>", as Redis stream
+// IDs) and are what SSE clients send back as Last-Event-ID.
+type OrderEvent struct {
+	ID      string    `json:"id"`
+	OrderID string    `json:"orderId"`
+	Status  string    `json:"status"`
+	At      time.Time `json:"at"`
+}
+
+// OrderEventPublisher records a status change and fans it out to every replica.
+type OrderEventPublisher interface {
+	PublishStatus(ctx context.Context, orderID, status string) (OrderEvent, error)
+}
+
+// OrderEventStream is the read side: retained history for resume plus live delivery.
+type OrderEventStream interface {
+	OrderEventPublisher
+	// Since returns retained events after lastID (all retained events for "").
+	Since(ctx context.Context, orderID, lastID string) ([]OrderEvent, error)
+	// Subscribe delivers events for orderID published from now on until cancel is called.
+	Subscribe(ctx context.Context, orderID string) (events <-chan OrderEvent, cancel func())
+}
+
+// WebhookSubscription is a client's callback endpoint for a set of event types.
+type WebhookSubscription struct {
+	ID, ClientID, URL string
+	Secret            string // HMAC key for the signature header; only shown when created
+	Events            []string
+	CreatedAt         time.Time
+}
+
+// WebhookDelivery is one event to be POSTed to one subscription, with its retry state.
+type WebhookDelivery struct {
+	ID, SubscriptionID, ClientID string
+	EventID, EventType, OrderID  string // EventID is stable across retries and redeliveries
+	Payload                      []byte
+	Status                       string // pending | delivered | failed
+	Attempts                     int
+	NextAttemptAt                time.Time
+	LastStatusCode               int
+	LastError                    string
+	CreatedAt, UpdatedAt         time.Time
+	DeliveredAt                  time.Time // zero until delivered
+}
+
+// WebhookAttempt is one entry of a delivery's log.
+type WebhookAttempt struct {
+	DeliveryID string
+	StatusCode int // 0 when no response arrived
+	Error      string
+	Duration   time.Duration
+	At         time.Time
+}
+
+// WebhookRepo stores subscriptions, the delivery queue and the delivery logs. Client-facing reads and
+// writes are scoped by clientID, so one client cannot see or touch another's.
+type WebhookRepo interface {
+	CreateSubscription(ctx context.Context, s *WebhookSubscription) error
+	ListSubscriptions(ctx context.Context, clientID string) ([]WebhookSubscription, error)
+	GetSubscription(ctx context.Context, id string) (*WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, clientID, id string) (bool, error)
+
+	// EnqueueDeliveries adds pending deliveries; one already queued for the same subscription and
+	// event id is skipped, so re-processing a status change does not notify twice.
+	EnqueueDeliveries(ctx context.Context, ds []WebhookDelivery) error
+	// ClaimDue takes up to limit pending deliveries whose next attempt is due and pushes their next
+	// attempt lease into the future, so concurrent workers skip them until then.
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]WebhookDelivery, error)
+	// RecordAttempt saves d's new retry state and appends a to its log.
+	RecordAttempt(ctx context.Context, d *WebhookDelivery, a WebhookAttempt) error
+
+	GetDelivery(ctx context.Context, clientID, id string) (*WebhookDelivery, error)
+	// ListDeliveries returns the client's newest deliveries, optionally only those in status.
+	ListDeliveries(ctx context.Context, clientID, status string, limit int) ([]WebhookDelivery, error)
+	ListAttempts(ctx context.Context, deliveryID string) ([]WebhookAttempt, error)
+	// Redeliver puts a delivery back in the queue, due now, with a fresh retry budget.
+	Redeliver(ctx context.Context, clientID, id string) (bool, error)
+}
+
+// WebhookSender POSTs a delivery's payload to url, signed with secret.
+type WebhookSender interface {
+	// Send returns the response status (0 if none arrived); err is set for transport failures only.
+	Send(ctx context.Context, url, secret string, d WebhookDelivery) (statusCode int, err error)
+}
+
+// Job is a bulk import or export run in the background (see RunJobs).
+type Job struct {
+	ID, Kind, Format string
+	Status           string      // queued | running | completed | failed
+	Owner            Principal   // the caller who submitted it; imports create orders as this caller
+	Filter           OrderFilter // exports only
+	Total            int         // rows in the file (imports, once counted) or exported (once completed)
+	Processed        int         // rows handled so far; Succeeded + Failed for imports
+	Succeeded        int
+	Failed           int    // import rows rejected; see the error report
+	Error            string // why the job as a whole failed
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	StartedAt        time.Time // zero until claimed
+	FinishedAt       time.Time // zero until completed or failed
+}
+
+// JobRepo stores jobs and hands queued ones to the runners. Client-facing reads are scoped by
+// clientID.
+type JobRepo interface {
+	CreateJob(ctx context.Context, j *Job) error
+	GetJob(ctx context.Context, clientID, id string) (*Job, error)
+	// ListJobs returns the client's newest jobs.
+	ListJobs(ctx context.Context, clientID string, limit int) ([]Job, error)
+	// ClaimJob marks the oldest queued job, or a running one whose claim expired (its runner died),
+	// running and claimed for lease; nil when there is none.
+	ClaimJob(ctx context.Context, lease time.Duration) (*Job, error)
+	// SaveJob stores j's status and progress and extends its claim by lease.
+	SaveJob(ctx context.Context, j *Job, lease time.Duration) error
+}
+
+// JobFiles keeps job uploads, results and error reports by name.
+type JobFiles interface {
+	// Create opens name for writing, truncating it.
+	Create(name string) (io.WriteCloser, error)
+	// Append opens name for writing at its end, creating it if needed.
+	Append(name string) (io.WriteCloser, error)
+	// Open returns an error matching fs.ErrNotExist when name does not exist.
+	Open(name string) (io.ReadCloser, error)
 }
 
 type OrderCache interface {
@@ -40,6 +254,8 @@ type OutboxRepo interface {
 
 type IdempotencyStore interface {
 	TryLock(ctx context.Context, key string) (bool, error)
+	// Unlock releases a TryLock whose request created nothing, so the key can be retried at once.
+	Unlock(ctx context.Context, key string) error
 	Remember(ctx context.Context, key, value string) error
 	Recall(ctx context.Context, key string) (string, bool, error)
 }
@@ -48,6 +264,12 @@ type OrderQueue interface {
 	PublishCreated(ctx context.Context, msg CreatedMsg) error
 }
 
+// OrderBatchQueue publishes several order.created commands and returns once the broker has
+// confirmed every one of them.
+type OrderBatchQueue interface {
+	PublishCreatedBatch(ctx context.Context, msgs []CreatedMsg) error
+}
+
 type CreatedMsg struct {
 	OrderID  string `json:"orderId"`
 	UserID   string `json:"userId"`
@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func principal(client, subject, tenant string, perms ...string) *Principal {
+	p := &Principal{ClientID: client, Subject: subject, Tenant: tenant, Perms: map[string]struct{}{}}
+	for _, perm := range perms {
+		p.Perms[perm] = struct{}{}
+	}
+	return p
+}
+
+func ctxFor(p *Principal) context.Context {
+	if p == nil {
+		return context.Background()
+	}
+	return WithPrincipal(context.Background(), *p)
+}
+
+func TestAuthorizeRead(t *testing.T) {
+	alice := &OrderRecord{ID: "o1", UserID: "alice", TenantID: "acme"}
+	untenanted := &OrderRecord{ID: "o2", UserID: "alice"}
+
+	for _, tc := range []struct {
+		name string
+		p    *Principal
+		rec  *OrderRecord
+		want error
+	}{
+		{"owner", principal("web", "alice", "acme", PermOrdersRead), alice, nil},
+		{"other user", principal("web", "bob", "acme", PermOrdersRead), alice, ErrNotFound},
+		{"service without read.all", principal("svc", "", "", PermOrdersRead), alice, ErrNotFound},
+		{"service with read.all", principal("svc", "", "", PermOrdersReadAll), alice, nil},
+		{"read.all in another tenant", principal("svc", "", "globex", PermOrdersReadAll), alice, ErrNotFound},
+		{"owner bound to another tenant", principal("web", "alice", "globex"), alice, ErrNotFound},
+		{"tenant-bound caller, untenanted order", principal("web", "alice", "acme"), untenanted, ErrNotFound},
+		{"untenanted caller, untenanted order", principal("web", "alice", ""), untenanted, nil},
+		{"no principal", nil, alice, ErrForbidden},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := authorizeRead(ctxFor(tc.p), tc.rec); !errors.Is(err, tc.want) {
+				t.Fatalf("authorizeRead = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestAuthorizeExport(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		p       *Principal
+		in      OrderFilter
+		want    error
+		wantOut OrderFilter
+	}{
+		{"end user gets own orders", principal("web", "alice", "acme"), OrderFilter{}, nil, OrderFilter{UserID: "alice", TenantID: "acme"}},
+		{"end user asking for another user", principal("web", "alice", ""), OrderFilter{UserID: "bob"}, ErrForbidden, OrderFilter{}},
+		{"tenant cannot be widened", principal("web", "alice", "acme"), OrderFilter{TenantID: "globex"}, nil, OrderFilter{UserID: "alice", TenantID: "acme"}},
+		{"service without read.all", principal("svc", "", "", PermOrdersRead), OrderFilter{}, ErrForbidden, OrderFilter{}},
+		{"service with read.all", principal("svc", "", "", PermOrdersReadAll), OrderFilter{UserID: "bob", Status: "FAILED"}, nil, OrderFilter{UserID: "bob", Status: "FAILED"}},
+		{"no principal", nil, OrderFilter{}, ErrForbidden, OrderFilter{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := tc.in
+			err := authorizeExport(ctxFor(tc.p), &f)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("authorizeExport = %v, want %v", err, tc.want)
+			}
+			if err == nil && f != tc.wantOut {
+				t.Fatalf("filter = %+v, want %+v", f, tc.wantOut)
+			}
+		})
+	}
+}
+
+func TestAuthorizeCreate(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		p          *Principal
+		userID     string
+		want       error
+		wantUser   string
+		wantTenant string
+	}{
+		{"end user, owner defaulted", principal("web", "alice", "acme"), "", nil, "alice", "acme"},
+		{"end user for themselves", principal("web", "alice", ""), "alice", nil, "alice", ""},
+		{"end user for someone else", principal("web", "alice", ""), "bob", ErrForbidden, "", ""},
+		{"service on behalf of a user", principal("svc", "", "acme"), "bob", nil, "bob", "acme"},
+		{"no principal", nil, "alice", ErrForbidden, "", ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			in := CreateOrderInput{UserID: tc.userID, TenantID: "from-body", ClientID: "from-body"}
+			err := authorizeCreate(ctxFor(tc.p), &in)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("authorizeCreate = %v, want %v", err, tc.want)
+			}
+			if err != nil {
+				return
+			}
+			if in.UserID != tc.wantUser || in.TenantID != tc.wantTenant || in.ClientID != tc.p.ClientID {
+				t.Fatalf("input = user %q tenant %q client %q", in.UserID, in.TenantID, in.ClientID)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	domain "github.com/aq2208/gorder-api/internal/entity"
+)
+
+// ReapStuckOrders finds orders that stayed PROCESSING past the SLA (lost publish, dropped message,
+// no status event from order-gw), re-dispatches them a bounded number of times and then fails them.
+// Re-dispatch republishes the original order.created command; order-gw deduplicates on the order id.
+type ReapStuckOrders struct {
+	repo        StuckOrderRepo
+	cache       OrderCache
+	queue       OrderQueue
+	events      OrderEventPublisher // optional
+	webhooks    *NotifyWebhooks     // optional
+	sla         time.Duration
+	maxAttempts int
+	batchSize   int
+}
+
+type ReapOption func(*ReapStuckOrders)
+
+// WithSLA sets how long an order may stay PROCESSING without an update (default 2m).
+func WithSLA(d time.Duration) ReapOption { return func(uc *ReapStuckOrders) { uc.sla = d } }
+
+// WithMaxAttempts sets the re-dispatches before an order is failed (default 3).
+func WithMaxAttempts(n int) ReapOption { return func(uc *ReapStuckOrders) { uc.maxAttempts = n } }
+
+// WithBatchSize caps the orders handled per Execute (default 100).
+func WithBatchSize(n int) ReapOption { return func(uc *ReapStuckOrders) { uc.batchSize = n } }
+
+// WithReapEvents publishes FAILED to order watchers when an order is given up on.
+func WithReapEvents(p OrderEventPublisher) ReapOption {
+	return func(uc *ReapStuckOrders) { uc.events = p }
+}
+
+// WithReapWebhooks notifies webhook subscribers when an order is given up on.
+func WithReapWebhooks(n *NotifyWebhooks) ReapOption {
+	return func(uc *ReapStuckOrders) { uc.webhooks = n }
+}
+
+func NewReapStuckOrders(repo StuckOrderRepo, cache OrderCache, queue OrderQueue, opts ...ReapOption) *ReapStuckOrders {
+	uc := &ReapStuckOrders{repo: repo, cache: cache, queue: queue, sla: 2 * time.Minute, maxAttempts: 3, batchSize: 100}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+type ReapResult struct {
+	Redispatched, Failed int
+}
+
+// Execute handles one batch of stuck orders. Errors on single orders are skipped (the order is
+// picked up again on the next run); only a failing scan is returned.
+func (uc *ReapStuckOrders) Execute(ctx context.Context) (ReapResult, error) {
+	var res ReapResult
+	stuck, err := uc.repo.ListStuck(ctx, string(domain.StatusProcessing), uc.sla, uc.batchSize)
+	if err != nil {
+		return res, err
+	}
+
+	for _, rec := range stuck {
+		if rec.DispatchAttempts >= uc.maxAttempts {
+			reason := fmt.Sprintf("no status from order-gw after %d re-dispatches", rec.DispatchAttempts)
+			ok, err := uc.repo.FailStuck(ctx, rec.ID, rec.Version, reason)
+			if err != nil || !ok {
+				continue
+			}
+			if uc.cache != nil {
+				_ = uc.cache.SetStatus(ctx, rec.ID, string(domain.StatusFailed))
+			}
+			if uc.events != nil {
+				_, _ = uc.events.PublishStatus(ctx, rec.ID, string(domain.StatusFailed))
+			}
+			if uc.webhooks != nil {
+				_ = uc.webhooks.Execute(ctx, rec.ID, string(domain.StatusFailed))
+			}
+			res.Failed++
+			continue
+		}
+
+		// claim the attempt first so a second reaper (or a retry after a crash) cannot double count it
+		ok, err := uc.repo.MarkRedispatched(ctx, rec.ID, rec.Version)
+		if err != nil || !ok {
+			continue
+		}
+		msg := CreatedMsg{
+			OrderID:  rec.ID,
+			UserID:   rec.UserID,
+			Cents:    rec.AmountCents,
+			Currency: rec.Currency,
+		}
+		if err := uc.queue.PublishCreated(ctx, msg); err != nil {
+			continue // the attempt is spent; the order is stuck again after another SLA
+		}
+		res.Redispatched++
+	}
+	return res, nil
+}
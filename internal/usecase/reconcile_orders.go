@@ -0,0 +1,217 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	domain "github.com/aq2208/gorder-api/internal/entity"
+	"github.com/google/uuid"
+)
+
+// Discrepancy kinds.
+const (
+	DiscrepancyStatus  = "status_mismatch"
+	DiscrepancyAmount  = "amount_mismatch"
+	DiscrepancyMissing = "missing_at_gateway"
+	DiscrepancyUnknown = "unknown_gateway_status" // order-gw reports a status we cannot translate
+)
+
+// What the job did about a discrepancy.
+const (
+	ActionReported  = "reported"
+	ActionCorrected = "corrected"
+	ActionSkipped   = "correction_skipped" // safe case, but the fix did not apply (order changed meanwhile or update failed)
+)
+
+// Discrepancy is one row of the reconciliation report. Gateway* fields are empty when order-gw does
+// not know the order.
+type Discrepancy struct {
+	RunID, OrderID, Kind, Action         string
+	LocalStatus, GatewayStatus           string
+	LocalCurrency, GatewayCurrency       string
+	LocalAmountCents, GatewayAmountCents int64
+}
+
+// ReconcileOrders compares recent orders with order-gw's records and writes every difference to the
+// discrepancy report. With auto-correct it also fixes the one safe case: we still show PROCESSING while
+// order-gw reached a final status with the same amount (a lost status event). Everything else needs a
+// human and is only reported.
+type ReconcileOrders struct {
+	repo        ReconcileRepo
+	orders      OrderRepo
+	cache       OrderCache
+	gw          OrderGatewayReader
+	reports     DiscrepancyRepo
+	events      OrderEventPublisher // optional
+	webhooks    *NotifyWebhooks     // optional
+	window      time.Duration
+	settle      time.Duration
+	pageSize    int
+	autoCorrect bool
+}
+
+type ReconcileOption func(*ReconcileOrders)
+
+// WithWindow sets how far back (by created_at) orders are compared (default 24h).
+func WithWindow(d time.Duration) ReconcileOption { return func(uc *ReconcileOrders) { uc.window = d } }
+
+// WithSettle skips orders updated more recently than d (default 5m).
+func WithSettle(d time.Duration) ReconcileOption { return func(uc *ReconcileOrders) { uc.settle = d } }
+
+// WithPageSize sets the orders compared per order-gw call (default 200).
+func WithPageSize(n int) ReconcileOption { return func(uc *ReconcileOrders) { uc.pageSize = n } }
+
+// WithAutoCorrect enables fixing safe discrepancies (compare-and-swap on the version that was compared).
+func WithAutoCorrect(on bool) ReconcileOption {
+	return func(uc *ReconcileOrders) { uc.autoCorrect = on }
+}
+
+// WithReconcileEvents publishes corrected statuses to order watchers.
+func WithReconcileEvents(p OrderEventPublisher) ReconcileOption {
+	return func(uc *ReconcileOrders) { uc.events = p }
+}
+
+// WithReconcileWebhooks notifies webhook subscribers of corrected statuses.
+func WithReconcileWebhooks(n *NotifyWebhooks) ReconcileOption {
+	return func(uc *ReconcileOrders) { uc.webhooks = n }
+}
+
+func NewReconcileOrders(repo ReconcileRepo, orders OrderRepo, cache OrderCache, gw OrderGatewayReader, reports DiscrepancyRepo, opts ...ReconcileOption) *ReconcileOrders {
+	uc := &ReconcileOrders{
+		repo: repo, orders: orders, cache: cache, gw: gw, reports: reports,
+		window: 24 * time.Hour, settle: 5 * time.Minute, pageSize: 200,
+	}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+type ReconcileReport struct {
+	RunID                             string
+	Checked, Discrepancies, Corrected int
+}
+
+// Execute runs one full pass. A failing page aborts the run; rows already written stay in the report.
+func (uc *ReconcileOrders) Execute(ctx context.Context) (ReconcileReport, error) {
+	rep := ReconcileReport{RunID: uuid.NewString()}
+	var cursor OrderCursor
+	for {
+		page, err := uc.repo.ListRecent(ctx, uc.window, uc.settle, cursor, uc.pageSize)
+		if err != nil {
+			return rep, err
+		}
+		if len(page) == 0 {
+			return rep, nil
+		}
+
+		ids := make([]string, len(page))
+		for i, rec := range page {
+			ids[i] = rec.ID
+		}
+		remote, err := uc.gw.ListOrders(ctx, ids)
+		if err != nil {
+			return rep, err
+		}
+
+		var found []Discrepancy
+		for _, rec := range page {
+			found = append(found, uc.compare(ctx, rep.RunID, rec, remote)...)
+		}
+		if len(found) > 0 {
+			if err := uc.reports.InsertDiscrepancies(ctx, found); err != nil {
+				return rep, err
+			}
+		}
+		rep.Checked += len(page)
+		rep.Discrepancies += len(found)
+		for _, d := range found {
+			if d.Action == ActionCorrected {
+				rep.Corrected++
+			}
+		}
+
+		last := page[len(page)-1]
+		cursor = OrderCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		if len(page) < uc.pageSize {
+			return rep, nil
+		}
+	}
+}
+
+func (uc *ReconcileOrders) compare(ctx context.Context, runID string, rec OrderRecord, remote map[string]GatewayOrder) []Discrepancy {
+	d := Discrepancy{
+		RunID:            runID,
+		OrderID:          rec.ID,
+		Action:           ActionReported,
+		LocalStatus:      rec.Status,
+		LocalCurrency:    rec.Currency,
+		LocalAmountCents: rec.AmountCents,
+	}
+
+	gw, ok := remote[rec.ID]
+	if !ok {
+		// a FAILED or CANCELLED order may never have reached order-gw; anything else should be known there
+		if rec.Status == string(domain.StatusFailed) || rec.Status == string(domain.StatusCancelled) {
+			return nil
+		}
+		d.Kind = DiscrepancyMissing
+		return []Discrepancy{d}
+	}
+	d.GatewayStatus = gw.Status
+	d.GatewayCurrency = gw.Currency
+	d.GatewayAmountCents = gw.AmountCents
+
+	var out []Discrepancy
+	amountOK := gw.AmountCents == rec.AmountCents && gw.Currency == rec.Currency
+	if !amountOK {
+		a := d
+		a.Kind = DiscrepancyAmount
+		out = append(out, a)
+	}
+	if gw.Status == GatewayStatusUnknown {
+		u := d
+		u.Kind = DiscrepancyUnknown
+		if gw.RawStatus != "" {
+			u.GatewayStatus = gw.RawStatus
+		}
+		return append(out, u)
+	}
+	// cancelled here and failed there agree: no money moved
+	cancelledAndFailed := rec.Status == string(domain.StatusCancelled) && gw.Status == string(domain.StatusFailed)
+	if gw.Status != rec.Status && !cancelledAndFailed {
+		s := d
+		s.Kind = DiscrepancyStatus
+		if uc.autoCorrect && amountOK && isSafeCorrection(rec.Status, gw.Status) {
+			s.Action = uc.correct(ctx, rec, gw.Status)
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// isSafeCorrection: only a PROCESSING order moving to order-gw's final status.
+func isSafeCorrection(local, remote string) bool {
+	return local == string(domain.StatusProcessing) &&
+		(remote == string(domain.StatusConfirmed) || remote == string(domain.StatusFailed))
+}
+
+// correct applies to only if rec has not changed since it was read; a later write (the status
+// consumer catching up, most likely) makes the correction moot.
+func (uc *ReconcileOrders) correct(ctx context.Context, rec OrderRecord, to string) string {
+	id := rec.ID
+	ok, err := uc.orders.UpdateStatus(ctx, id, rec.Version, to)
+	if err != nil || !ok {
+		return ActionSkipped
+	}
+	if uc.cache != nil {
+		_ = uc.cache.SetStatus(ctx, id, to)
+	}
+	if uc.events != nil {
+		_, _ = uc.events.PublishStatus(ctx, id, to)
+	}
+	if uc.webhooks != nil {
+		_ = uc.webhooks.Execute(ctx, id, to)
+	}
+	return ActionCorrected
+}
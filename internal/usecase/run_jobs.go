@@ -0,0 +1,241 @@
+package usecase
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// RunJobs runs queued import and export jobs, one per Execute. Progress is saved every
+// progressEvery rows, which also renews the job's claim; a job whose runner died is claimed again
+// once the claim expires and resumes:
+//   - an import skips the rows it had already saved as processed. Rows without an idempotency key
+//     get "import:<job id>:<line>", so rows handled after the last save are replayed, not created twice;
+//   - an export starts over.
+type RunJobs struct {
+	repo          JobRepo
+	files         JobFiles
+	create        *CreateOrder
+	orders        OrderExportRepo
+	pageSize      int
+	claimTTL      time.Duration
+	progressEvery int
+}
+
+type RunJobsOption func(*RunJobs)
+
+// WithExportPageSize sets the rows per export query and per Parquet row group (default 500).
+func WithExportPageSize(n int) RunJobsOption { return func(uc *RunJobs) { uc.pageSize = n } }
+
+// WithJobClaimTTL sets how long a running job stays claimed without saving progress (default 2m).
+func WithJobClaimTTL(d time.Duration) RunJobsOption { return func(uc *RunJobs) { uc.claimTTL = d } }
+
+// WithProgressEvery sets how many rows are handled between progress saves (default 100).
+func WithProgressEvery(n int) RunJobsOption { return func(uc *RunJobs) { uc.progressEvery = n } }
+
+func NewRunJobs(repo JobRepo, files JobFiles, create *CreateOrder, orders OrderExportRepo, opts ...RunJobsOption) *RunJobs {
+	uc := &RunJobs{repo: repo, files: files, create: create, orders: orders,
+		pageSize: 500, claimTTL: 2 * time.Minute, progressEvery: 100}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// Execute claims and runs one job; it returns nil when none was queued. A job that cannot run is
+// saved as failed and returned without error; the error is for the claim or the final save. When
+// ctx ends mid-job the job is left running, to be resumed after its claim expires.
+func (uc *RunJobs) Execute(ctx context.Context) (*Job, error) {
+	j, err := uc.repo.ClaimJob(ctx, uc.claimTTL)
+	if err != nil || j == nil {
+		return nil, err
+	}
+
+	switch j.Kind {
+	case JobImport:
+		err = uc.runImport(ctx, j)
+	case JobExport:
+		err = uc.runExport(ctx, j)
+	default:
+		err = fmt.Errorf("unknown job kind %q", j.Kind)
+	}
+	if ctx.Err() != nil {
+		return j, ctx.Err()
+	}
+
+	j.Status, j.FinishedAt = JobCompleted, time.Now()
+	if err != nil {
+		j.Status, j.Error = JobFailed, err.Error()
+	}
+	return j, uc.repo.SaveJob(context.WithoutCancel(ctx), j, 0)
+}
+
+// jobRowError is one line of an import's error report.
+type jobRowError struct {
+	Line    int    `json:"line"`
+	Error   string `json:"error"` // invalid | forbidden | duplicate | failed
+	Message string `json:"message"`
+}
+
+func (uc *RunJobs) runImport(ctx context.Context, j *Job) error {
+	if j.Total == 0 {
+		total, err := uc.countRows(j)
+		if err != nil {
+			return err
+		}
+		j.Total = total
+		if err := uc.repo.SaveJob(ctx, j, uc.claimTTL); err != nil {
+			return err
+		}
+	}
+
+	in, err := uc.files.Open(jobInputFile(j))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	rows, err := newRowReader(j.Format, in)
+	if err != nil {
+		return err
+	}
+
+	// a resumed job keeps the report written so far
+	open := uc.files.Append
+	if j.Processed == 0 {
+		open = uc.files.Create
+	}
+	ef, err := open(jobErrorFile(j))
+	if err != nil {
+		return err
+	}
+	defer ef.Close()
+	report := bufio.NewWriter(ef)
+	enc := json.NewEncoder(report)
+
+	save := func() error {
+		if err := report.Flush(); err != nil {
+			return err
+		}
+		return uc.repo.SaveJob(ctx, j, uc.claimTTL)
+	}
+
+	// create orders as the job's owner
+	ctx = WithPrincipal(ctx, j.Owner)
+	for seen := 0; ; seen++ {
+		row, err := rows.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if seen < j.Processed {
+			continue // handled before a restart
+		}
+
+		if row.Err == nil {
+			if row.In.IdempotencyKey == "" {
+				row.In.IdempotencyKey = fmt.Sprintf("import:%s:%d", j.ID, row.Line)
+			}
+			_, row.Err = uc.create.Execute(ctx, row.In)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		j.Processed++
+		if row.Err == nil {
+			j.Succeeded++
+		} else {
+			j.Failed++
+			if err := enc.Encode(jobRowError{Line: row.Line, Error: jobErrorCode(row.Err), Message: row.Err.Error()}); err != nil {
+				return err
+			}
+		}
+		if j.Processed%uc.progressEvery == 0 {
+			if err := save(); err != nil {
+				return err
+			}
+		}
+	}
+	j.Total = j.Processed
+	return report.Flush()
+}
+
+// countRows reads the upload once so progress can be reported against a total.
+func (uc *RunJobs) countRows(j *Job) (int, error) {
+	in, err := uc.files.Open(jobInputFile(j))
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+	rows, err := newRowReader(j.Format, in)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for {
+		if _, err := rows.next(); errors.Is(err, io.EOF) {
+			return n, nil
+		} else if err != nil {
+			return 0, err
+		}
+		n++
+	}
+}
+
+func (uc *RunJobs) runExport(ctx context.Context, j *Job) error {
+	j.Total, j.Processed = 0, 0
+	out, err := uc.files.Create(jobOutputFile(j))
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	rows, err := newRowWriter(j.Format, out, uc.pageSize)
+	if err != nil {
+		return err
+	}
+
+	var after OrderCursor
+	for {
+		page, err := uc.orders.ExportOrders(ctx, j.Filter, after, uc.pageSize)
+		if err != nil {
+			return err
+		}
+		for i := range page {
+			if err := rows.write(&page[i]); err != nil {
+				return err
+			}
+		}
+		j.Processed += len(page)
+		if len(page) < uc.pageSize {
+			break
+		}
+		last := page[len(page)-1]
+		after = OrderCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		if err := uc.repo.SaveJob(ctx, j, uc.claimTTL); err != nil {
+			return err
+		}
+	}
+	if err := rows.flush(); err != nil {
+		return err
+	}
+	j.Total = j.Processed
+	return out.Close()
+}
+
+// jobErrorCode is the machine-readable reason an import row was rejected.
+func jobErrorCode(err error) string {
+	switch {
+	case errors.Is(err, errBadRow), errors.Is(err, ErrValidation), errors.Is(err, ErrInvalidAmount):
+		return "invalid"
+	case errors.Is(err, ErrForbidden):
+		return "forbidden"
+	case errors.Is(err, ErrDuplicate):
+		return "duplicate"
+	}
+	return "failed"
+}
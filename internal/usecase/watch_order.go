@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	domain "github.com/aq2208/gorder-api/internal/entity"
+)
+
+// WatchOrder serves push-style status updates (SSE, long-poll, gRPC Watch) on top of the event stream.
+type WatchOrder struct {
+	get    *GetOrder
+	events OrderEventStream
+}
+
+func NewWatchOrder(get *GetOrder, events OrderEventStream) *WatchOrder {
+	return &WatchOrder{get: get, events: events}
+}
+
+// OrderWatch is an open watch. Read Events until it is closed or the order is final, then call Close.
+type OrderWatch struct {
+	Order   *OrderRecord      // state after subscribing
+	LastID  string            // newest retained event id ("" if none); identifies Order's state
+	Backlog []OrderEvent      // events after the caller's lastEventID (resume); nil for a fresh watch
+	Events  <-chan OrderEvent // live events newer than LastID / Backlog
+	Close   func()
+}
+
+// Watch authorizes like GetOrder, subscribes and only then reads state and history, so a change racing
+// with the subscription is seen either in the state or on Events (duplicates are dropped by event id).
+func (uc *WatchOrder) Watch(ctx context.Context, id, lastEventID string) (*OrderWatch, error) {
+	if _, err := uc.get.Execute(ctx, id); err != nil {
+		return nil, err
+	}
+	live, cancel := uc.events.Subscribe(ctx, id)
+
+	rec, err := uc.get.Execute(ctx, id)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	history, err := uc.events.Since(ctx, id, lastEventID)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	w := &OrderWatch{Order: rec, LastID: lastEventID, Close: cancel}
+	if lastEventID != "" {
+		w.Backlog = append([]OrderEvent{}, history...) // non-nil marks a resume
+	}
+	if n := len(history); n > 0 {
+		w.LastID = history[n-1].ID
+	}
+
+	out := make(chan OrderEvent, 8)
+	go func(seen string) {
+		defer close(out)
+		for ev := range live {
+			if !EventIDAfter(ev.ID, seen) {
+				continue
+			}
+			seen = ev.ID
+			select {
+			case out <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}(w.LastID)
+	w.Events = out
+	return w, nil
+}
+
+// AwaitFinal returns the order once it is CONFIRMED/FAILED or wait elapses, whichever is first
+// (long-poll). The returned record is always re-read, so it is current either way.
+func (uc *WatchOrder) AwaitFinal(ctx context.Context, id string, wait time.Duration) (*OrderRecord, error) {
+	rec, err := uc.get.Execute(ctx, id)
+	if err != nil || wait <= 0 || domain.Status(rec.Status).IsFinal() {
+		return rec, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+	w, err := uc.Watch(ctx, id, "")
+	if err != nil {
+		return nil, err
+	}
+	defer w.Close()
+
+	if !domain.Status(w.Order.Status).IsFinal() {
+	loop:
+		for {
+			select {
+			case ev, ok := <-w.Events:
+				if !ok || domain.Status(ev.Status).IsFinal() {
+					break loop
+				}
+			case <-ctx.Done():
+				break loop
+			}
+		}
+	}
+	return uc.get.Execute(context.WithoutCancel(ctx), id)
+}
+
+// EventIDAfter reports whether event id a is newer than b ("<ms>-<seq>"; "" is older than everything).
+func EventIDAfter(a, b string) bool {
+	if b == "" {
+		return a != ""
+	}
+	am, as := splitEventID(a)
+	bm, bs := splitEventID(b)
+	if am != bm {
+		return am > bm
+	}
+	return as > bs
+}
+
+func splitEventID(id string) (ms, seq uint64) {
+	m, s, _ := strings.Cut(id, "-")
+	ms, _ = strconv.ParseUint(m, 10, 64)
+	seq, _ = strconv.ParseUint(s, 10, 64)
+	return ms, seq
+}
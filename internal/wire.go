@@ -1 +1,132 @@
+//go:build wireinject
+
 package internal
+
+import (
+	"github.com/aq2208/gorder-api/configs"
+	"github.com/aq2208/gorder-api/internal/adapter/cache"
+	"github.com/aq2208/gorder-api/internal/adapter/grpc"
+	"github.com/aq2208/gorder-api/internal/adapter/grpcapi"
+	"github.com/aq2208/gorder-api/internal/adapter/http"
+	"github.com/aq2208/gorder-api/internal/adapter/jobs"
+	"github.com/aq2208/gorder-api/internal/adapter/kafka"
+	"github.com/aq2208/gorder-api/internal/adapter/memory"
+	"github.com/aq2208/gorder-api/internal/adapter/queue"
+	"github.com/aq2208/gorder-api/internal/adapter/reaper"
+	"github.com/aq2208/gorder-api/internal/adapter/repo"
+	"github.com/aq2208/gorder-api/internal/adapter/webhook"
+	"github.com/aq2208/gorder-api/internal/security"
+	"github.com/aq2208/gorder-api/internal/usecase"
+	"github.com/google/wire"
+)
+
+// Injectors. Run `go generate ./internal` (or `wire ./internal`) after changing them; the output is wire_gen.go.
+// Every provider returning a cleanup is closed in reverse order by the returned cleanup func, and a failing
+// provider releases everything built before it.
+
+var usecaseSet = wire.NewSet(
+	usecase.NewCreateOrder,
+	provideCreateOrderBatch,
+	usecase.NewGetOrder,
+	usecase.NewWatchOrder,
+	usecase.NewListOrders,
+	usecase.NewCancelOrder,
+	provideManageWebhooks,
+	provideManageJobs,
+)
+
+var apiSet = wire.NewSet(
+	repo.ProviderSet,
+	cache.ProviderSet,
+	queue.ProviderSet,
+	security.ProviderSet,
+	http.ProviderSet,
+	grpcapi.ProviderSet,
+	jobs.ProviderSet,
+	usecaseSet,
+	wire.Struct(new(API), "*"),
+)
+
+var workerSet = wire.NewSet(
+	repo.ProviderSet,
+	cache.ProviderSet,
+	queue.ProviderSet,
+	grpc.ProviderSet,
+	reaper.ProviderSet,
+	webhook.ProviderSet,
+	usecase.NewNotifyWebhooks,
+	wire.Bind(new(queue.OrderGateway), new(*grpc.OrderGWClient)),
+	wire.Struct(new(OrderWorker), "*"),
+)
+
+var statusSet = wire.NewSet(
+	repo.ProviderSet,
+	cache.ProviderSet,
+	kafka.ProviderSet,
+	usecase.NewNotifyWebhooks,
+	wire.Struct(new(StatusConsumer), "*"),
+)
+
+func InitializeAPI(cfg configs.Config) (*API, func(), error) {
+	panic(wire.Build(apiSet))
+}
+
+func InitializeOrderWorker(cfg configs.Config) (*OrderWorker, func(), error) {
+	panic(wire.Build(workerSet))
+}
+
+func InitializeStatusConsumer(cfg configs.Config) (*StatusConsumer, func(), error) {
+	panic(wire.Build(statusSet))
+}
+
+func InitializeReconciler(cfg configs.Config) (*Reconciler, func(), error) {
+	panic(wire.Build(
+		repo.ProviderSet,
+		cache.ProviderSet,
+		grpc.ProviderSet,
+		usecase.NewNotifyWebhooks,
+		provideReconcileOrders,
+		wire.Struct(new(Reconciler), "*"),
+	))
+}
+
+func InitializeCombined(cfg configs.Config) (*Combined, func(), error) {
+	panic(wire.Build(
+		repo.ProviderSet,
+		cache.ProviderSet,
+		queue.ProviderSet,
+		grpc.ProviderSet,
+		kafka.ProviderSet,
+		reaper.ProviderSet,
+		webhook.ProviderSet,
+		security.ProviderSet,
+		http.ProviderSet,
+		grpcapi.ProviderSet,
+		jobs.ProviderSet,
+		usecaseSet,
+		usecase.NewNotifyWebhooks,
+		wire.Bind(new(queue.OrderGateway), new(*grpc.OrderGWClient)),
+		wire.Struct(new(API), "*"),
+		wire.Struct(new(OrderWorker), "*"),
+		wire.Struct(new(StatusConsumer), "*"),
+		wire.Struct(new(Combined), "*"),
+	))
+}
+
+func InitializeLocalMem(cfg configs.Config) (*LocalMem, func(), error) {
+	panic(wire.Build(
+		memory.ProviderSet,
+		reaper.ProviderSet,
+		webhook.ProviderSet,
+		security.ProviderSet,
+		http.ProviderSet,
+		grpcapi.ProviderSet,
+		jobs.ProviderSet,
+		usecaseSet,
+		usecase.NewNotifyWebhooks,
+		queue.NewOrderCreatedHandler,
+		kafka.NewOrderStatusChangedHandler,
+		wire.Bind(new(queue.OrderGateway), new(*memory.Gateway)),
+		wire.Struct(new(LocalMem), "*"),
+	))
+}
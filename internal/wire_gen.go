@@ -0,0 +1,530 @@
+// Code generated by Wire. DO NOT EDIT.
+
+//go:generate go run -mod=mod github.com/google/wire/cmd/wire
+//go:build !wireinject
+// +build !wireinject
+
+package internal
+
+import (
+	"github.com/aq2208/gorder-api/configs"
+	"github.com/aq2208/gorder-api/internal/adapter/cache"
+	"github.com/aq2208/gorder-api/internal/adapter/grpc"
+	"github.com/aq2208/gorder-api/internal/adapter/grpcapi"
+	"github.com/aq2208/gorder-api/internal/adapter/http"
+	"github.com/aq2208/gorder-api/internal/adapter/http/middleware"
+	"github.com/aq2208/gorder-api/internal/adapter/jobs"
+	"github.com/aq2208/gorder-api/internal/adapter/kafka"
+	"github.com/aq2208/gorder-api/internal/adapter/memory"
+	"github.com/aq2208/gorder-api/internal/adapter/queue"
+	"github.com/aq2208/gorder-api/internal/adapter/reaper"
+	"github.com/aq2208/gorder-api/internal/adapter/repo"
+	"github.com/aq2208/gorder-api/internal/adapter/webhook"
+	"github.com/aq2208/gorder-api/internal/security"
+	"github.com/aq2208/gorder-api/internal/usecase"
+	"github.com/google/wire"
+)
+
+// Injectors from wire.go:
+
+func InitializeAPI(cfg configs.Config) (*API, func(), error) {
+	db, cleanup, err := repo.NewDB(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	mySQLOrderRepo := repo.NewMySQLOrderRepo(db)
+	client, cleanup2, err := cache.NewRedisClient(cfg)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	redisCache := cache.ProvideCache(client, cfg)
+	redisIdempotencyStore := cache.ProvideIdempotencyStore(client, cfg)
+	connection, cleanup3, err := queue.NewConnection(cfg)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	rabbitProducer, cleanup4, err := queue.ProvideProducer(connection, cfg)
+	if err != nil {
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	createOrder := usecase.NewCreateOrder(mySQLOrderRepo, redisCache, redisIdempotencyStore, rabbitProducer)
+	createOrderBatch := provideCreateOrderBatch(cfg, mySQLOrderRepo, redisCache, redisIdempotencyStore, rabbitProducer)
+	getOrder := usecase.NewGetOrder(mySQLOrderRepo)
+	redisOrderEvents, cleanup5 := cache.ProvideOrderEvents(client, cfg)
+	watchOrder := usecase.NewWatchOrder(getOrder, redisOrderEvents)
+	cancelOrder := usecase.NewCancelOrder(mySQLOrderRepo, redisCache, redisOrderEvents)
+	orderHandler := http.NewOrderHandler(createOrder, createOrderBatch, getOrder, watchOrder, cancelOrder, cfg)
+	orderEventsHandler := http.NewOrderEventsHandler(watchOrder, cfg)
+	mySQLWebhookRepo := repo.NewMySQLWebhookRepo(db)
+	manageWebhooks := provideManageWebhooks(cfg, mySQLWebhookRepo)
+	webhookHandler := http.NewWebhookHandler(manageWebhooks)
+	mySQLJobRepo := repo.NewMySQLJobRepo(db)
+	localFiles, err := jobs.ProvideLocalFiles(cfg)
+	if err != nil {
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	manageJobs := provideManageJobs(cfg, mySQLJobRepo, localFiles)
+	jobHandler := http.NewJobHandler(manageJobs)
+	tokenHandler := http.NewTokenHandler(cfg)
+	authz := middleware.NewAuthz(cfg)
+	keyStore, err := security.LoadKeyStore(cfg)
+	if err != nil {
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	redisNonceStore := cache.NewRedisNonceStore(client)
+	replayGuard := security.ProvideReplayGuard(redisNonceStore, cfg)
+	serverSigner, err := security.LoadServerSigner(cfg)
+	if err != nil {
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	cryptoVerify := middleware.ProvideCryptoVerify(cfg, keyStore, replayGuard, serverSigner)
+	rateLimiter := middleware.ProvideRateLimiter(cfg)
+	engine := http.NewRouter(orderHandler, orderEventsHandler, webhookHandler, jobHandler, tokenHandler, authz, cryptoVerify, rateLimiter, cfg)
+	listOrders := usecase.NewListOrders(mySQLOrderRepo)
+	orderAPI := grpcapi.NewOrderAPI(createOrder, getOrder, listOrders, cancelOrder, watchOrder)
+	auth := grpcapi.NewAuth(authz, rateLimiter)
+	server, cleanup6, err := grpcapi.NewServer(cfg, orderAPI, auth)
+	if err != nil {
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	runJobs := jobs.ProvideRunJobs(cfg, mySQLJobRepo, localFiles, createOrder, mySQLOrderRepo)
+	runner := jobs.ProvideRunner(cfg, runJobs)
+	api := &API{
+		Router:    engine,
+		GRPC:      server,
+		Jobs:      runner,
+		Cache:     redisCache,
+		Idem:      redisIdempotencyStore,
+		RateLimit: rateLimiter,
+	}
+	return api, func() {
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+	}, nil
+}
+
+func InitializeOrderWorker(cfg configs.Config) (*OrderWorker, func(), error) {
+	connection, cleanup, err := queue.NewConnection(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	db, cleanup2, err := repo.NewDB(cfg)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	mySQLOrderRepo := repo.NewMySQLOrderRepo(db)
+	clientConn, cleanup3, err := grpc.NewOrderGWConn(cfg)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	orderGWClient := grpc.ProvideOrderGWClient(clientConn)
+	orderCreatedHandler := queue.NewOrderCreatedHandler(mySQLOrderRepo, orderGWClient)
+	router, cleanup4, err := queue.ProvideRouter(connection, cfg, orderCreatedHandler)
+	if err != nil {
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	client, cleanup5, err := cache.NewRedisClient(cfg)
+	if err != nil {
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	redisCache := cache.ProvideCache(client, cfg)
+	rabbitProducer, cleanup6, err := queue.ProvideProducer(connection, cfg)
+	if err != nil {
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	redisOrderEvents, cleanup7 := cache.ProvideOrderEvents(client, cfg)
+	mySQLWebhookRepo := repo.NewMySQLWebhookRepo(db)
+	notifyWebhooks := usecase.NewNotifyWebhooks(mySQLOrderRepo, mySQLWebhookRepo)
+	reapStuckOrders := reaper.ProvideReapStuckOrders(cfg, mySQLOrderRepo, redisCache, rabbitProducer, redisOrderEvents, notifyWebhooks)
+	redisLeaderLease := cache.ProvideReaperLease(client, cfg)
+	runner := reaper.ProvideRunner(cfg, reapStuckOrders, redisLeaderLease)
+	httpSender := webhook.ProvideHTTPSender(cfg)
+	deliverWebhooks := webhook.ProvideDeliverWebhooks(cfg, mySQLWebhookRepo, httpSender)
+	webhookRunner := webhook.ProvideRunner(cfg, deliverWebhooks)
+	orderWorker := &OrderWorker{
+		Router:   router,
+		Reaper:   runner,
+		Webhooks: webhookRunner,
+	}
+	return orderWorker, func() {
+		cleanup7()
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+	}, nil
+}
+
+func InitializeStatusConsumer(cfg configs.Config) (*StatusConsumer, func(), error) {
+	consumerGroup, cleanup, err := kafka.NewConsumerGroup(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	db, cleanup2, err := repo.NewDB(cfg)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	mySQLOrderRepo := repo.NewMySQLOrderRepo(db)
+	client, cleanup3, err := cache.NewRedisClient(cfg)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	redisCache := cache.ProvideCache(client, cfg)
+	redisOrderEvents, cleanup4 := cache.ProvideOrderEvents(client, cfg)
+	mySQLWebhookRepo := repo.NewMySQLWebhookRepo(db)
+	notifyWebhooks := usecase.NewNotifyWebhooks(mySQLOrderRepo, mySQLWebhookRepo)
+	orderStatusChangedHandler := kafka.NewOrderStatusChangedHandler(mySQLOrderRepo, redisCache, redisOrderEvents, notifyWebhooks)
+	consumer := kafka.ProvideStatusConsumer(consumerGroup, cfg, orderStatusChangedHandler)
+	statusConsumer := &StatusConsumer{
+		Consumer: consumer,
+		Cache:    redisCache,
+	}
+	return statusConsumer, func() {
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+	}, nil
+}
+
+func InitializeReconciler(cfg configs.Config) (*Reconciler, func(), error) {
+	db, cleanup, err := repo.NewDB(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	mySQLOrderRepo := repo.NewMySQLOrderRepo(db)
+	client, cleanup2, err := cache.NewRedisClient(cfg)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	redisCache := cache.ProvideCache(client, cfg)
+	clientConn, cleanup3, err := grpc.NewOrderGWConn(cfg)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	orderGWClient := grpc.ProvideOrderGWClient(clientConn)
+	mySQLDiscrepancyRepo := repo.NewMySQLDiscrepancyRepo(db)
+	redisOrderEvents, cleanup4 := cache.ProvideOrderEvents(client, cfg)
+	mySQLWebhookRepo := repo.NewMySQLWebhookRepo(db)
+	notifyWebhooks := usecase.NewNotifyWebhooks(mySQLOrderRepo, mySQLWebhookRepo)
+	reconcileOrders := provideReconcileOrders(cfg, mySQLOrderRepo, mySQLOrderRepo, redisCache, orderGWClient, mySQLDiscrepancyRepo, redisOrderEvents, notifyWebhooks)
+	reconciler := &Reconciler{
+		Job: reconcileOrders,
+	}
+	return reconciler, func() {
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+	}, nil
+}
+
+func InitializeCombined(cfg configs.Config) (*Combined, func(), error) {
+	db, cleanup, err := repo.NewDB(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	mySQLOrderRepo := repo.NewMySQLOrderRepo(db)
+	client, cleanup2, err := cache.NewRedisClient(cfg)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	redisCache := cache.ProvideCache(client, cfg)
+	redisIdempotencyStore := cache.ProvideIdempotencyStore(client, cfg)
+	connection, cleanup3, err := queue.NewConnection(cfg)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	rabbitProducer, cleanup4, err := queue.ProvideProducer(connection, cfg)
+	if err != nil {
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	createOrder := usecase.NewCreateOrder(mySQLOrderRepo, redisCache, redisIdempotencyStore, rabbitProducer)
+	createOrderBatch := provideCreateOrderBatch(cfg, mySQLOrderRepo, redisCache, redisIdempotencyStore, rabbitProducer)
+	getOrder := usecase.NewGetOrder(mySQLOrderRepo)
+	redisOrderEvents, cleanup5 := cache.ProvideOrderEvents(client, cfg)
+	watchOrder := usecase.NewWatchOrder(getOrder, redisOrderEvents)
+	cancelOrder := usecase.NewCancelOrder(mySQLOrderRepo, redisCache, redisOrderEvents)
+	orderHandler := http.NewOrderHandler(createOrder, createOrderBatch, getOrder, watchOrder, cancelOrder, cfg)
+	orderEventsHandler := http.NewOrderEventsHandler(watchOrder, cfg)
+	mySQLWebhookRepo := repo.NewMySQLWebhookRepo(db)
+	manageWebhooks := provideManageWebhooks(cfg, mySQLWebhookRepo)
+	webhookHandler := http.NewWebhookHandler(manageWebhooks)
+	mySQLJobRepo := repo.NewMySQLJobRepo(db)
+	localFiles, err := jobs.ProvideLocalFiles(cfg)
+	if err != nil {
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	manageJobs := provideManageJobs(cfg, mySQLJobRepo, localFiles)
+	jobHandler := http.NewJobHandler(manageJobs)
+	tokenHandler := http.NewTokenHandler(cfg)
+	authz := middleware.NewAuthz(cfg)
+	keyStore, err := security.LoadKeyStore(cfg)
+	if err != nil {
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	redisNonceStore := cache.NewRedisNonceStore(client)
+	replayGuard := security.ProvideReplayGuard(redisNonceStore, cfg)
+	serverSigner, err := security.LoadServerSigner(cfg)
+	if err != nil {
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	cryptoVerify := middleware.ProvideCryptoVerify(cfg, keyStore, replayGuard, serverSigner)
+	rateLimiter := middleware.ProvideRateLimiter(cfg)
+	engine := http.NewRouter(orderHandler, orderEventsHandler, webhookHandler, jobHandler, tokenHandler, authz, cryptoVerify, rateLimiter, cfg)
+	listOrders := usecase.NewListOrders(mySQLOrderRepo)
+	orderAPI := grpcapi.NewOrderAPI(createOrder, getOrder, listOrders, cancelOrder, watchOrder)
+	auth := grpcapi.NewAuth(authz, rateLimiter)
+	server, cleanup6, err := grpcapi.NewServer(cfg, orderAPI, auth)
+	if err != nil {
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	runJobs := jobs.ProvideRunJobs(cfg, mySQLJobRepo, localFiles, createOrder, mySQLOrderRepo)
+	runner := jobs.ProvideRunner(cfg, runJobs)
+	api := &API{
+		Router:    engine,
+		GRPC:      server,
+		Jobs:      runner,
+		Cache:     redisCache,
+		Idem:      redisIdempotencyStore,
+		RateLimit: rateLimiter,
+	}
+	clientConn, cleanup7, err := grpc.NewOrderGWConn(cfg)
+	if err != nil {
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	orderGWClient := grpc.ProvideOrderGWClient(clientConn)
+	orderCreatedHandler := queue.NewOrderCreatedHandler(mySQLOrderRepo, orderGWClient)
+	router, cleanup8, err := queue.ProvideRouter(connection, cfg, orderCreatedHandler)
+	if err != nil {
+		cleanup7()
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	notifyWebhooks := usecase.NewNotifyWebhooks(mySQLOrderRepo, mySQLWebhookRepo)
+	reapStuckOrders := reaper.ProvideReapStuckOrders(cfg, mySQLOrderRepo, redisCache, rabbitProducer, redisOrderEvents, notifyWebhooks)
+	redisLeaderLease := cache.ProvideReaperLease(client, cfg)
+	reaperRunner := reaper.ProvideRunner(cfg, reapStuckOrders, redisLeaderLease)
+	httpSender := webhook.ProvideHTTPSender(cfg)
+	deliverWebhooks := webhook.ProvideDeliverWebhooks(cfg, mySQLWebhookRepo, httpSender)
+	webhookRunner := webhook.ProvideRunner(cfg, deliverWebhooks)
+	orderWorker := &OrderWorker{
+		Router:   router,
+		Reaper:   reaperRunner,
+		Webhooks: webhookRunner,
+	}
+	consumerGroup, cleanup9, err := kafka.NewConsumerGroup(cfg)
+	if err != nil {
+		cleanup8()
+		cleanup7()
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	orderStatusChangedHandler := kafka.NewOrderStatusChangedHandler(mySQLOrderRepo, redisCache, redisOrderEvents, notifyWebhooks)
+	consumer := kafka.ProvideStatusConsumer(consumerGroup, cfg, orderStatusChangedHandler)
+	statusConsumer := &StatusConsumer{
+		Consumer: consumer,
+		Cache:    redisCache,
+	}
+	combined := &Combined{
+		API:    api,
+		Worker: orderWorker,
+		Status: statusConsumer,
+	}
+	return combined, func() {
+		cleanup9()
+		cleanup8()
+		cleanup7()
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+	}, nil
+}
+
+func InitializeLocalMem(cfg configs.Config) (*LocalMem, func(), error) {
+	orderRepo := memory.NewOrderRepo()
+	memoryCache := memory.ProvideCache(cfg)
+	idempotencyStore := memory.ProvideIdempotencyStore(cfg)
+	commandBus := memory.NewCommandBus()
+	createOrder := usecase.NewCreateOrder(orderRepo, memoryCache, idempotencyStore, commandBus)
+	createOrderBatch := provideCreateOrderBatch(cfg, orderRepo, memoryCache, idempotencyStore, commandBus)
+	getOrder := usecase.NewGetOrder(orderRepo)
+	orderEvents := memory.NewOrderEvents()
+	watchOrder := usecase.NewWatchOrder(getOrder, orderEvents)
+	cancelOrder := usecase.NewCancelOrder(orderRepo, memoryCache, orderEvents)
+	orderHandler := http.NewOrderHandler(createOrder, createOrderBatch, getOrder, watchOrder, cancelOrder, cfg)
+	orderEventsHandler := http.NewOrderEventsHandler(watchOrder, cfg)
+	webhookRepo := memory.NewWebhookRepo()
+	manageWebhooks := provideManageWebhooks(cfg, webhookRepo)
+	webhookHandler := http.NewWebhookHandler(manageWebhooks)
+	jobRepo := memory.NewJobRepo()
+	localFiles, err := jobs.ProvideLocalFiles(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	manageJobs := provideManageJobs(cfg, jobRepo, localFiles)
+	jobHandler := http.NewJobHandler(manageJobs)
+	tokenHandler := http.NewTokenHandler(cfg)
+	authz := middleware.NewAuthz(cfg)
+	keyStore, err := security.LoadKeyStore(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonceStore := memory.NewNonceStore()
+	replayGuard := security.ProvideReplayGuard(nonceStore, cfg)
+	serverSigner, err := security.LoadServerSigner(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	cryptoVerify := middleware.ProvideCryptoVerify(cfg, keyStore, replayGuard, serverSigner)
+	rateLimiter := middleware.ProvideRateLimiter(cfg)
+	engine := http.NewRouter(orderHandler, orderEventsHandler, webhookHandler, jobHandler, tokenHandler, authz, cryptoVerify, rateLimiter, cfg)
+	listOrders := usecase.NewListOrders(orderRepo)
+	orderAPI := grpcapi.NewOrderAPI(createOrder, getOrder, listOrders, cancelOrder, watchOrder)
+	auth := grpcapi.NewAuth(authz, rateLimiter)
+	server, cleanup, err := grpcapi.NewServer(cfg, orderAPI, auth)
+	if err != nil {
+		return nil, nil, err
+	}
+	statusBus := memory.NewStatusBus()
+	gateway := memory.NewGateway(statusBus)
+	orderCreatedHandler := queue.NewOrderCreatedHandler(orderRepo, gateway)
+	notifyWebhooks := usecase.NewNotifyWebhooks(orderRepo, webhookRepo)
+	orderStatusChangedHandler := kafka.NewOrderStatusChangedHandler(orderRepo, memoryCache, orderEvents, notifyWebhooks)
+	reapStuckOrders := reaper.ProvideReapStuckOrders(cfg, orderRepo, memoryCache, commandBus, orderEvents, notifyWebhooks)
+	leaderLease := memory.NewLeaderLease()
+	runner := reaper.ProvideRunner(cfg, reapStuckOrders, leaderLease)
+	httpSender := webhook.ProvideHTTPSender(cfg)
+	deliverWebhooks := webhook.ProvideDeliverWebhooks(cfg, webhookRepo, httpSender)
+	webhookRunner := webhook.ProvideRunner(cfg, deliverWebhooks)
+	runJobs := jobs.ProvideRunJobs(cfg, jobRepo, localFiles, createOrder, orderRepo)
+	jobsRunner := jobs.ProvideRunner(cfg, runJobs)
+	localMem := &LocalMem{
+		Router:        engine,
+		GRPC:          server,
+		Cache:         memoryCache,
+		Idem:          idempotencyStore,
+		RateLimit:     rateLimiter,
+		Commands:      commandBus,
+		Statuses:      statusBus,
+		Gateway:       gateway,
+		Worker:        orderCreatedHandler,
+		StatusHandler: orderStatusChangedHandler,
+		Reaper:        runner,
+		Webhooks:      webhookRunner,
+		Jobs:          jobsRunner,
+	}
+	return localMem, func() {
+		cleanup()
+	}, nil
+}
+
+// wire.go:
+
+var usecaseSet = wire.NewSet(usecase.NewCreateOrder, provideCreateOrderBatch, usecase.NewGetOrder, usecase.NewWatchOrder, usecase.NewListOrders, usecase.NewCancelOrder, provideManageWebhooks,
+	provideManageJobs,
+)
+
+var apiSet = wire.NewSet(repo.ProviderSet, cache.ProviderSet, queue.ProviderSet, security.ProviderSet, http.ProviderSet, grpcapi.ProviderSet, jobs.ProviderSet, usecaseSet, wire.Struct(new(API), "*"))
+
+var workerSet = wire.NewSet(repo.ProviderSet, cache.ProviderSet, queue.ProviderSet, grpc.ProviderSet, reaper.ProviderSet, webhook.ProviderSet, usecase.NewNotifyWebhooks, wire.Bind(new(queue.OrderGateway), new(*grpc.OrderGWClient)), wire.Struct(new(OrderWorker), "*"))
+
+var statusSet = wire.NewSet(repo.ProviderSet, cache.ProviderSet, kafka.ProviderSet, usecase.NewNotifyWebhooks, wire.Struct(new(StatusConsumer), "*"))
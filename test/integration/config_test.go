@@ -0,0 +1,112 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aq2208/gorder-api/configs"
+)
+
+// config check validates secret references by syntax without resolving them, and reports every
+// problem at once; --resolve-secrets resolves them as the server does.
+func TestConfigCheck(t *testing.T) {
+	dir := t.TempDir()
+	base, err := os.ReadFile(filepath.Join(configDir, "base.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ci := `
+mysql:
+  dsn: "env://GORDER_TEST_UNSET_DSN"
+rabbitmq:
+  url: "env://GORDER_TEST_UNSET_AMQP"
+crypto:
+  server_rsa_pri_pem_file: "/etc/order-api/keys/server/rsa_pri.pem"
+security:
+  jwt_secret: "sealed://secrets.yaml#security.jwt_secret"
+`
+	for name, body := range map[string]string{"base.yaml": string(base), "ci.yaml": ci} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := configs.Check(dir, "ci", false)
+	if err != nil {
+		t.Fatalf("syntax-only check: %v", err)
+	}
+	if got.MySQL.DSN != "env://GORDER_TEST_UNSET_DSN" || got.Security.TTL != 30*time.Minute {
+		t.Fatalf("checked config: dsn %q ttl %s", got.MySQL.DSN, got.Security.TTL)
+	}
+
+	_, err = configs.Check(dir, "ci", true)
+	for _, want := range []string{"mysql.dsn", "rabbitmq.url", "security.jwt_secret"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("resolving check = %v, want an error for %s", err, want)
+		}
+	}
+
+	// malformed references and other problems are all reported without resolving anything
+	bad := ci + `  ttl: 30
+mysql_extra: 1
+`
+	bad = strings.Replace(bad, "env://GORDER_TEST_UNSET_DSN", "env://not a name", 1)
+	bad = strings.Replace(bad, "#security.jwt_secret", "", 1)
+	if err := os.WriteFile(filepath.Join(dir, "ci.yaml"), []byte(bad), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = configs.Check(dir, "ci", false)
+	for _, want := range []string{"unknown config keys: mysql_extra", "mysql.dsn (env://)", "security.jwt_secret (sealed://)", "security.ttl"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("check = %v, want %q", err, want)
+		}
+	}
+}
+
+// A sealed:// reference only accepts a value in sealed form.
+func TestOpenSealedValue(t *testing.T) {
+	key := make([]byte, 32)
+	sealed, err := configs.SealValue(key, "security.jwt_secret", "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := configs.OpenSealedValue(key, "security.jwt_secret", sealed); err != nil || got != "s3cret" {
+		t.Fatalf("open = %q %v", got, err)
+	}
+	if _, err := configs.OpenSealedValue(key, "security.ttl", sealed); err == nil {
+		t.Fatal("a value sealed for another key opened")
+	}
+	for _, v := range []string{"s3cret", "ENC[AES256_GCM,data:abc]", ""} {
+		if _, err := configs.OpenSealedValue(key, "security.jwt_secret", v); err == nil {
+			t.Fatalf("open %q: want an error", v)
+		}
+	}
+}
+
+// Responses are signed with a server-only key; the client-held legacy key is a dev-only fallback.
+func TestServerKeyRequired(t *testing.T) {
+	dir := t.TempDir()
+	base, err := os.ReadFile(filepath.Join(configDir, "base.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy := "security:\n  jwt_secret: \"s\"\ncrypto:\n  server_key_from_legacy: true\n  rsa_pri_pem: \"pem\"\n"
+	for name, body := range map[string]string{"base.yaml": string(base), "stg.yaml": "", "dev.yaml": legacy, "prod.yaml": legacy} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for env, want := range map[string]string{
+		"stg":  "crypto.server_rsa_pri_pem or server_rsa_pri_pem_file required",
+		"prod": "crypto.server_key_from_legacy is only allowed in dev and local-mem",
+		"dev":  "",
+	} {
+		_, err := configs.Check(dir, env, false)
+		if want == "" && err != nil || want != "" && (err == nil || !strings.Contains(err.Error(), want)) {
+			t.Fatalf("%s: check = %v, want %q", env, err, want)
+		}
+	}
+}
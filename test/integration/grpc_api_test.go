@@ -0,0 +1,289 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/aq2208/gorder-api/internal/adapter/grpcapi"
+	pb "github.com/aq2208/gorder-api/internal/generated/orderapipb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// grpcConn serves the harness's OrderAPI over an in-memory listener and returns a client conn.
+func (h *harness) grpcConn() *grpc.ClientConn {
+	h.t.Helper()
+	srv, cleanup, err := grpcapi.NewServer(cfg, h.grpc, h.auth)
+	if err != nil {
+		h.t.Fatalf("grpc server: %v", err)
+	}
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = srv.Serve(lis) }()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		h.t.Fatalf("grpc dial: %v", err)
+	}
+	h.t.Cleanup(func() {
+		_ = conn.Close()
+		cleanup()
+	})
+	return conn
+}
+
+// bearer returns a call context carrying token, cancelled when the test ends.
+func (h *harness) bearer(token string) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	h.t.Cleanup(cancel)
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
+
+func expectCode(t *testing.T, err error, want codes.Code) {
+	t.Helper()
+	if got := status.Code(err); got != want {
+		t.Fatalf("code = %s, want %s (err %v)", got, want, err)
+	}
+}
+
+func TestGRPCAuth(t *testing.T) {
+	h := newHarness(t)
+	conn := h.grpcConn()
+	api := pb.NewOrderAPIClient(conn)
+
+	// health and reflection need no token
+	hc, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{Service: "gorder.orderapi.v1.OrderAPI"})
+	if err != nil || hc.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("health = %v, %v", hc, err)
+	}
+
+	_, err = api.GetOrder(context.Background(), &pb.GetOrderRequest{Id: "x"})
+	expectCode(t, err, codes.Unauthenticated)
+	_, err = api.GetOrder(h.bearer("not-a-jwt"), &pb.GetOrderRequest{Id: "x"})
+	expectCode(t, err, codes.Unauthenticated)
+
+	// read-only client
+	ana := h.token("svc-analytics", "ana-secret")
+	_, err = api.CreateOrder(h.bearer(ana), &pb.CreateOrderRequest{UserId: "u1", AmountCents: 100, Currency: "USD", ItemsJson: "[]"})
+	expectCode(t, err, codes.PermissionDenied)
+	_, err = api.CancelOrder(h.bearer(ana), &pb.CancelOrderRequest{Id: "x"})
+	expectCode(t, err, codes.PermissionDenied)
+	_, err = api.GetOrder(h.bearer(ana), &pb.GetOrderRequest{Id: "missing"})
+	expectCode(t, err, codes.NotFound)
+}
+
+func TestGRPCOrderLifecycle(t *testing.T) {
+	h := newHarness(t)
+	api := pb.NewOrderAPIClient(h.grpcConn())
+	tok := h.token("demo-user", "demo-user-secret")
+
+	create := func() string {
+		t.Helper()
+		out, err := api.CreateOrder(h.bearer(tok), &pb.CreateOrderRequest{AmountCents: 1250, Currency: "USD", ItemsJson: `[{"sku":"A"}]`})
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if out.GetStatus() != "PROCESSING" {
+			t.Fatalf("create status = %s", out.GetStatus())
+		}
+		return out.GetOrderId()
+	}
+	confirmed := create()
+	h.dispatch()
+	pending := create()
+
+	got, err := api.GetOrder(h.bearer(tok), &pb.GetOrderRequest{Id: confirmed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.GetStatus() != "CONFIRMED" || got.GetUserId() != "user-demo" || got.GetAmountCents() != 1250 || got.GetCreatedAt() == nil {
+		t.Fatalf("get = %v", got)
+	}
+
+	_, err = api.CreateOrder(h.bearer(tok), &pb.CreateOrderRequest{AmountCents: 0, Currency: "USD", ItemsJson: "[]"})
+	expectCode(t, err, codes.InvalidArgument)
+	_, err = api.CreateOrder(h.bearer(tok), &pb.CreateOrderRequest{UserId: "someone-else", AmountCents: 1, Currency: "USD", ItemsJson: "[]"})
+	expectCode(t, err, codes.PermissionDenied)
+
+	cancelled, err := api.CancelOrder(h.bearer(tok), &pb.CancelOrderRequest{Id: pending})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cancelled.GetStatus() != "CANCELLED" {
+		t.Fatalf("cancel status = %s", cancelled.GetStatus())
+	}
+	_, err = api.CancelOrder(h.bearer(tok), &pb.CancelOrderRequest{Id: pending})
+	expectCode(t, err, codes.FailedPrecondition)
+	_, err = api.CancelOrder(h.bearer(tok), &pb.CancelOrderRequest{Id: confirmed})
+	expectCode(t, err, codes.FailedPrecondition)
+
+	list, err := api.ListOrders(h.bearer(tok), &pb.ListOrdersRequest{Status: "CANCELLED"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.GetOrders()) != 1 || list.GetOrders()[0].GetId() != pending {
+		t.Fatalf("cancelled orders = %v", list.GetOrders())
+	}
+
+	// someone else's order is invisible and cannot be cancelled
+	other := h.token(opsClient, opsSecret)
+	out, err := api.CreateOrder(h.bearer(other), &pb.CreateOrderRequest{UserId: "user-other", AmountCents: 5, Currency: "USD", ItemsJson: "[]"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// another user's order is reported as missing
+	_, err = api.GetOrder(h.bearer(tok), &pb.GetOrderRequest{Id: out.GetOrderId()})
+	expectCode(t, err, codes.NotFound)
+	_, err = api.CancelOrder(h.bearer(tok), &pb.CancelOrderRequest{Id: out.GetOrderId()})
+	expectCode(t, err, codes.NotFound)
+}
+
+func TestGRPCListOrdersPaging(t *testing.T) {
+	h := newHarness(t)
+	api := pb.NewOrderAPIClient(h.grpcConn())
+	tok := h.token("demo-user", "demo-user-secret")
+
+	var ids []string
+	for range 5 {
+		out, err := api.CreateOrder(h.bearer(tok), &pb.CreateOrderRequest{AmountCents: 100, Currency: "USD", ItemsJson: "[]"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, out.GetOrderId())
+	}
+
+	seen := map[string]bool{}
+	req := &pb.ListOrdersRequest{PageSize: 2}
+	pages := 0
+	for {
+		page, err := api.ListOrders(h.bearer(tok), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages++
+		for _, o := range page.GetOrders() {
+			if seen[o.GetId()] {
+				t.Fatalf("order %s listed twice", o.GetId())
+			}
+			seen[o.GetId()] = true
+		}
+		if page.GetNextPageToken() == "" {
+			break
+		}
+		req.PageToken = page.GetNextPageToken()
+	}
+	if len(seen) != len(ids) || pages != 3 {
+		t.Fatalf("listed %d orders in %d pages, want %d in 3", len(seen), pages, len(ids))
+	}
+
+	_, err := api.ListOrders(h.bearer(tok), &pb.ListOrdersRequest{PageToken: "%%%"})
+	expectCode(t, err, codes.InvalidArgument)
+	_, err = api.ListOrders(h.bearer(tok), &pb.ListOrdersRequest{UserId: "user-other"})
+	expectCode(t, err, codes.PermissionDenied)
+
+	// service clients name the user
+	svc := h.token(opsClient, opsSecret)
+	_, err = api.ListOrders(h.bearer(svc), &pb.ListOrdersRequest{})
+	expectCode(t, err, codes.InvalidArgument)
+	list, err := api.ListOrders(h.bearer(svc), &pb.ListOrdersRequest{UserId: "user-demo", PageSize: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.GetOrders()) != len(ids) {
+		t.Fatalf("service list = %d orders, want %d", len(list.GetOrders()), len(ids))
+	}
+}
+
+func TestGRPCWatchOrder(t *testing.T) {
+	h := newHarness(t)
+	api := pb.NewOrderAPIClient(h.grpcConn())
+	tok := h.token("demo-user", "demo-user-secret")
+
+	out, err := api.CreateOrder(h.bearer(tok), &pb.CreateOrderRequest{AmountCents: 100, Currency: "USD", ItemsJson: "[]"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, err := api.WatchOrder(h.bearer(tok), &pb.WatchOrderRequest{Id: out.GetOrderId()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.GetStatus() != "PROCESSING" || first.GetOrderId() != out.GetOrderId() {
+		t.Fatalf("snapshot = %v", first)
+	}
+
+	h.dispatch()
+	ev, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.GetStatus() != "CONFIRMED" || ev.GetEventId() == "" {
+		t.Fatalf("event = %v", ev)
+	}
+	if _, err := stream.Recv(); !errors.Is(err, io.EOF) {
+		t.Fatalf("stream after final status: %v, want EOF", err)
+	}
+
+	// resuming after the final event has nothing left to send
+	resumed, err := api.WatchOrder(h.bearer(tok), &pb.WatchOrderRequest{Id: out.GetOrderId(), LastEventId: ev.GetEventId()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := resumed.Recv(); !errors.Is(err, io.EOF) {
+		t.Fatalf("resumed stream: %v, want EOF", err)
+	}
+
+	// another user's order
+	other := h.token(opsClient, opsSecret)
+	o2, err := api.CreateOrder(h.bearer(other), &pb.CreateOrderRequest{UserId: "user-other", AmountCents: 5, Currency: "USD", ItemsJson: "[]"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	denied, err := api.WatchOrder(h.bearer(tok), &pb.WatchOrderRequest{Id: o2.GetOrderId()})
+	if err == nil {
+		_, err = denied.Recv()
+	}
+	expectCode(t, err, codes.NotFound)
+}
+
+// An order cancelled before the worker picks up its order.created command never reaches order-gw.
+func TestCancelledOrderIsNotForwarded(t *testing.T) {
+	h := newHarness(t)
+	api := pb.NewOrderAPIClient(h.grpcConn())
+	tok := h.token("demo-user", "demo-user-secret")
+
+	cancelled, kept := h.placeOrder(demoClient, tok, validOrder), h.placeOrder(demoClient, tok, validOrder)
+	if _, err := api.CancelOrder(h.bearer(tok), &pb.CancelOrderRequest{Id: cancelled}); err != nil {
+		t.Fatal(err)
+	}
+	if n := h.dispatch(); n != 2 {
+		t.Fatalf("dispatched %d, want 2", n)
+	}
+	if n := h.gw.callCount(); n != 1 {
+		t.Fatalf("order-gw called %d times, want 1", n)
+	}
+
+	for id, want := range map[string]string{cancelled: "CANCELLED", kept: "CONFIRMED"} {
+		got, err := api.GetOrder(h.bearer(tok), &pb.GetOrderRequest{Id: id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.GetStatus() != want {
+			t.Fatalf("order %s = %s, want %s", id, got.GetStatus(), want)
+		}
+	}
+}
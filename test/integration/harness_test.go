@@ -0,0 +1,381 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aq2208/gorder-api/configs"
+	"github.com/aq2208/gorder-api/internal/adapter/grpcapi"
+	httpadapter "github.com/aq2208/gorder-api/internal/adapter/http"
+	"github.com/aq2208/gorder-api/internal/adapter/http/middleware"
+	"github.com/aq2208/gorder-api/internal/adapter/jobs"
+	"github.com/aq2208/gorder-api/internal/adapter/kafka"
+	"github.com/aq2208/gorder-api/internal/adapter/memory"
+	"github.com/aq2208/gorder-api/internal/adapter/queue"
+	"github.com/aq2208/gorder-api/internal/security"
+	"github.com/aq2208/gorder-api/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// The suite runs the real router, middleware and use cases over the in-memory adapters
+// (configs/local-mem.yaml). Rabbit and order-gw are replaced by recording stand-ins so each
+// test decides when an order is dispatched and which status order-gw reports back.
+
+var (
+	cfg       configs.Config
+	configDir string // absolute; tests run in a temp dir
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+
+	var err error
+	configDir, err = filepath.Abs("../../configs")
+	if err != nil {
+		log.Fatal(err)
+	}
+	cfg, err = configs.Load(configDir, "local-mem")
+	if err != nil {
+		log.Fatalf("load config: %v", err)
+	}
+	security.Clients[opsClient] = security.Client{
+		ID: opsClient, Secret: opsSecret, Enabled: true,
+		Perms: []string{"orders.read", "orders.write", "orders.read.all", "webhooks.manage", "jobs.manage"},
+	}
+
+	// the router's logger writes ./logs/app.log; keep it out of the source tree
+	tmp, err := os.MkdirTemp("", "gorder-integration-*")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.Chdir(tmp); err != nil {
+		log.Fatal(err)
+	}
+
+	code := m.Run()
+	_ = os.RemoveAll(tmp)
+	os.Exit(code)
+}
+
+// recordingQueue implements usecase.OrderQueue; Fail makes every publish return an error.
+type recordingQueue struct {
+	mu   sync.Mutex
+	msgs []usecase.CreatedMsg
+	Fail error
+}
+
+func (q *recordingQueue) PublishCreated(ctx context.Context, msg usecase.CreatedMsg) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.Fail != nil {
+		return q.Fail
+	}
+	q.msgs = append(q.msgs, msg)
+	return nil
+}
+
+func (q *recordingQueue) PublishCreatedBatch(ctx context.Context, msgs []usecase.CreatedMsg) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.Fail != nil {
+		return q.Fail
+	}
+	q.msgs = append(q.msgs, msgs...)
+	return nil
+}
+
+// drain returns and clears the published messages.
+func (q *recordingQueue) drain() []usecase.CreatedMsg {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	out := q.msgs
+	q.msgs = nil
+	return out
+}
+
+// fakeGateway implements queue.OrderGateway and reports Status for every accepted order,
+// the way order-gw publishes order.status.changed. An empty Status accepts orders silently.
+type fakeGateway struct {
+	Status  string
+	Fail    error
+	publish func(context.Context, usecase.OrderStatusChangedMsg) error
+
+	mu    sync.Mutex
+	calls []string
+}
+
+func (g *fakeGateway) CreateOrder(ctx context.Context, orderID, userID string, cents int64, currency string) error {
+	g.mu.Lock()
+	g.calls = append(g.calls, orderID)
+	g.mu.Unlock()
+	if g.Fail != nil {
+		return g.Fail
+	}
+	if g.Status == "" {
+		return nil
+	}
+	return g.publish(ctx, usecase.OrderStatusChangedMsg{
+		OrderID: orderID, UserID: userID, Cents: cents, Currency: currency, Status: g.Status,
+	})
+}
+
+func (g *fakeGateway) callCount() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.calls)
+}
+
+type harness struct {
+	t      *testing.T
+	router http.Handler
+	grpc   *grpcapi.OrderAPI
+	auth   *grpcapi.Auth
+	repo   *memory.OrderRepo
+	cache  *memory.Cache
+	events *memory.OrderEvents
+	hooks  *memory.WebhookRepo
+	jobs   *memory.JobRepo
+	runner *usecase.RunJobs
+	idem   *memory.IdempotencyStore
+	queue  *recordingQueue
+	gw     *fakeGateway
+	worker *queue.OrderCreatedHandler
+}
+
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+	return newHarnessWith(t, cfg)
+}
+
+// newHarnessWith builds the suite over a modified copy of the local-mem config.
+func newHarnessWith(t *testing.T, cfg configs.Config) *harness {
+	t.Helper()
+
+	repo := memory.NewOrderRepo()
+	cache := memory.NewCache(cfg.Cache.TTL)
+	idem := memory.NewIdempotencyStore(cfg.Idempotency.TTL)
+	q := &recordingQueue{}
+
+	events := memory.NewOrderEvents()
+	hooks := memory.NewWebhookRepo()
+	status := kafka.NewOrderStatusChangedHandler(repo, cache, events, usecase.NewNotifyWebhooks(repo, hooks))
+	gw := &fakeGateway{Status: "CONFIRMED", publish: status.Handle}
+
+	keys, err := security.LoadKeyStore(cfg)
+	if err != nil {
+		t.Fatalf("keystore: %v", err)
+	}
+	signer, err := security.LoadServerSigner(cfg)
+	if err != nil {
+		t.Fatalf("server signer: %v", err)
+	}
+	replay := security.ProvideReplayGuard(memory.NewNonceStore(), cfg)
+
+	get := usecase.NewGetOrder(repo)
+	watch := usecase.NewWatchOrder(get, events)
+	create := usecase.NewCreateOrder(repo, cache, idem, q)
+	batch := usecase.NewCreateOrderBatch(repo, cache, idem, q, usecase.WithBatchMaxItems(cfg.Batch.MaxItems), usecase.WithBatchMode(cfg.Batch.Mode))
+	cancel := usecase.NewCancelOrder(repo, cache, events)
+	h := httpadapter.NewOrderHandler(create, batch, get, watch, cancel, cfg)
+
+	// small pages and frequent saves so tests cover paging, row groups and resuming
+	jobRepo := memory.NewJobRepo()
+	files, err := jobs.NewLocalFiles(t.TempDir())
+	if err != nil {
+		t.Fatalf("job files: %v", err)
+	}
+	runner := usecase.NewRunJobs(jobRepo, files, create, repo, usecase.WithExportPageSize(2), usecase.WithProgressEvery(2))
+
+	authz := middleware.NewAuthz(cfg)
+	rl := middleware.ProvideRateLimiter(cfg)
+	router := httpadapter.NewRouter(
+		h,
+		httpadapter.NewOrderEventsHandler(watch, cfg),
+		httpadapter.NewWebhookHandler(usecase.NewManageWebhooks(hooks,
+			usecase.WithInsecureWebhookURLs(cfg.Webhooks.AllowHTTP), usecase.WithPrivateWebhookHosts(cfg.Webhooks.AllowPrivate))),
+		httpadapter.NewJobHandler(usecase.NewManageJobs(jobRepo, files, usecase.WithMaxUpload(cfg.Jobs.MaxUploadBytes))),
+		httpadapter.NewTokenHandler(cfg),
+		authz,
+		middleware.ProvideCryptoVerify(cfg, keys, replay, signer),
+		rl,
+		cfg,
+	)
+	orderAPI := grpcapi.NewOrderAPI(create, get, usecase.NewListOrders(repo), cancel, watch)
+
+	return &harness{
+		t:      t,
+		router: router,
+		grpc:   orderAPI,
+		auth:   grpcapi.NewAuth(authz, rl),
+		repo:   repo,
+		cache:  cache,
+		events: events,
+		hooks:  hooks,
+		jobs:   jobRepo,
+		runner: runner,
+		idem:   idem,
+		queue:  q,
+		gw:     gw,
+		worker: queue.NewOrderCreatedHandler(repo, gw),
+	}
+}
+
+// dispatch runs every published order.created message through the worker handler.
+func (h *harness) dispatch() int {
+	h.t.Helper()
+	msgs := h.queue.drain()
+	for _, msg := range msgs {
+		if err := h.worker.HandleCreate(context.Background(), msg); err != nil {
+			h.t.Fatalf("dispatch %s: %v", msg.OrderID, err)
+		}
+	}
+	return len(msgs)
+}
+
+func (h *harness) serve(req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.router.ServeHTTP(w, req)
+	return w
+}
+
+// token issues an access token for one of the registered clients.
+func (h *harness) token(clientID, secret string) string {
+	h.t.Helper()
+	w := h.serve(formRequest("/v1/token", clientID, secret))
+	if w.Code != http.StatusOK {
+		h.t.Fatalf("token %s: %d %s", clientID, w.Code, w.Body)
+	}
+	var out struct {
+		AccessToken string `json:"access_token"`
+	}
+	decode(h.t, w, &out)
+	return out.AccessToken
+}
+
+// seal encrypts and signs body through the /_test helper, bound (v2) to method + path.
+func (h *harness) seal(clientID, method, path string, body any) []byte {
+	h.t.Helper()
+	raw, err := json.Marshal(body)
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	return h.sealRaw(clientID, method, path, raw)
+}
+
+// sealRaw seals a body that is not JSON, such as an import file.
+func (h *harness) sealRaw(clientID, method, path string, raw []byte) []byte {
+	h.t.Helper()
+	path, _, _ = strings.Cut(path, "?") // the signature binds the path, not the query
+	q := url.Values{"client_id": {clientID}, "v": {"2"}, "method": {method}, "path": {path}}
+	req := httptest.NewRequest(http.MethodPost, "/_test/encrypt-sign?"+q.Encode(), bytes.NewReader(raw))
+	w := h.serve(req)
+	if w.Code != http.StatusOK {
+		h.t.Fatalf("encrypt-sign: %d %s", w.Code, w.Body)
+	}
+	return w.Body.Bytes()
+}
+
+// call sends an already sealed envelope.
+func (h *harness) call(method, path, token string, envelope []byte, header http.Header) *httptest.ResponseRecorder {
+	h.t.Helper()
+	req := httptest.NewRequest(method, path, bytes.NewReader(envelope))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	return h.serve(req)
+}
+
+// send seals body for clientID and calls the endpoint with it.
+func (h *harness) send(method, path, clientID, token string, body any, header http.Header) *httptest.ResponseRecorder {
+	h.t.Helper()
+	return h.call(method, path, token, h.seal(clientID, method, path, body), header)
+}
+
+func (h *harness) createOrder(clientID, token, idemKey string, body createBody) *httptest.ResponseRecorder {
+	h.t.Helper()
+	var header http.Header
+	if idemKey != "" {
+		header = http.Header{"X-Idempotency-Key": {idemKey}}
+	}
+	return h.send(http.MethodPost, "/v1/orders", clientID, token, body, header)
+}
+
+// validOrder is a create request for the caller's own user: one item, 19.99 USD.
+var validOrder = createBody{Amount: amount{Cents: 1999, Currency: "USD"}, Items: `[{"sku":"A1","qty":1}]`}
+
+// placeOrder creates body as clientID, expects 202 and returns the order id. The order.created
+// message waits in the queue until dispatch.
+func (h *harness) placeOrder(clientID, token string, body createBody) string {
+	h.t.Helper()
+	w := h.createOrder(clientID, token, "", body)
+	expectStatus(h.t, w, http.StatusAccepted)
+	var out createResp
+	decode(h.t, w, &out)
+	return out.OrderID
+}
+
+func (h *harness) getOrder(clientID, token, id string) *httptest.ResponseRecorder {
+	h.t.Helper()
+	return h.send(http.MethodGet, "/v1/orders/"+id, clientID, token, struct{}{}, nil)
+}
+
+type amount struct {
+	Cents    int64  `json:"cents"`
+	Currency string `json:"currency"`
+}
+
+type createBody struct {
+	UserID string `json:"userId,omitempty"`
+	Amount amount `json:"amount"`
+	Items  string `json:"items"`
+}
+
+type createResp struct {
+	OrderID string `json:"orderId"`
+	Status  string `json:"status"`
+}
+
+type orderResp struct {
+	ID          string `json:"id"`
+	UserID      string `json:"user_id"`
+	Status      string `json:"status"`
+	AmountCents int64  `json:"amount_cents"`
+	Currency    string `json:"currency"`
+	Failure     string `json:"failure_reason"`
+}
+
+func decode(t *testing.T, w *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("decode %q: %v", w.Body.String(), err)
+	}
+}
+
+func expectStatus(t *testing.T, w *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	if w.Code != want {
+		t.Fatalf("status = %d, want %d (body %s)", w.Code, want, w.Body)
+	}
+}
+
+var errBrokerDown = errors.New("broker unavailable")
+
+func formRequest(path, clientID, secret string) *http.Request {
+	form := url.Values{"client_id": {clientID}, "client_secret": {secret}}
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
@@ -0,0 +1,396 @@
+package integration
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+type jobResp struct {
+	ID        string         `json:"id"`
+	Kind      string         `json:"kind"`
+	Format    string         `json:"format"`
+	Status    string         `json:"status"`
+	Total     int            `json:"total"`
+	Processed int            `json:"processed"`
+	Succeeded int            `json:"succeeded"`
+	Failed    int            `json:"failed"`
+	Progress  float64        `json:"progress"`
+	Error     string         `json:"error"`
+	Filter    map[string]any `json:"filter"`
+}
+
+type rowError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// importFile uploads file as an import job and returns it as queued.
+func (h *harness) importFile(clientID, token, format, file string) jobResp {
+	h.t.Helper()
+	path := "/v1/jobs/import?format=" + format
+	w := h.call(http.MethodPost, path, token, h.sealRaw(clientID, http.MethodPost, path, []byte(file)), nil)
+	expectStatus(h.t, w, http.StatusAccepted)
+	var j jobResp
+	decode(h.t, w, &j)
+	return j
+}
+
+func (h *harness) export(clientID, token string, body map[string]any) jobResp {
+	h.t.Helper()
+	w := h.send(http.MethodPost, "/v1/jobs/export", clientID, token, body, nil)
+	expectStatus(h.t, w, http.StatusAccepted)
+	var j jobResp
+	decode(h.t, w, &j)
+	return j
+}
+
+func (h *harness) job(clientID, token, id string) jobResp {
+	h.t.Helper()
+	w := h.send(http.MethodGet, "/v1/jobs/"+id, clientID, token, struct{}{}, nil)
+	expectStatus(h.t, w, http.StatusOK)
+	var j jobResp
+	decode(h.t, w, &j)
+	return j
+}
+
+// runJobs runs queued jobs until none is left, the way the runner does between ticks.
+func (h *harness) runJobs() int {
+	h.t.Helper()
+	n := 0
+	for {
+		j, err := h.runner.Execute(context.Background())
+		if err != nil {
+			h.t.Fatalf("run job: %v", err)
+		}
+		if j == nil {
+			return n
+		}
+		n++
+	}
+}
+
+func lines(t *testing.T, body *bytes.Buffer) []string {
+	t.Helper()
+	var out []string
+	sc := bufio.NewScanner(body)
+	for sc.Scan() {
+		out = append(out, sc.Text())
+	}
+	return out
+}
+
+func TestJobImportCSV(t *testing.T) {
+	h := newHarness(t)
+	tok := h.token(opsClient, opsSecret)
+
+	file := "\ufeffuser_id,amount_cents,currency,items_json,idempotency_key,note\n" +
+		`u-1,100,USD,"[{""sku"":""A""}]",,first` + "\n" +
+		"u-2,abc,USD,[],,bad amount\n" +
+		"u-3,200,,[],,no currency\n" +
+		"u-4,300,EUR,[],k-1,\n" +
+		"u-4,300,EUR,[],k-1,same key\n" +
+		",150,USD,[],,no user\n"
+	j := h.importFile(opsClient, tok, "csv", file)
+	if j.Kind != "import" || j.Status != "queued" || j.Progress != 0 {
+		t.Fatalf("submitted job = %+v", j)
+	}
+
+	// not finished yet: nothing to report
+	w := h.send(http.MethodGet, "/v1/jobs/"+j.ID+"/errors", opsClient, tok, struct{}{}, nil)
+	expectStatus(t, w, http.StatusConflict)
+
+	if n := h.runJobs(); n != 1 {
+		t.Fatalf("ran %d jobs, want 1", n)
+	}
+	got := h.job(opsClient, tok, j.ID)
+	if got.Status != "completed" || got.Total != 6 || got.Processed != 6 || got.Succeeded != 3 || got.Failed != 3 || got.Progress != 1 {
+		t.Fatalf("finished job = %+v", got)
+	}
+	// the repeated key replays the first order instead of creating another
+	if n := h.dispatch(); n != 2 {
+		t.Fatalf("created %d orders, want 2", n)
+	}
+
+	w = h.send(http.MethodGet, "/v1/jobs/"+j.ID+"/errors", opsClient, tok, struct{}{}, nil)
+	expectStatus(t, w, http.StatusOK)
+	var report []rowError
+	for _, l := range lines(t, w.Body) {
+		var e rowError
+		if err := json.Unmarshal([]byte(l), &e); err != nil {
+			t.Fatalf("report line %q: %v", l, err)
+		}
+		report = append(report, e)
+	}
+	want := []rowError{{3, "invalid"}, {4, "invalid"}, {7, "invalid"}}
+	if len(report) != len(want) {
+		t.Fatalf("report = %+v, want %+v", report, want)
+	}
+	for i := range want {
+		if report[i] != want[i] {
+			t.Fatalf("report[%d] = %+v, want %+v", i, report[i], want[i])
+		}
+	}
+
+	// an import has no result file
+	w = h.send(http.MethodGet, "/v1/jobs/"+j.ID+"/result", opsClient, tok, struct{}{}, nil)
+	expectStatus(t, w, http.StatusNotFound)
+}
+
+func TestJobImportNDJSONAsEndUser(t *testing.T) {
+	h := newHarness(t)
+	tok := h.token("demo-user", "demo-user-secret")
+
+	file := `{"amount_cents":100,"currency":"USD","items_json":"[]"}` + "\n" +
+		"\n" +
+		`{"user_id":"someone-else","amount_cents":100,"currency":"USD","items_json":"[]"}` + "\n" +
+		`{"amount_cents":` + "\n" +
+		`{"user_id":"user-demo","amount_cents":250,"currency":"VND","items_json":"[{\"sku\":\"B\"}]"}` + "\n"
+	j := h.importFile("demo-user", tok, "ndjson", file)
+	h.runJobs()
+
+	got := h.job("demo-user", tok, j.ID)
+	if got.Status != "completed" || got.Total != 4 || got.Succeeded != 2 || got.Failed != 2 {
+		t.Fatalf("finished job = %+v", got)
+	}
+	for _, msg := range h.queue.drain() {
+		if msg.UserID != "user-demo" {
+			t.Fatalf("imported order for %q, want user-demo", msg.UserID)
+		}
+	}
+
+	w := h.send(http.MethodGet, "/v1/jobs/"+j.ID+"/errors", "demo-user", tok, struct{}{}, nil)
+	expectStatus(t, w, http.StatusOK)
+	report := lines(t, w.Body)
+	if len(report) != 2 || !strings.Contains(report[0], `"line":3,"error":"forbidden"`) || !strings.Contains(report[1], `"line":4,"error":"invalid"`) {
+		t.Fatalf("report = %q", report)
+	}
+
+	// jobs belong to the client that submitted them
+	other := h.token(opsClient, opsSecret)
+	w = h.send(http.MethodGet, "/v1/jobs/"+j.ID, opsClient, other, struct{}{}, nil)
+	expectStatus(t, w, http.StatusNotFound)
+}
+
+func TestJobImportResumesAfterRestart(t *testing.T) {
+	h := newHarness(t)
+	tok := h.token(opsClient, opsSecret)
+
+	var file strings.Builder
+	file.WriteString("user_id,amount_cents,currency,items_json\n")
+	for range 5 {
+		file.WriteString("u-1,100,USD,[]\n")
+	}
+	j := h.importFile(opsClient, tok, "csv", file.String())
+
+	// a runner claimed the job, created the first two orders and died before saving again
+	claimed, err := h.jobs.ClaimJob(context.Background(), 0)
+	if err != nil || claimed == nil {
+		t.Fatalf("claim: %v %v", claimed, err)
+	}
+	claimed.Total, claimed.Processed, claimed.Succeeded = 5, 2, 2
+	if err := h.jobs.SaveJob(context.Background(), claimed, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := h.runJobs(); n != 1 {
+		t.Fatalf("ran %d jobs, want 1 (expired claim is taken over)", n)
+	}
+	got := h.job(opsClient, tok, j.ID)
+	if got.Status != "completed" || got.Processed != 5 || got.Succeeded != 5 {
+		t.Fatalf("resumed job = %+v", got)
+	}
+	if n := h.dispatch(); n != 3 {
+		t.Fatalf("created %d orders after resume, want 3", n)
+	}
+}
+
+func TestJobImportRejectsBadUploads(t *testing.T) {
+	h := newHarness(t)
+	tok := h.token(opsClient, opsSecret)
+
+	for name, path := range map[string]string{
+		"unknown format": "/v1/jobs/import?format=xml",
+		"no format":      "/v1/jobs/import",
+	} {
+		w := h.call(http.MethodPost, path, tok, h.sealRaw(opsClient, http.MethodPost, path, []byte("a,b\n")), nil)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", name, w.Code)
+		}
+	}
+
+	// a CSV header without the required columns fails the whole job
+	j := h.importFile(opsClient, tok, "csv", "user,amount\nu-1,100\n")
+	h.runJobs()
+	if got := h.job(opsClient, tok, j.ID); got.Status != "failed" || got.Error == "" {
+		t.Fatalf("job = %+v, want failed with an error", got)
+	}
+
+	// without jobs.manage
+	ana := h.token("svc-analytics", "ana-secret")
+	path := "/v1/jobs/import?format=csv"
+	w := h.call(http.MethodPost, path, ana, h.sealRaw("svc-analytics", http.MethodPost, path, []byte("x")), nil)
+	expectStatus(t, w, http.StatusForbidden)
+}
+
+func TestJobExportFormats(t *testing.T) {
+	h := newHarness(t)
+	tok := h.token(opsClient, opsSecret)
+	for i := range 5 {
+		h.placeOrder(opsClient, tok, createBody{UserID: "user-a", Amount: amount{Cents: int64(100 * (i + 1)), Currency: "USD"}, Items: "[]"})
+		if i == 1 {
+			h.dispatch() // the first two are confirmed
+		}
+	}
+	h.placeOrder(opsClient, tok, createBody{UserID: "user-b", Amount: amount{Cents: 100, Currency: "USD"}, Items: "[]"})
+
+	nd := h.export(opsClient, tok, map[string]any{"format": "ndjson", "filter": map[string]any{"user_id": "user-a"}})
+	csvJob := h.export(opsClient, tok, map[string]any{"format": "csv", "filter": map[string]any{"user_id": "user-a", "status": "CONFIRMED"}})
+	pq := h.export(opsClient, tok, map[string]any{"format": "parquet"})
+
+	w := h.send(http.MethodGet, "/v1/jobs/"+nd.ID+"/result", opsClient, tok, struct{}{}, nil)
+	expectStatus(t, w, http.StatusConflict)
+
+	if n := h.runJobs(); n != 3 {
+		t.Fatalf("ran %d jobs, want 3", n)
+	}
+
+	// ndjson: one order per line, oldest first
+	w = h.send(http.MethodGet, "/v1/jobs/"+nd.ID+"/result", opsClient, tok, struct{}{}, nil)
+	expectStatus(t, w, http.StatusOK)
+	if ct := w.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Fatalf("content-type = %q", ct)
+	}
+	rows := lines(t, w.Body)
+	if len(rows) != 5 {
+		t.Fatalf("ndjson rows = %d, want 5", len(rows))
+	}
+	var prev int64
+	for _, l := range rows {
+		var o struct {
+			UserID      string `json:"user_id"`
+			AmountCents int64  `json:"amount_cents"`
+		}
+		if err := json.Unmarshal([]byte(l), &o); err != nil {
+			t.Fatalf("row %q: %v", l, err)
+		}
+		if o.UserID != "user-a" || o.AmountCents <= prev {
+			t.Fatalf("row %q out of filter or order", l)
+		}
+		prev = o.AmountCents
+	}
+	if got := h.job(opsClient, tok, nd.ID); got.Total != 5 || got.Progress != 1 || got.Filter["user_id"] != "user-a" {
+		t.Fatalf("ndjson job = %+v", got)
+	}
+
+	// csv: header plus the confirmed orders
+	w = h.send(http.MethodGet, "/v1/jobs/"+csvJob.ID+"/result", opsClient, tok, struct{}{}, nil)
+	expectStatus(t, w, http.StatusOK)
+	recs, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 3 || recs[0][0] != "id" || recs[1][3] != "CONFIRMED" || recs[2][3] != "CONFIRMED" {
+		t.Fatalf("csv = %q", recs)
+	}
+
+	// parquet: a file any Parquet reader opens, one row group per export page
+	w = h.send(http.MethodGet, "/v1/jobs/"+pq.ID+"/result", opsClient, tok, struct{}{}, nil)
+	expectStatus(t, w, http.StatusOK)
+	if ct := w.Header().Get("Content-Type"); ct != "application/vnd.apache.parquet" {
+		t.Fatalf("content-type = %q", ct)
+	}
+	body := bytes.NewReader(w.Body.Bytes())
+	f, err := parquet.OpenFile(body, body.Size())
+	if err != nil {
+		t.Fatalf("open parquet: %v", err)
+	}
+	if n := len(f.RowGroups()); n != 3 || f.NumRows() != 6 {
+		t.Fatalf("parquet = %d rows in %d row groups, want 6 in 3", f.NumRows(), n)
+	}
+	if fields := f.Schema().Fields(); fields[0].Name() != "id" || fields[len(fields)-1].Name() != "updated_at" {
+		t.Fatalf("parquet schema = %s", f.Schema())
+	}
+	type exported struct {
+		ID          string    `parquet:"id"`
+		UserID      string    `parquet:"user_id"`
+		TenantID    *string   `parquet:"tenant_id,optional"`
+		AmountCents int64     `parquet:"amount_cents"`
+		ItemsJSON   string    `parquet:"items_json"`
+		CreatedAt   time.Time `parquet:"created_at"`
+	}
+	out, err := parquet.Read[exported](body, body.Size())
+	if err != nil {
+		t.Fatalf("read parquet: %v", err)
+	}
+	if r := out[5]; r.UserID != "user-b" || r.AmountCents != 100 || r.TenantID != nil || r.ItemsJSON != "[]" || r.CreatedAt.IsZero() {
+		t.Fatalf("last parquet row = %+v", r)
+	}
+}
+
+func TestJobExportScope(t *testing.T) {
+	h := newHarness(t)
+	svc := h.token(opsClient, opsSecret)
+	demo := h.token("demo-user", "demo-user-secret")
+	for range 2 {
+		h.placeOrder("demo-user", demo, validOrder)
+	}
+	for range 3 {
+		h.placeOrder(opsClient, svc, createBody{UserID: "user-b", Amount: amount{Cents: 100, Currency: "USD"}, Items: "[]"})
+	}
+
+	// an end user exports only their own orders, whatever the filter asks for
+	w := h.send(http.MethodPost, "/v1/jobs/export", "demo-user", demo, map[string]any{"format": "ndjson", "filter": map[string]any{"user_id": "user-b"}}, nil)
+	expectStatus(t, w, http.StatusForbidden)
+	j := h.export("demo-user", demo, map[string]any{"format": "ndjson"})
+	if j.Filter["user_id"] != "user-demo" {
+		t.Fatalf("filter = %v, want bound to user-demo", j.Filter)
+	}
+
+	// a time window that matches nothing still yields an (empty) file
+	past := time.Now().Add(-time.Hour)
+	empty := h.export(opsClient, svc, map[string]any{"format": "csv", "filter": map[string]any{
+		"created_from": past.Add(-time.Hour), "created_to": past,
+	}})
+	h.runJobs()
+
+	w = h.send(http.MethodGet, "/v1/jobs/"+j.ID+"/result", "demo-user", demo, struct{}{}, nil)
+	expectStatus(t, w, http.StatusOK)
+	if rows := lines(t, w.Body); len(rows) != 2 {
+		t.Fatalf("end-user export = %d rows, want 2", len(rows))
+	}
+	w = h.send(http.MethodGet, "/v1/jobs/"+empty.ID+"/result", opsClient, svc, struct{}{}, nil)
+	expectStatus(t, w, http.StatusOK)
+	if rows := lines(t, w.Body); len(rows) != 1 {
+		t.Fatalf("empty export = %q, want only the header", rows)
+	}
+
+	for name, body := range map[string]map[string]any{
+		"bad format": {"format": "xlsx"},
+		"bad window": {"format": "csv", "filter": map[string]any{"created_from": past, "created_to": past.Add(-time.Minute)}},
+	} {
+		w := h.send(http.MethodPost, "/v1/jobs/export", opsClient, svc, body, nil)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", name, w.Code)
+		}
+	}
+
+	// listing is per client, newest first
+	w = h.send(http.MethodGet, "/v1/jobs?limit=10", opsClient, svc, struct{}{}, nil)
+	expectStatus(t, w, http.StatusOK)
+	var list struct {
+		Jobs []jobResp `json:"jobs"`
+	}
+	decode(t, w, &list)
+	if len(list.Jobs) != 1 || list.Jobs[0].ID != empty.ID {
+		t.Fatalf("jobs = %+v", list.Jobs)
+	}
+}
@@ -0,0 +1,114 @@
+package integration
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aq2208/gorder-api/internal/security"
+)
+
+// detachedJWS signs body for method + path through the /_test helper and returns the payload and
+// the X-JWS-Signature value.
+func (h *harness) detachedJWS(clientID, method, path string, body []byte) ([]byte, string) {
+	h.t.Helper()
+	q := url.Values{"client_id": {clientID}, "format": {"jws"}, "method": {method}, "path": {path}}
+	w := h.serve(httptest.NewRequest(http.MethodPost, "/_test/encrypt-sign?"+q.Encode(), bytes.NewReader(body)))
+	expectStatus(h.t, w, http.StatusOK)
+	var out struct {
+		Payload string `json:"payload"`
+		JWS     string `json:"jws"`
+	}
+	decode(h.t, w, &out)
+	return []byte(out.Payload), out.JWS
+}
+
+func (h *harness) callJWS(method, path, token, contentType string, payload []byte, jws string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-JWS-Signature", jws)
+	return h.serve(req)
+}
+
+func TestDetachedJWSBindsRoute(t *testing.T) {
+	h := newHarness(t)
+	tok := h.token(demoClient, demoSecret)
+	id, other := h.placeOrder(demoClient, tok, validOrder), h.placeOrder(demoClient, tok, validOrder)
+	path := "/v1/orders/" + id
+
+	payload, jws := h.detachedJWS(demoClient, http.MethodGet, path, []byte("{}"))
+	expectStatus(t, h.callJWS(http.MethodGet, path, tok, "application/json", payload, jws), http.StatusOK)
+
+	// the same signature replayed on another route, or sent with a body type it does not sign
+	payload, jws = h.detachedJWS(demoClient, http.MethodGet, path, []byte("{}"))
+	expectStatus(t, h.callJWS(http.MethodGet, "/v1/orders/"+other, tok, "application/json", payload, jws), http.StatusUnauthorized)
+	expectStatus(t, h.callJWS(http.MethodGet, path, tok, "text/plain", payload, jws), http.StatusUnsupportedMediaType)
+	expectStatus(t, h.callJWS(http.MethodGet, path, tok, "application/jose", payload, jws), http.StatusBadRequest)
+
+	// a validly signed JWS without htm/htu is not accepted as route-bound
+	keys, err := security.LoadKeyStore(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := keys.Lookup(demoClient, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := make([]byte, 16)
+	_, _ = rand.Read(nonce)
+	unbound, err := security.SignJWS(key.Service, security.JOSEHeader{
+		Kid: key.KeyID, Iat: time.Now().Unix(), Nonce: base64.RawURLEncoding.EncodeToString(nonce),
+	}, []byte("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := h.callJWS(http.MethodGet, path, tok, "application/json", []byte("{}"), security.DetachJWS(unbound))
+	expectStatus(t, w, http.StatusUnauthorized)
+}
+
+// A client without keys of its own gets the legacy key only when crypto.legacy_key_fallback is on.
+func TestLegacyKeyFallbackIsOptIn(t *testing.T) {
+	off := cfg
+	off.CryptoConfig.LegacyKeyFallback = false
+	keys, err := security.LoadKeyStore(off)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.Lookup(demoClient, ""); !errors.Is(err, security.ErrUnknownKey) {
+		t.Fatalf("lookup without fallback: %v, want %v", err, security.ErrUnknownKey)
+	}
+	if _, err := keys.Lookup(security.LegacyClient, ""); !errors.Is(err, security.ErrUnknownKey) {
+		t.Fatalf("lookup of the legacy scope itself: %v", err)
+	}
+
+	on := cfg
+	on.CryptoConfig.LegacyKeyFallback = true
+	if keys, err = security.LoadKeyStore(on); err != nil {
+		t.Fatal(err)
+	}
+	key, err := keys.Lookup(demoClient, "")
+	if err != nil || key.ClientID != security.LegacyClient {
+		t.Fatalf("lookup with fallback: %+v %v", key, err)
+	}
+}
+
+// The /_test helpers sign and decrypt for any client, so they exist only in dev and local-mem.
+func TestCryptoHelpersOnlyInDev(t *testing.T) {
+	for env, want := range map[string]int{"local-mem": http.StatusOK, "dev": http.StatusOK, "stg": http.StatusNotFound, "prod": http.StatusNotFound} {
+		c := cfg
+		c.Env = env
+		h := newHarnessWith(t, c)
+		q := url.Values{"client_id": {demoClient}}
+		w := h.serve(httptest.NewRequest(http.MethodPost, "/_test/encrypt-sign?"+q.Encode(), bytes.NewReader([]byte("{}"))))
+		if w.Code != want {
+			t.Fatalf("%s: /_test/encrypt-sign = %d, want %d", env, w.Code, want)
+		}
+	}
+}
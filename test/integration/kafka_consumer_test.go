@@ -0,0 +1,137 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/aq2208/gorder-api/internal/adapter/kafka"
+	"github.com/aq2208/gorder-api/internal/usecase"
+)
+
+// fakeGroup hands one claim with msgs to the handler per Consume call and records marked offsets.
+type fakeGroup struct {
+	sarama.ConsumerGroup
+	msgs   []*sarama.ConsumerMessage
+	ctx    context.Context
+	mu     sync.Mutex
+	marked []int64
+}
+
+type fakeSession struct {
+	sarama.ConsumerGroupSession
+	g *fakeGroup
+}
+
+func (s fakeSession) Context() context.Context { return s.g.ctx }
+func (s fakeSession) MarkMessage(msg *sarama.ConsumerMessage, _ string) {
+	s.g.mu.Lock()
+	s.g.marked = append(s.g.marked, msg.Offset)
+	s.g.mu.Unlock()
+}
+
+type fakeClaim struct {
+	sarama.ConsumerGroupClaim
+	ch chan *sarama.ConsumerMessage
+}
+
+func (c fakeClaim) Messages() <-chan *sarama.ConsumerMessage { return c.ch }
+
+func (g *fakeGroup) Consume(ctx context.Context, _ []string, h sarama.ConsumerGroupHandler) error {
+	g.ctx = ctx
+	ch := make(chan *sarama.ConsumerMessage, len(g.msgs))
+	for _, m := range g.msgs {
+		ch <- m
+	}
+	close(ch)
+	_ = h.ConsumeClaim(fakeSession{g: g}, fakeClaim{ch: ch})
+	<-ctx.Done()
+	return nil
+}
+
+func (g *fakeGroup) offsets() []int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]int64(nil), g.marked...)
+}
+
+func statusMessages(t *testing.T, ids ...string) []*sarama.ConsumerMessage {
+	var out []*sarama.ConsumerMessage
+	for i, id := range ids {
+		raw, err := json.Marshal(usecase.OrderStatusChangedMsg{OrderID: id, Status: "CONFIRMED"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, &sarama.ConsumerMessage{Offset: int64(i), Value: raw})
+	}
+	return out
+}
+
+// A failing status update is retried before its offset is committed, never skipped.
+func TestStatusConsumerRetriesBeforeCommitting(t *testing.T) {
+	g := &fakeGroup{msgs: statusMessages(t, "o-1", "o-2")}
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	c := kafka.NewConsumer(g, []string{"order.status.changed"}, func(ctx context.Context, ev usecase.OrderStatusChangedMsg) error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, ev.OrderID)
+		if len(calls) <= 2 {
+			return errors.New("mysql unavailable")
+		}
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() { _ = c.Start(ctx) }()
+	for len(g.offsets()) < 2 && ctx.Err() == nil {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got := g.offsets(); len(got) != 2 || got[0] != 0 || got[1] != 1 {
+		t.Fatalf("marked offsets = %v, want [0 1]", got)
+	}
+	if len(calls) != 4 || calls[2] != "o-1" || calls[3] != "o-2" {
+		t.Fatalf("handled %v, want o-1 three times then o-2", calls)
+	}
+}
+
+// On shutdown mid-retry neither the failed message nor any later one is committed.
+func TestStatusConsumerDoesNotCommitPastFailure(t *testing.T) {
+	g := &fakeGroup{msgs: statusMessages(t, "o-1", "o-2")}
+	c := kafka.NewConsumer(g, []string{"order.status.changed"}, func(ctx context.Context, ev usecase.OrderStatusChangedMsg) error {
+		if ev.OrderID == "o-1" {
+			return errors.New("mysql unavailable")
+		}
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	_ = c.Start(ctx)
+	if got := g.offsets(); len(got) != 0 {
+		t.Fatalf("marked offsets = %v after a failed message", got)
+	}
+
+	// an event for an order that does not exist cannot succeed later; it is skipped
+	g = &fakeGroup{msgs: statusMessages(t, "missing")}
+	c = kafka.NewConsumer(g, []string{"order.status.changed"}, func(context.Context, usecase.OrderStatusChangedMsg) error {
+		return usecase.ErrNotFound
+	})
+	ctx, cancel = context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_ = c.Start(ctx)
+	if got := g.offsets(); len(got) != 1 {
+		t.Fatalf("marked offsets = %v for an unknown order", got)
+	}
+}
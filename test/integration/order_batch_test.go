@@ -0,0 +1,191 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aq2208/gorder-api/internal/usecase"
+)
+
+type batchOrder struct {
+	UserID         string `json:"userId,omitempty"`
+	Amount         amount `json:"amount"`
+	Items          string `json:"items"`
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+}
+
+type batchBody struct {
+	Mode   string       `json:"mode,omitempty"`
+	Orders []batchOrder `json:"orders"`
+}
+
+type batchResp struct {
+	Created int `json:"created"`
+	Failed  int `json:"failed"`
+	Results []struct {
+		Index    int    `json:"index"`
+		OrderID  string `json:"orderId"`
+		Status   string `json:"status"`
+		Replayed bool   `json:"replayed"`
+		Error    string `json:"error"`
+	} `json:"results"`
+}
+
+func (h *harness) createBatch(token string, body batchBody) *httptest.ResponseRecorder {
+	h.t.Helper()
+	return h.send(http.MethodPost, "/v1/orders:batch", "demo-user", token, body, nil)
+}
+
+func okOrder(key string) batchOrder {
+	return batchOrder{Amount: amount{Cents: 500, Currency: "USD"}, Items: `[{"sku":"B"}]`, IdempotencyKey: key}
+}
+
+func TestBatchPerItem(t *testing.T) {
+	h := newHarness(t)
+	tok := h.token("demo-user", "demo-user-secret")
+
+	bad := okOrder("")
+	bad.Amount.Cents = 0
+	foreign := okOrder("")
+	foreign.UserID = "someone-else"
+	body := batchBody{Mode: "per_item", Orders: []batchOrder{okOrder("b-1"), bad, foreign, okOrder("b-1"), okOrder("b-2")}}
+
+	w := h.createBatch(tok, body)
+	expectStatus(t, w, http.StatusOK)
+	var out batchResp
+	decode(t, w, &out)
+	if out.Created != 2 || out.Failed != 3 || len(out.Results) != 5 {
+		t.Fatalf("batch = %+v", out)
+	}
+	want := []string{"", "invalid", "forbidden", "duplicate", ""}
+	for i, r := range out.Results {
+		if r.Index != i || r.Error != want[i] {
+			t.Fatalf("result %d = %+v, want error %q", i, r, want[i])
+		}
+	}
+	if out.Results[0].Status != "PROCESSING" || out.Results[0].OrderID == "" {
+		t.Fatalf("created item = %+v", out.Results[0])
+	}
+
+	// one confirmed publish for both orders
+	if n := h.dispatch(); n != 2 {
+		t.Fatalf("dispatched %d, want 2", n)
+	}
+	var got orderResp
+	decode(t, h.getOrder("demo-user", tok, out.Results[4].OrderID), &got)
+	if got.Status != "CONFIRMED" || got.UserID != "user-demo" {
+		t.Fatalf("order = %+v", got)
+	}
+
+	// resending replays by idempotency key
+	w = h.createBatch(tok, batchBody{Orders: []batchOrder{okOrder("b-1")}})
+	expectStatus(t, w, http.StatusOK)
+	var again batchResp
+	decode(t, w, &again)
+	if r := again.Results[0]; !r.Replayed || r.OrderID != out.Results[0].OrderID {
+		t.Fatalf("replay = %+v", r)
+	}
+	if n := h.dispatch(); n != 0 {
+		t.Fatalf("replay dispatched %d", n)
+	}
+}
+
+func TestBatchAtomic(t *testing.T) {
+	h := newHarness(t)
+	tok := h.token("demo-user", "demo-user-secret")
+
+	bad := okOrder("a-3")
+	bad.Amount.Currency = ""
+	w := h.createBatch(tok, batchBody{Mode: "atomic", Orders: []batchOrder{okOrder("a-1"), okOrder("a-2"), bad}})
+	expectStatus(t, w, http.StatusOK)
+	var out batchResp
+	decode(t, w, &out)
+	if out.Created != 0 || out.Results[0].Error != "aborted" || out.Results[1].Error != "aborted" || out.Results[2].Error != "invalid" {
+		t.Fatalf("atomic batch = %+v", out)
+	}
+	if n := h.dispatch(); n != 0 {
+		t.Fatalf("aborted batch dispatched %d", n)
+	}
+
+	// a key in flight elsewhere aborts the batch and releases the keys it had locked
+	if ok, _ := h.idem.TryLock(context.Background(), usecase.ScopedIdempotencyKey(demoClient, demoUser, "busy")); !ok {
+		t.Fatal("lock busy")
+	}
+	w = h.createBatch(tok, batchBody{Mode: "atomic", Orders: []batchOrder{okOrder("a-1"), okOrder("busy")}})
+	decode(t, w, &out)
+	if out.Created != 0 || out.Results[0].Error != "aborted" || out.Results[1].Error != "duplicate" {
+		t.Fatalf("atomic batch with busy key = %+v", out)
+	}
+
+	w = h.createBatch(tok, batchBody{Mode: "atomic", Orders: []batchOrder{okOrder("a-1"), okOrder("a-2")}})
+	decode(t, w, &out)
+	if out.Created != 2 || out.Failed != 0 {
+		t.Fatalf("atomic retry = %+v", out)
+	}
+	if n := h.dispatch(); n != 2 {
+		t.Fatalf("dispatched %d, want 2", n)
+	}
+}
+
+func TestBatchPublishFailureIsRetryable(t *testing.T) {
+	h := newHarness(t)
+	tok := h.token("demo-user", "demo-user-secret")
+
+	h.queue.Fail = errBrokerDown
+	w := h.createBatch(tok, batchBody{Orders: []batchOrder{okOrder("p-1"), okOrder("p-2")}})
+	expectStatus(t, w, http.StatusInternalServerError)
+	if n := h.dispatch(); n != 0 {
+		t.Fatalf("dispatched %d after a failed publish", n)
+	}
+
+	// the keys were released: the retry publishes the stored orders instead of creating new ones
+	h.queue.Fail = nil
+	w = h.createBatch(tok, batchBody{Orders: []batchOrder{okOrder("p-1"), okOrder("p-2")}})
+	expectStatus(t, w, http.StatusOK)
+	var out batchResp
+	decode(t, w, &out)
+	for i, r := range out.Results {
+		rec, err := h.repo.GetByIdemKey(context.Background(), demoClient, demoUser, []string{"p-1", "p-2"}[i])
+		if err != nil {
+			t.Fatalf("stored order %d: %v", i, err)
+		}
+		if r.Error != "" || r.OrderID != rec.ID {
+			t.Fatalf("retry after publish failure = %+v, want stored order %s", r, rec.ID)
+		}
+	}
+	if n := h.dispatch(); n != 2 {
+		t.Fatalf("dispatched %d, want 2", n)
+	}
+
+	// now the keys are remembered and replay
+	w = h.createBatch(tok, batchBody{Orders: []batchOrder{okOrder("p-1")}})
+	var again batchResp
+	decode(t, w, &again)
+	if r := again.Results[0]; !r.Replayed || r.OrderID != out.Results[0].OrderID {
+		t.Fatalf("replay = %+v", r)
+	}
+}
+
+func TestBatchLimits(t *testing.T) {
+	h := newHarness(t)
+	tok := h.token("demo-user", "demo-user-secret")
+
+	expectStatus(t, h.createBatch(tok, batchBody{}), http.StatusBadRequest)
+	expectStatus(t, h.createBatch(tok, batchBody{Mode: "sometimes", Orders: []batchOrder{okOrder("")}}), http.StatusBadRequest)
+
+	many := make([]batchOrder, cfg.Batch.MaxItems+1)
+	for i := range many {
+		many[i] = okOrder("")
+	}
+	expectStatus(t, h.createBatch(tok, batchBody{Orders: many}), http.StatusRequestEntityTooLarge)
+
+	w := h.send(http.MethodPost, "/v1/orders:explode", "demo-user", tok, batchBody{Orders: []batchOrder{okOrder("")}}, nil)
+	expectStatus(t, w, http.StatusNotFound)
+
+	// read-only clients cannot batch-create
+	ana := h.token("svc-analytics", "ana-secret")
+	w = h.send(http.MethodPost, "/v1/orders:batch", "svc-analytics", ana, batchBody{Orders: []batchOrder{okOrder("")}}, nil)
+	expectStatus(t, w, http.StatusForbidden)
+}
@@ -0,0 +1,118 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aq2208/gorder-api/internal/usecase"
+)
+
+func (h *harness) getOrderIf(clientID, token, path string, header http.Header) *httptest.ResponseRecorder {
+	h.t.Helper()
+	return h.send(http.MethodGet, path, clientID, token, struct{}{}, header)
+}
+
+func TestConditionalGetOrder(t *testing.T) {
+	h := newHarness(t)
+	tok := h.token("demo-user", "demo-user-secret")
+	id := h.placeOrder("demo-user", tok, validOrder)
+	path := "/v1/orders/" + id
+
+	w := h.getOrder("demo-user", tok, id)
+	expectStatus(t, w, http.StatusOK)
+	etag, lastMod := w.Header().Get("ETag"), w.Header().Get("Last-Modified")
+	if etag == "" {
+		t.Fatal("no ETag")
+	}
+	if _, err := http.ParseTime(lastMod); err != nil {
+		t.Fatalf("Last-Modified %q: %v", lastMod, err)
+	}
+	if cc := w.Header().Get("Cache-Control"); cc != "private, no-cache" {
+		t.Fatalf("in-flight Cache-Control = %q", cc)
+	}
+
+	for name, header := range map[string]http.Header{
+		"etag":          {"If-None-Match": {etag}},
+		"weak etag":     {"If-None-Match": {"W/" + etag}},
+		"etag list":     {"If-None-Match": {`"41", ` + etag}},
+		"any":           {"If-None-Match": {"*"}},
+		"last-modified": {"If-Modified-Since": {lastMod}},
+		"later":         {"If-Modified-Since": {time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)}},
+	} {
+		w := h.getOrderIf("demo-user", tok, path, header)
+		if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+			t.Fatalf("%s: status = %d, body %q; want bodiless 304", name, w.Code, w.Body)
+		}
+		if w.Header().Get("ETag") != etag || w.Header().Get("Cache-Control") == "" {
+			t.Fatalf("%s: 304 without validators: %v", name, w.Header())
+		}
+	}
+
+	for name, header := range map[string]http.Header{
+		"other etag": {"If-None-Match": {`"41"`}},
+		"earlier":    {"If-Modified-Since": {time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)}},
+		// If-None-Match wins over If-Modified-Since
+		"both": {"If-None-Match": {`"41"`}, "If-Modified-Since": {lastMod}},
+	} {
+		w := h.getOrderIf("demo-user", tok, path, header)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, want 200", name, w.Code)
+		}
+	}
+
+	// once confirmed the old ETag is stale and the order may be cached
+	h.dispatch()
+	w = h.getOrderIf("demo-user", tok, path, http.Header{"If-None-Match": {etag}})
+	expectStatus(t, w, http.StatusOK)
+	var got orderResp
+	decode(t, w, &got)
+	if got.Status != "CONFIRMED" || w.Header().Get("ETag") == etag {
+		t.Fatalf("confirmed = %+v, ETag %q", got, w.Header().Get("ETag"))
+	}
+	if cc := w.Header().Get("Cache-Control"); cc != "private, max-age=3600, must-revalidate" {
+		t.Fatalf("final Cache-Control = %q", cc)
+	}
+
+	// a long-poll that sees no change answers 304 to a client holding the current version
+	pending := h.placeOrder("demo-user", tok, validOrder)
+	w = h.getOrder("demo-user", tok, pending)
+	w = h.getOrderIf("demo-user", tok, "/v1/orders/"+pending+"?wait=50ms", http.Header{"If-None-Match": {w.Header().Get("ETag")}})
+	expectStatus(t, w, http.StatusNotModified)
+}
+
+// CANCELLED is final for watchers, but order-gw may still confirm the order, so it is not cached.
+func TestCancelledOrderIsRevalidated(t *testing.T) {
+	h := newHarness(t)
+	tok := h.token("demo-user", "demo-user-secret")
+	id := h.placeOrder("demo-user", tok, validOrder)
+	expectStatus(t, h.cancelOrder("demo-user", tok, id, ""), http.StatusOK)
+
+	w := h.getOrder("demo-user", tok, id)
+	expectStatus(t, w, http.StatusOK)
+	if cc := w.Header().Get("Cache-Control"); cc != "private, no-cache" {
+		t.Fatalf("cancelled Cache-Control = %q", cc)
+	}
+}
+
+// A redelivered status event changes nothing, so pollers' cached copies stay valid.
+func TestRedeliveredStatusKeepsETag(t *testing.T) {
+	h := newHarness(t)
+	tok := h.token(demoClient, demoSecret)
+	id := h.placeOrder(demoClient, tok, validOrder)
+	h.dispatch()
+
+	w := h.getOrder(demoClient, tok, id)
+	expectStatus(t, w, http.StatusOK)
+	etag := w.Header().Get("ETag")
+
+	for range 3 {
+		if err := h.gw.publish(context.Background(), usecase.OrderStatusChangedMsg{OrderID: id, Status: "CONFIRMED"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w = h.getOrderIf(demoClient, tok, "/v1/orders/"+id, http.Header{"If-None-Match": {etag}})
+	expectStatus(t, w, http.StatusNotModified)
+}
@@ -0,0 +1,135 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	pb "github.com/aq2208/gorder-api/internal/generated/orderapipb"
+	"github.com/aq2208/gorder-api/internal/usecase"
+	"google.golang.org/grpc/codes"
+)
+
+func (h *harness) cancelOrder(clientID, token, id, ifMatch string) *httptest.ResponseRecorder {
+	h.t.Helper()
+	var header http.Header
+	if ifMatch != "" {
+		header = http.Header{"If-Match": {ifMatch}}
+	}
+	return h.send(http.MethodPost, "/v1/orders/"+id+":cancel", clientID, token, struct{}{}, header)
+}
+
+func TestOrderETagFollowsVersion(t *testing.T) {
+	h := newHarness(t)
+	tok := h.token("demo-user", "demo-user-secret")
+	id := h.placeOrder("demo-user", tok, validOrder)
+
+	w := h.getOrder("demo-user", tok, id)
+	expectStatus(t, w, http.StatusOK)
+	if got := w.Header().Get("ETag"); got != `"0"` {
+		t.Fatalf("new order ETag = %q, want \"0\"", got)
+	}
+
+	h.dispatch() // order-gw confirms: one update
+	w = h.getOrder("demo-user", tok, id)
+	if got := w.Header().Get("ETag"); got != `"1"` {
+		t.Fatalf("confirmed order ETag = %q, want \"1\"", got)
+	}
+	rec, _ := h.repo.GetByID(context.Background(), id)
+	if rec.Version != 1 || rec.Status != "CONFIRMED" {
+		t.Fatalf("stored order = %+v", rec)
+	}
+}
+
+func TestCancelHonoursIfMatch(t *testing.T) {
+	h := newHarness(t)
+	tok := h.token("demo-user", "demo-user-secret")
+	id := h.placeOrder("demo-user", tok, validOrder)
+
+	for _, stale := range []string{`"7"`, `W/"0"`, `0`} {
+		w := h.cancelOrder("demo-user", tok, id, stale)
+		expectStatus(t, w, http.StatusPreconditionFailed)
+	}
+
+	w := h.cancelOrder("demo-user", tok, id, `"3", "0"`)
+	expectStatus(t, w, http.StatusOK)
+	var got orderResp
+	decode(t, w, &got)
+	if got.Status != "CANCELLED" || w.Header().Get("ETag") != `"1"` {
+		t.Fatalf("cancelled = %+v, ETag %q", got, w.Header().Get("ETag"))
+	}
+
+	// the precondition is checked first; without one the state check answers
+	expectStatus(t, h.cancelOrder("demo-user", tok, id, `"0"`), http.StatusPreconditionFailed)
+	expectStatus(t, h.cancelOrder("demo-user", tok, id, ""), http.StatusConflict)
+	expectStatus(t, h.cancelOrder("demo-user", tok, "missing", "*"), http.StatusNotFound)
+
+	w = h.send(http.MethodPost, "/v1/orders/"+id+":archive", "demo-user", tok, struct{}{}, nil)
+	expectStatus(t, w, http.StatusNotFound)
+}
+
+// A client that read the order before order-gw's outcome landed must not cancel over it.
+func TestCancelOnStaleReadIsRejected(t *testing.T) {
+	h := newHarness(t)
+	tok := h.token("demo-user", "demo-user-secret")
+	id := h.placeOrder("demo-user", tok, validOrder)
+
+	etag := h.getOrder("demo-user", tok, id).Header().Get("ETag")
+	h.gw.Status = ""
+	h.dispatch()
+	rec, _ := h.repo.GetByID(context.Background(), id)
+	if ok, err := h.repo.UpdateStatus(context.Background(), id, rec.Version, "PROCESSING"); err != nil || !ok {
+		t.Fatalf("touch order: %v %v", ok, err)
+	}
+
+	w := h.cancelOrder("demo-user", tok, id, etag)
+	expectStatus(t, w, http.StatusPreconditionFailed)
+	if ok, _ := h.repo.UpdateStatus(context.Background(), id, rec.Version, "FAILED"); ok {
+		t.Fatal("update at a stale version was applied")
+	}
+
+	api := pb.NewOrderAPIClient(h.grpcConn())
+	_, err := api.CancelOrder(h.bearer(tok), &pb.CancelOrderRequest{Id: id, Etag: etag})
+	expectCode(t, err, codes.Aborted)
+	order, err := api.GetOrder(h.bearer(tok), &pb.GetOrderRequest{Id: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancelled, err := api.CancelOrder(h.bearer(tok), &pb.CancelOrderRequest{Id: id, Etag: order.GetEtag()})
+	if err != nil || cancelled.GetStatus() != "CANCELLED" || cancelled.GetEtag() == order.GetEtag() {
+		t.Fatalf("cancel at current etag = %v, %v", cancelled, err)
+	}
+}
+
+// Concurrent writers each apply exactly once: none overwrites another's update unseen.
+func TestConcurrentStatusWritesAreSerialised(t *testing.T) {
+	h := newHarness(t)
+	tok := h.token("demo-user", "demo-user-secret")
+	id := h.placeOrder("demo-user", tok, validOrder)
+
+	// distinct statuses, none the current one: every write is a change
+	statuses := []string{"PENDING", "CONFIRMED", "FAILED", "CANCELLED"}
+	writers := len(statuses)
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for _, status := range statuses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- usecase.ApplyStatus(context.Background(), h.repo, id, status)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("apply: %v", err)
+		}
+	}
+	rec, _ := h.repo.GetByID(context.Background(), id)
+	if rec.Version != int64(writers) {
+		t.Fatalf("version = %d after %d writes", rec.Version, writers)
+	}
+}
@@ -0,0 +1,198 @@
+package integration
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	domain "github.com/aq2208/gorder-api/internal/entity"
+)
+
+type sseEvent struct {
+	ID     string
+	Status string
+}
+
+// openEvents connects to the SSE endpoint on a real listener and returns the parsed events.
+func openEvents(t *testing.T, h *harness, token, id, lastEventID string) (<-chan sseEvent, *http.Response) {
+	t.Helper()
+	srv := httptest.NewServer(h.router)
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/v1/orders/"+id+"/events", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	out := make(chan sseEvent, 16)
+	go func() {
+		defer close(out)
+		var ev sseEvent
+		sc := bufio.NewScanner(resp.Body)
+		for sc.Scan() {
+			line := sc.Text()
+			switch {
+			case strings.HasPrefix(line, "id: "):
+				ev.ID = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "data: "):
+				var d struct {
+					Status string `json:"status"`
+				}
+				_ = json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &d)
+				ev.Status = d.Status
+			case line == "" && ev.Status != "":
+				out <- ev
+				ev = sseEvent{}
+			}
+		}
+	}()
+	return out, resp
+}
+
+func nextEvent(t *testing.T, events <-chan sseEvent) sseEvent {
+	t.Helper()
+	select {
+	case ev, ok := <-events:
+		if !ok {
+			t.Fatal("stream closed")
+		}
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event within 5s")
+	}
+	return sseEvent{}
+}
+
+func expectClosed(t *testing.T, events <-chan sseEvent) {
+	t.Helper()
+	select {
+	case ev, ok := <-events:
+		if ok {
+			t.Fatalf("unexpected event %+v", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream still open")
+	}
+}
+
+func TestOrderEvents_StreamsUntilFinal(t *testing.T) {
+	h := newHarness(t)
+	tok := h.token(demoClient, demoSecret)
+	id := h.placeOrder(demoClient, tok, validOrder)
+
+	events, resp := openEvents(t, h, tok, id, "")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("response = %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	if ev := nextEvent(t, events); ev.Status != string(domain.StatusProcessing) {
+		t.Fatalf("snapshot = %+v", ev)
+	}
+
+	h.dispatch()
+	ev := nextEvent(t, events)
+	if ev.Status != string(domain.StatusConfirmed) || ev.ID == "" {
+		t.Fatalf("event = %+v", ev)
+	}
+	expectClosed(t, events)
+}
+
+func TestOrderEvents_ResumeWithLastEventID(t *testing.T) {
+	h := newHarness(t)
+	tok := h.token(demoClient, demoSecret)
+	id := h.placeOrder(demoClient, tok, validOrder)
+
+	// the client saw an earlier event, then missed the change while disconnected
+	first, _ := h.events.PublishStatus(context.Background(), id, string(domain.StatusProcessing))
+	h.dispatch()
+
+	events, _ := openEvents(t, h, tok, id, first.ID)
+	if ev := nextEvent(t, events); ev.Status != string(domain.StatusConfirmed) {
+		t.Fatalf("replayed = %+v", ev)
+	}
+	expectClosed(t, events)
+
+	// already up to date with a final order: nothing to send
+	latest, _ := h.events.Since(context.Background(), id, first.ID)
+	events, _ = openEvents(t, h, tok, id, latest[len(latest)-1].ID)
+	expectClosed(t, events)
+}
+
+func TestOrderEvents_Authz(t *testing.T) {
+	h := newHarness(t)
+	svc := h.token(opsClient, opsSecret)
+	body := validOrder
+	body.UserID = "user-other"
+	id := h.placeOrder(opsClient, svc, body)
+
+	demo := h.token(demoClient, demoSecret)
+	if _, resp := openEvents(t, h, demo, id, ""); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("other user's order: %d", resp.StatusCode)
+	}
+	if _, resp := openEvents(t, h, demo, "does-not-exist", ""); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("unknown order: %d", resp.StatusCode)
+	}
+}
+
+// getOrderWait long-polls GET /v1/orders/:id?wait=...; the envelope is bound to the path only.
+func (h *harness) getOrderWait(clientID, token, id, wait string) *httptest.ResponseRecorder {
+	h.t.Helper()
+	path := "/v1/orders/" + id
+	return h.call(http.MethodGet, path+"?wait="+wait, token, h.seal(clientID, http.MethodGet, path, struct{}{}), nil)
+}
+
+func TestGetOrder_LongPoll(t *testing.T) {
+	h := newHarness(t)
+	tok := h.token(demoClient, demoSecret)
+	id := h.placeOrder(demoClient, tok, validOrder)
+
+	msgs := h.queue.drain()
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		for _, msg := range msgs {
+			_ = h.worker.HandleCreate(context.Background(), msg)
+		}
+	}()
+
+	start := time.Now()
+	w := h.getOrderWait(demoClient, tok, id, "5s")
+	expectStatus(t, w, http.StatusOK)
+	var got orderResp
+	decode(t, w, &got)
+	if got.Status != string(domain.StatusConfirmed) {
+		t.Fatalf("status = %s, want CONFIRMED", got.Status)
+	}
+	if d := time.Since(start); d > 3*time.Second {
+		t.Fatalf("returned after %s; should return on the status change", d)
+	}
+}
+
+func TestGetOrder_LongPollTimeout(t *testing.T) {
+	h := newHarness(t)
+	h.gw.Status = "" // order-gw never reports back
+	tok := h.token(demoClient, demoSecret)
+	id := h.placeOrder(demoClient, tok, validOrder)
+	h.dispatch()
+
+	w := h.getOrderWait(demoClient, tok, id, "200ms")
+	expectStatus(t, w, http.StatusOK)
+	var got orderResp
+	decode(t, w, &got)
+	if got.Status != string(domain.StatusProcessing) {
+		t.Fatalf("status = %s, want PROCESSING", got.Status)
+	}
+
+	expectStatus(t, h.getOrderWait(demoClient, tok, id, "soon"), http.StatusBadRequest)
+}
@@ -0,0 +1,304 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	domain "github.com/aq2208/gorder-api/internal/entity"
+	"github.com/aq2208/gorder-api/internal/usecase"
+)
+
+const (
+	demoClient = "demo-user"
+	demoSecret = "demo-user-secret"
+	demoUser   = "user-demo"
+
+	// opsClient is a service client registered by the suite (see TestMain) with the perms no built-in
+	// client has: every user's orders, webhooks and jobs.
+	opsClient = "ops-console"
+	opsSecret = "ops-console-secret"
+)
+
+func TestOrderLifecycle(t *testing.T) {
+	h := newHarness(t)
+	tok := h.token(demoClient, demoSecret)
+
+	// create: persisted as PROCESSING and enqueued once
+	w := h.createOrder(demoClient, tok, "lifecycle-1", validOrder)
+	expectStatus(t, w, http.StatusAccepted)
+	var created createResp
+	decode(t, w, &created)
+	if created.OrderID == "" || created.Status != string(domain.StatusProcessing) {
+		t.Fatalf("create response = %+v", created)
+	}
+
+	// retry with the same key: same order, nothing new enqueued
+	w = h.createOrder(demoClient, tok, "lifecycle-1", validOrder)
+	expectStatus(t, w, http.StatusAccepted)
+	var retried createResp
+	decode(t, w, &retried)
+	if retried.OrderID != created.OrderID {
+		t.Fatalf("retry returned order %s, want %s", retried.OrderID, created.OrderID)
+	}
+
+	// before dispatch the order is still PROCESSING
+	w = h.getOrder(demoClient, tok, created.OrderID)
+	expectStatus(t, w, http.StatusOK)
+	var got orderResp
+	decode(t, w, &got)
+	if got.Status != string(domain.StatusProcessing) || got.UserID != demoUser {
+		t.Fatalf("before dispatch: %+v", got)
+	}
+
+	// worker forwards to order-gw, which reports CONFIRMED through the status handler
+	if n := h.dispatch(); n != 1 {
+		t.Fatalf("dispatched %d messages, want 1", n)
+	}
+	if n := h.gw.callCount(); n != 1 {
+		t.Fatalf("order-gw called %d times, want 1", n)
+	}
+
+	w = h.getOrder(demoClient, tok, created.OrderID)
+	expectStatus(t, w, http.StatusOK)
+	decode(t, w, &got)
+	if got.ID != created.OrderID || got.Status != string(domain.StatusConfirmed) {
+		t.Fatalf("after dispatch: %+v", got)
+	}
+	if got.AmountCents != 1999 || got.Currency != "USD" {
+		t.Fatalf("amount = %d %s", got.AmountCents, got.Currency)
+	}
+}
+
+func TestOrderLifecycle_Rejected(t *testing.T) {
+	h := newHarness(t)
+	h.gw.Status = "REJECTED"
+	tok := h.token(demoClient, demoSecret)
+
+	id := h.placeOrder(demoClient, tok, validOrder)
+	h.dispatch()
+
+	w := h.getOrder(demoClient, tok, id)
+	expectStatus(t, w, http.StatusOK)
+	var got orderResp
+	decode(t, w, &got)
+	if got.Status != string(domain.StatusFailed) {
+		t.Fatalf("status = %s, want FAILED", got.Status)
+	}
+}
+
+func TestCreateOrder_KeylessRequestsAreIndependent(t *testing.T) {
+	h := newHarness(t)
+	tok := h.token(demoClient, demoSecret)
+
+	ids := map[string]bool{}
+	for range 3 {
+		ids[h.placeOrder(demoClient, tok, validOrder)] = true
+	}
+	if len(ids) != 3 {
+		t.Fatalf("got %d distinct orders, want 3", len(ids))
+	}
+	if n := h.dispatch(); n != 3 {
+		t.Fatalf("dispatched %d messages, want 3", n)
+	}
+}
+
+func TestCreateOrder_PublishFailure(t *testing.T) {
+	h := newHarness(t)
+	h.queue.Fail = errBrokerDown
+	tok := h.token(demoClient, demoSecret)
+
+	w := h.createOrder(demoClient, tok, "publish-fail", validOrder)
+	expectStatus(t, w, http.StatusInternalServerError)
+
+	// the row was written before the publish; it stays PROCESSING and the key is not remembered
+	rec, err := h.repo.GetByIdemKey(context.Background(), demoClient, demoUser, "publish-fail")
+	if err != nil {
+		t.Fatalf("order row: %v", err)
+	}
+	if rec.Status != string(domain.StatusProcessing) {
+		t.Fatalf("status = %s, want PROCESSING", rec.Status)
+	}
+	if _, ok, _ := h.idem.Recall(context.Background(), usecase.ScopedIdempotencyKey(demoClient, demoUser, "publish-fail")); ok {
+		t.Fatal("idempotency key remembered after a failed publish")
+	}
+	if n := h.dispatch(); n != 0 {
+		t.Fatalf("dispatched %d messages, want 0", n)
+	}
+
+	// the key was released: a retry publishes the stored order instead of creating a second one
+	h.queue.Fail = nil
+	w = h.createOrder(demoClient, tok, "publish-fail", validOrder)
+	expectStatus(t, w, http.StatusAccepted)
+	var retried createResp
+	decode(t, w, &retried)
+	if retried.OrderID != rec.ID {
+		t.Fatalf("retry created order %s, want the stored %s", retried.OrderID, rec.ID)
+	}
+	if n := h.dispatch(); n != 1 {
+		t.Fatalf("dispatched %d messages, want 1", n)
+	}
+	if got, _ := h.repo.GetByID(context.Background(), rec.ID); got.Status != string(domain.StatusConfirmed) {
+		t.Fatalf("status = %s, want CONFIRMED", got.Status)
+	}
+}
+
+func TestCreateOrder_DuplicateInFlightKey(t *testing.T) {
+	h := newHarness(t)
+	tok := h.token(demoClient, demoSecret)
+
+	// another request holds the lock but has not finished yet
+	if ok, err := h.idem.TryLock(context.Background(), usecase.ScopedIdempotencyKey(demoClient, demoUser, "in-flight")); err != nil || !ok {
+		t.Fatalf("lock: %v %v", ok, err)
+	}
+
+	w := h.createOrder(demoClient, tok, "in-flight", validOrder)
+	expectStatus(t, w, http.StatusConflict)
+	if n := h.dispatch(); n != 0 {
+		t.Fatalf("dispatched %d messages, want 0", n)
+	}
+}
+
+// Once the key has expired from the idempotency store, a retry still replays the stored order, whatever
+// its status, instead of creating a second one.
+func TestCreateOrder_RetryAfterKeyExpired(t *testing.T) {
+	h := newHarness(t)
+	tok := h.token(demoClient, demoSecret)
+	h.idem.SetTTL(time.Millisecond)
+
+	w := h.createOrder(demoClient, tok, "expired", validOrder)
+	expectStatus(t, w, http.StatusAccepted)
+	var first createResp
+	decode(t, w, &first)
+	if n := h.dispatch(); n != 1 {
+		t.Fatalf("dispatched %d, want 1", n)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	w = h.createOrder(demoClient, tok, "expired", validOrder)
+	expectStatus(t, w, http.StatusAccepted)
+	var retried createResp
+	decode(t, w, &retried)
+	if retried.OrderID != first.OrderID || retried.Status != string(domain.StatusConfirmed) {
+		t.Fatalf("retry = %+v, want %s CONFIRMED", retried, first.OrderID)
+	}
+	if n := h.dispatch(); n != 0 {
+		t.Fatalf("dispatched %d on retry, want 0", n)
+	}
+
+	// the repo refuses a second order under the same key outright
+	dup := &usecase.OrderRecord{ID: "dup", UserID: demoUser, ClientID: demoClient, IdempotencyKey: "expired",
+		Status: string(domain.StatusProcessing), AmountCents: 1, Currency: "USD", ItemsJSON: "[]"}
+	if err := h.repo.Create(context.Background(), dup); !errors.Is(err, usecase.ErrDuplicate) {
+		t.Fatalf("second order under the key: %v, want %v", err, usecase.ErrDuplicate)
+	}
+}
+
+// Idempotency keys belong to the caller: the same key from another client is a different request.
+func TestCreateOrder_KeysScopedToCaller(t *testing.T) {
+	h := newHarness(t)
+	demo := h.token(demoClient, demoSecret)
+	svc := h.token(opsClient, opsSecret)
+	body := validOrder
+	body.UserID = demoUser
+
+	w := h.createOrder(demoClient, demo, "shared-key", validOrder)
+	expectStatus(t, w, http.StatusAccepted)
+	var mine createResp
+	decode(t, w, &mine)
+
+	w = h.createOrder(opsClient, svc, "shared-key", body)
+	expectStatus(t, w, http.StatusAccepted)
+	var theirs createResp
+	decode(t, w, &theirs)
+	if theirs.OrderID == mine.OrderID {
+		t.Fatalf("another client recalled order %s by its key", mine.OrderID)
+	}
+
+	// each caller still replays its own key
+	w = h.createOrder(demoClient, demo, "shared-key", validOrder)
+	var again createResp
+	decode(t, w, &again)
+	if again.OrderID != mine.OrderID {
+		t.Fatalf("replay returned %s, want %s", again.OrderID, mine.OrderID)
+	}
+	if n := h.dispatch(); n != 2 {
+		t.Fatalf("dispatched %d, want 2", n)
+	}
+}
+
+func TestCreateOrder_ReplayedEnvelope(t *testing.T) {
+	h := newHarness(t)
+	tok := h.token(demoClient, demoSecret)
+
+	env := h.seal(demoClient, http.MethodPost, "/v1/orders", validOrder)
+	expectStatus(t, h.call(http.MethodPost, "/v1/orders", tok, env, nil), http.StatusAccepted)
+	expectStatus(t, h.call(http.MethodPost, "/v1/orders", tok, env, nil), http.StatusConflict)
+
+	if n := h.dispatch(); n != 1 {
+		t.Fatalf("dispatched %d messages, want 1", n)
+	}
+}
+
+func TestCreateOrder_Validation(t *testing.T) {
+	h := newHarness(t)
+	tok := h.token(demoClient, demoSecret)
+
+	bad := validOrder
+	bad.Amount.Cents = 0
+	expectStatus(t, h.createOrder(demoClient, tok, "", bad), http.StatusBadRequest)
+
+	// an end-user token cannot create orders for someone else
+	other := validOrder
+	other.UserID = "user-someone-else"
+	expectStatus(t, h.createOrder(demoClient, tok, "", other), http.StatusForbidden)
+}
+
+func TestAuth_Failures(t *testing.T) {
+	h := newHarness(t)
+
+	// bad credentials
+	w := h.serve(formRequest("/v1/token", "demo-user", "wrong"))
+	expectStatus(t, w, http.StatusUnauthorized)
+
+	// missing / invalid bearer token
+	env := h.seal(demoClient, http.MethodPost, "/v1/orders", validOrder)
+	expectStatus(t, h.call(http.MethodPost, "/v1/orders", "not-a-jwt", env, nil), http.StatusUnauthorized)
+
+	// read-only client
+	tok := h.token("svc-analytics", "ana-secret")
+	w = h.createOrder("svc-analytics", tok, "", validOrder)
+	expectStatus(t, w, http.StatusForbidden)
+}
+
+func TestGetOrder_Ownership(t *testing.T) {
+	h := newHarness(t)
+
+	// created by a service client on behalf of another user
+	svc := h.token(opsClient, opsSecret)
+	body := validOrder
+	body.UserID = "user-other"
+	id := h.placeOrder(opsClient, svc, body)
+
+	demo := h.token(demoClient, demoSecret)
+	// another user's order is indistinguishable from a missing one
+	other := h.getOrder(demoClient, demo, id)
+	missing := h.getOrder(demoClient, demo, "does-not-exist")
+	expectStatus(t, other, http.StatusNotFound)
+	expectStatus(t, missing, http.StatusNotFound)
+	if other.Body.String() != missing.Body.String() {
+		t.Fatalf("bodies differ: %s vs %s", other.Body, missing.Body)
+	}
+	expectStatus(t, h.getOrder(opsClient, svc, id), http.StatusOK)
+	// built-in service clients keep plain orders.read and see no one's orders without orders.read.all
+	for _, c := range []struct{ id, secret string }{{"simulated-client", "simulated-client-secret"}, {"svc-order-gw", "gw-secret"}, {"svc-analytics", "ana-secret"}} {
+		expectStatus(t, h.getOrder(c.id, h.token(c.id, c.secret), id), http.StatusNotFound)
+	}
+
+	// a tenant-bound caller does not see untenanted orders, even its own user's
+	body.UserID = "user-demo"
+	id = h.placeOrder(opsClient, svc, body)
+	expectStatus(t, h.getOrder(demoClient, demo, id), http.StatusNotFound)
+}
@@ -0,0 +1,89 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	domain "github.com/aq2208/gorder-api/internal/entity"
+	"github.com/aq2208/gorder-api/internal/usecase"
+)
+
+// reap runs one reaper pass with a zero SLA, so every PROCESSING order counts as stuck.
+func (h *harness) reap(maxAttempts int) usecase.ReapResult {
+	h.t.Helper()
+	uc := usecase.NewReapStuckOrders(h.repo, h.cache, h.queue, usecase.WithSLA(0), usecase.WithMaxAttempts(maxAttempts))
+	res, err := uc.Execute(context.Background())
+	if err != nil {
+		h.t.Fatalf("reap: %v", err)
+	}
+	return res
+}
+
+func TestReaper_RedispatchesLostPublish(t *testing.T) {
+	h := newHarness(t)
+	tok := h.token(demoClient, demoSecret)
+
+	h.queue.Fail = errBrokerDown
+	expectStatus(t, h.createOrder(demoClient, tok, "reaper-lost", validOrder), http.StatusInternalServerError)
+	h.queue.Fail = nil
+
+	rec, err := h.repo.GetByIdemKey(context.Background(), demoClient, demoUser, "reaper-lost")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res := h.reap(3); res.Redispatched != 1 || res.Failed != 0 {
+		t.Fatalf("reap = %+v, want 1 re-dispatch", res)
+	}
+	if n := h.dispatch(); n != 1 {
+		t.Fatalf("dispatched %d messages, want 1", n)
+	}
+
+	w := h.getOrder(demoClient, tok, rec.ID)
+	expectStatus(t, w, http.StatusOK)
+	var got orderResp
+	decode(t, w, &got)
+	if got.Status != string(domain.StatusConfirmed) {
+		t.Fatalf("status = %s, want CONFIRMED", got.Status)
+	}
+
+	// confirmed orders are no longer stuck
+	if res := h.reap(3); res != (usecase.ReapResult{}) {
+		t.Fatalf("second reap = %+v, want nothing", res)
+	}
+}
+
+func TestReaper_FailsAfterMaxAttempts(t *testing.T) {
+	h := newHarness(t)
+	h.gw.Status = "" // order-gw accepts but never reports a status
+	tok := h.token(demoClient, demoSecret)
+
+	id := h.placeOrder(demoClient, tok, validOrder)
+	h.dispatch()
+
+	for i := 0; i < 2; i++ {
+		if res := h.reap(2); res.Redispatched != 1 {
+			t.Fatalf("reap %d = %+v, want 1 re-dispatch", i, res)
+		}
+		h.dispatch()
+	}
+	if res := h.reap(2); res.Failed != 1 || res.Redispatched != 0 {
+		t.Fatalf("final reap = %+v, want 1 failed", res)
+	}
+	if n := h.gw.callCount(); n != 3 {
+		t.Fatalf("order-gw called %d times, want 3", n)
+	}
+
+	w := h.getOrder(demoClient, tok, id)
+	expectStatus(t, w, http.StatusOK)
+	var got orderResp
+	decode(t, w, &got)
+	if got.Status != string(domain.StatusFailed) || !strings.Contains(got.Failure, "2 re-dispatches") {
+		t.Fatalf("after reaper: %+v", got)
+	}
+	if st, _ := h.cache.GetStatus(context.Background(), id); st != string(domain.StatusFailed) {
+		t.Fatalf("cached status = %q, want FAILED", st)
+	}
+}
@@ -0,0 +1,151 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/aq2208/gorder-api/internal/adapter/memory"
+	domain "github.com/aq2208/gorder-api/internal/entity"
+	"github.com/aq2208/gorder-api/internal/usecase"
+)
+
+// gatewayRecords stands in for order-gw's ListOrders.
+type gatewayRecords map[string]usecase.GatewayOrder
+
+func (g gatewayRecords) ListOrders(ctx context.Context, ids []string) (map[string]usecase.GatewayOrder, error) {
+	out := map[string]usecase.GatewayOrder{}
+	for _, id := range ids {
+		if o, ok := g[id]; ok {
+			out[id] = o
+		}
+	}
+	return out, nil
+}
+
+func TestReconcile(t *testing.T) {
+	h := newHarness(t)
+	tok := h.token(demoClient, demoSecret)
+
+	h.gw.Status = "" // status events lost: everything stays PROCESSING
+	lost := h.placeOrder(demoClient, tok, validOrder)
+	drifted := h.placeOrder(demoClient, tok, validOrder)
+	missing := h.placeOrder(demoClient, tok, validOrder)
+	h.dispatch()
+	h.gw.Status = "CONFIRMED"
+	confirmed := h.placeOrder(demoClient, tok, validOrder)
+	inSync := h.placeOrder(demoClient, tok, validOrder)
+	h.dispatch()
+
+	gw := gatewayRecords{
+		lost:      {OrderID: lost, Status: "CONFIRMED", AmountCents: 1999, Currency: "USD"},
+		drifted:   {OrderID: drifted, Status: "CONFIRMED", AmountCents: 2999, Currency: "USD"},
+		confirmed: {OrderID: confirmed, Status: "FAILED", AmountCents: 1999, Currency: "USD"},
+		inSync:    {OrderID: inSync, Status: "CONFIRMED", AmountCents: 1999, Currency: "USD"},
+	}
+	reports := memory.NewDiscrepancyRepo()
+	uc := usecase.NewReconcileOrders(h.repo, h.repo, h.cache, gw, reports,
+		usecase.WithWindow(time.Hour), usecase.WithSettle(0), usecase.WithPageSize(2), usecase.WithAutoCorrect(true))
+
+	rep, err := uc.Execute(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Checked != 5 || rep.Discrepancies != 5 || rep.Corrected != 1 {
+		t.Fatalf("report = %+v, want checked=5 discrepancies=5 corrected=1", rep)
+	}
+
+	got := map[string][]string{}
+	for _, d := range reports.All() {
+		if d.RunID != rep.RunID {
+			t.Fatalf("row from run %s, want %s", d.RunID, rep.RunID)
+		}
+		got[d.OrderID] = append(got[d.OrderID], d.Kind+"/"+d.Action)
+	}
+	for _, kinds := range got {
+		sort.Strings(kinds)
+	}
+	want := map[string][]string{
+		lost:      {"status_mismatch/corrected"},
+		drifted:   {"amount_mismatch/reported", "status_mismatch/reported"}, // amount differs: not safe to fix
+		missing:   {"missing_at_gateway/reported"},
+		confirmed: {"status_mismatch/reported"}, // already final here: needs a human
+	}
+	for id, kinds := range want {
+		if !slices.Equal(got[id], kinds) {
+			t.Errorf("order %s: %v, want %v", id, got[id], kinds)
+		}
+	}
+	if len(got[inSync]) != 0 {
+		t.Errorf("in-sync order reported: %v", got[inSync])
+	}
+
+	status := func(id string) string {
+		w := h.getOrder(demoClient, tok, id)
+		expectStatus(t, w, http.StatusOK)
+		var o orderResp
+		decode(t, w, &o)
+		return o.Status
+	}
+	if s := status(lost); s != string(domain.StatusConfirmed) {
+		t.Errorf("corrected order status = %s, want CONFIRMED", s)
+	}
+	if s := status(drifted); s != string(domain.StatusProcessing) {
+		t.Errorf("drifted order status = %s, want PROCESSING (unchanged)", s)
+	}
+	if s := status(confirmed); s != string(domain.StatusConfirmed) {
+		t.Errorf("confirmed order status = %s, want CONFIRMED (unchanged)", s)
+	}
+}
+
+func TestReconcile_ReportOnly(t *testing.T) {
+	h := newHarness(t)
+	tok := h.token(demoClient, demoSecret)
+	h.gw.Status = ""
+	id := h.placeOrder(demoClient, tok, validOrder)
+	h.dispatch()
+
+	reports := memory.NewDiscrepancyRepo()
+	gw := gatewayRecords{id: {OrderID: id, Status: "CONFIRMED", AmountCents: 1999, Currency: "USD"}}
+	uc := usecase.NewReconcileOrders(h.repo, h.repo, h.cache, gw, reports, usecase.WithSettle(0))
+
+	rep, err := uc.Execute(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Discrepancies != 1 || rep.Corrected != 0 {
+		t.Fatalf("report = %+v, want 1 discrepancy, 0 corrected", rep)
+	}
+	if rec, _ := h.repo.GetByID(context.Background(), id); rec.Status != string(domain.StatusProcessing) {
+		t.Fatalf("status = %s, want PROCESSING without auto-correct", rec.Status)
+	}
+}
+
+// A status order-gw reports that we cannot translate is never taken for a failure.
+func TestReconcile_UnknownGatewayStatus(t *testing.T) {
+	h := newHarness(t)
+	tok := h.token(demoClient, demoSecret)
+	h.gw.Status = ""
+	id := h.placeOrder(demoClient, tok, validOrder)
+	h.dispatch()
+
+	reports := memory.NewDiscrepancyRepo()
+	gw := gatewayRecords{id: {OrderID: id, Status: usecase.GatewayStatusUnknown, RawStatus: "ON_HOLD", AmountCents: 1999, Currency: "USD"}}
+	uc := usecase.NewReconcileOrders(h.repo, h.repo, h.cache, gw, reports, usecase.WithSettle(0), usecase.WithAutoCorrect(true))
+
+	rep, err := uc.Execute(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := reports.All()
+	if rep.Corrected != 0 || len(rows) != 1 || rows[0].Kind != usecase.DiscrepancyUnknown ||
+		rows[0].Action != usecase.ActionReported || rows[0].GatewayStatus != "ON_HOLD" {
+		t.Fatalf("report = %+v, rows %+v", rep, rows)
+	}
+	if rec, _ := h.repo.GetByID(context.Background(), id); rec.Status != string(domain.StatusProcessing) {
+		t.Fatalf("status = %s, want PROCESSING", rec.Status)
+	}
+}
@@ -0,0 +1,290 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aq2208/gorder-api/internal/adapter/webhook"
+	"github.com/aq2208/gorder-api/internal/usecase"
+)
+
+// receiver is a subscriber endpoint that records what it gets and answers with Status.
+type receiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	Status   int
+	requests []received
+}
+
+type received struct {
+	header http.Header
+	body   []byte
+}
+
+func newReceiver(t *testing.T) *receiver {
+	r := &receiver{Status: http.StatusOK}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		r.requests = append(r.requests, received{header: req.Header.Clone(), body: body})
+		status := r.Status
+		r.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *receiver) received() []received {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]received(nil), r.requests...)
+}
+
+func (r *receiver) respond(status int) {
+	r.mu.Lock()
+	r.Status = status
+	r.mu.Unlock()
+}
+
+type subscriptionResp struct {
+	ID     string   `json:"id"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+}
+
+type deliveryResp struct {
+	ID        string `json:"id"`
+	EventID   string `json:"event_id"`
+	EventType string `json:"event_type"`
+	OrderID   string `json:"order_id"`
+	Status    string `json:"status"`
+	Attempts  int    `json:"attempts"`
+	Log       []struct {
+		StatusCode int    `json:"status_code"`
+		Error      string `json:"error"`
+	} `json:"attempts_log"`
+}
+
+func (h *harness) subscribe(clientID, token, url string, events ...string) subscriptionResp {
+	h.t.Helper()
+	w := h.send(http.MethodPost, "/v1/webhooks", clientID, token, map[string]any{"url": url, "events": events}, nil)
+	expectStatus(h.t, w, http.StatusCreated)
+	var s subscriptionResp
+	decode(h.t, w, &s)
+	return s
+}
+
+func (h *harness) deliveries(clientID, token, status string) []deliveryResp {
+	h.t.Helper()
+	path := "/v1/webhooks/deliveries"
+	env := h.seal(clientID, http.MethodGet, path, struct{}{})
+	w := h.call(http.MethodGet, path+"?status="+status, token, env, nil)
+	expectStatus(h.t, w, http.StatusOK)
+	var out struct {
+		Deliveries []deliveryResp `json:"deliveries"`
+	}
+	decode(h.t, w, &out)
+	return out.Deliveries
+}
+
+// deliver runs the delivery use case once, with no backoff so retries are due at once.
+func (h *harness) deliver(maxAttempts int) usecase.DeliverResult {
+	h.t.Helper()
+	uc := usecase.NewDeliverWebhooks(h.hooks, webhook.NewHTTPSender(2*time.Second, cfg.Webhooks.AllowPrivate),
+		usecase.WithDeliveryAttempts(maxAttempts), usecase.WithBackoff(0, 0))
+	res, err := uc.Execute(context.Background())
+	if err != nil {
+		h.t.Fatalf("deliver: %v", err)
+	}
+	return res
+}
+
+func TestWebhooks_SignedDeliveryOnConfirm(t *testing.T) {
+	h := newHarness(t)
+	rcv := newReceiver(t)
+	tok := h.token(demoClient, demoSecret)
+	sub := h.subscribe(demoClient, tok, rcv.URL+"/hooks", usecase.WebhookOrderConfirmed)
+	if sub.Secret == "" || sub.ID == "" {
+		t.Fatalf("subscription = %+v", sub)
+	}
+
+	id := h.placeOrder(demoClient, tok, validOrder)
+	h.dispatch()
+
+	// the same status event again (Kafka redelivery) must not notify twice
+	_ = h.gw.publish(context.Background(), usecase.OrderStatusChangedMsg{OrderID: id, Status: "CONFIRMED"})
+
+	if res := h.deliver(3); res.Delivered != 1 {
+		t.Fatalf("result = %+v, want 1 delivered", res)
+	}
+	got := rcv.received()
+	if len(got) != 1 {
+		t.Fatalf("receiver got %d requests, want 1", len(got))
+	}
+	req := got[0]
+	ts, _ := strconv.ParseInt(req.header.Get(webhook.HeaderTimestamp), 10, 64)
+	if !webhook.Verify(sub.Secret, ts, req.body, req.header.Get(webhook.HeaderSignature)) {
+		t.Fatal("signature does not verify")
+	}
+	if req.header.Get(webhook.HeaderEvent) != usecase.WebhookOrderConfirmed {
+		t.Fatalf("event header = %q", req.header.Get(webhook.HeaderEvent))
+	}
+	var payload usecase.WebhookPayload
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.ID != req.header.Get(webhook.HeaderID) || payload.Data.OrderID != id || payload.Data.Status != "CONFIRMED" {
+		t.Fatalf("payload = %+v", payload)
+	}
+
+	ds := h.deliveries(demoClient, tok, usecase.DeliveryDelivered)
+	if len(ds) != 1 || ds[0].OrderID != id || ds[0].Attempts != 1 {
+		t.Fatalf("deliveries = %+v", ds)
+	}
+}
+
+func TestWebhooks_RetryFailAndRedeliver(t *testing.T) {
+	h := newHarness(t)
+	h.gw.Status = "REJECTED"
+	rcv := newReceiver(t)
+	rcv.respond(http.StatusInternalServerError)
+	tok := h.token(demoClient, demoSecret)
+	h.subscribe(demoClient, tok, rcv.URL, usecase.WebhookOrderConfirmed, usecase.WebhookOrderFailed)
+
+	h.placeOrder(demoClient, tok, validOrder)
+	h.dispatch()
+
+	if res := h.deliver(2); res.Retried != 1 {
+		t.Fatalf("first run = %+v, want 1 retried", res)
+	}
+	if res := h.deliver(2); res.Failed != 1 {
+		t.Fatalf("second run = %+v, want 1 failed", res)
+	}
+	if res := h.deliver(2); res != (usecase.DeliverResult{}) {
+		t.Fatalf("failed delivery sent again: %+v", res)
+	}
+
+	failed := h.deliveries(demoClient, tok, usecase.DeliveryFailed)
+	if len(failed) != 1 || failed[0].EventType != usecase.WebhookOrderFailed {
+		t.Fatalf("failed deliveries = %+v", failed)
+	}
+	id := failed[0].ID
+
+	// the receiver is fixed; the client asks for the delivery again
+	rcv.respond(http.StatusNoContent)
+	w := h.send(http.MethodPost, "/v1/webhooks/deliveries/"+id+"/redeliver", demoClient, tok, struct{}{}, nil)
+	expectStatus(t, w, http.StatusAccepted)
+	if res := h.deliver(2); res.Delivered != 1 {
+		t.Fatalf("after redeliver = %+v", res)
+	}
+
+	w = h.send(http.MethodGet, "/v1/webhooks/deliveries/"+id, demoClient, tok, struct{}{}, nil)
+	expectStatus(t, w, http.StatusOK)
+	var d deliveryResp
+	decode(t, w, &d)
+	if d.Status != usecase.DeliveryDelivered || len(d.Log) != 3 {
+		t.Fatalf("delivery = %+v", d)
+	}
+	if d.Log[0].StatusCode != http.StatusInternalServerError || d.Log[2].StatusCode != http.StatusNoContent {
+		t.Fatalf("attempt log = %+v", d.Log)
+	}
+
+	// every attempt of the same event carries the same id
+	got := rcv.received()
+	if len(got) != 3 || got[0].header.Get(webhook.HeaderID) != got[2].header.Get(webhook.HeaderID) {
+		t.Fatalf("receiver got %d requests", len(got))
+	}
+}
+
+func TestWebhooks_ClientScopeAndValidation(t *testing.T) {
+	h := newHarness(t)
+	rcv := newReceiver(t)
+	demo := h.token(demoClient, demoSecret)
+	svc := h.token(opsClient, opsSecret)
+
+	// unknown event, bad URL, missing permission
+	w := h.send(http.MethodPost, "/v1/webhooks", demoClient, demo, map[string]any{"url": rcv.URL, "events": []string{"order.shipped"}}, nil)
+	expectStatus(t, w, http.StatusBadRequest)
+	w = h.send(http.MethodPost, "/v1/webhooks", demoClient, demo, map[string]any{"url": "not a url", "events": []string{usecase.WebhookOrderConfirmed}}, nil)
+	expectStatus(t, w, http.StatusBadRequest)
+	ana := h.token("svc-analytics", "ana-secret")
+	w = h.send(http.MethodGet, "/v1/webhooks", "svc-analytics", ana, struct{}{}, nil)
+	expectStatus(t, w, http.StatusForbidden)
+
+	// orders notify the subscriptions of the client that created them only
+	sub := h.subscribe(demoClient, demo, rcv.URL, usecase.WebhookOrderConfirmed)
+	body := validOrder
+	body.UserID = "user-other"
+	h.placeOrder(opsClient, svc, body)
+	h.dispatch()
+	if res := h.deliver(3); res != (usecase.DeliverResult{}) {
+		t.Fatalf("another client's order was delivered: %+v", res)
+	}
+
+	// subscriptions are listed without secrets and only to their owner
+	w = h.send(http.MethodGet, "/v1/webhooks", opsClient, svc, struct{}{}, nil)
+	expectStatus(t, w, http.StatusOK)
+	var list struct {
+		Subscriptions []subscriptionResp `json:"subscriptions"`
+	}
+	decode(t, w, &list)
+	if len(list.Subscriptions) != 0 {
+		t.Fatalf("ops client sees %+v", list.Subscriptions)
+	}
+	w = h.send(http.MethodGet, "/v1/webhooks", demoClient, demo, struct{}{}, nil)
+	decode(t, w, &list)
+	if len(list.Subscriptions) != 1 || list.Subscriptions[0].Secret != "" {
+		t.Fatalf("demo sees %+v", list.Subscriptions)
+	}
+
+	w = h.send(http.MethodDelete, "/v1/webhooks/"+sub.ID, opsClient, svc, struct{}{}, nil)
+	expectStatus(t, w, http.StatusNotFound)
+	w = h.send(http.MethodDelete, "/v1/webhooks/"+sub.ID, demoClient, demo, struct{}{}, nil)
+	expectStatus(t, w, http.StatusNoContent)
+}
+
+// Outside local development webhooks cannot be aimed at the API's own network (SSRF).
+func TestWebhooks_RefuseInternalAddresses(t *testing.T) {
+	h := newHarness(t)
+	ctx := usecase.WithPrincipal(context.Background(), usecase.Principal{ClientID: demoClient})
+	uc := usecase.NewManageWebhooks(h.hooks)
+	for _, u := range []string{
+		"https://127.0.0.1/hooks",
+		"https://10.1.2.3/hooks",
+		"https://169.254.169.254/latest/meta-data",
+		"https://[::1]:8443/hooks",
+		"https://localhost/hooks",
+	} {
+		if _, err := uc.Subscribe(ctx, u, []string{usecase.WebhookOrderConfirmed}); !errors.Is(err, usecase.ErrInvalidWebhook) {
+			t.Fatalf("subscribe %s: err = %v, want ErrInvalidWebhook", u, err)
+		}
+	}
+	if _, err := uc.Subscribe(ctx, "https://hooks.example.com/orders", []string{usecase.WebhookOrderConfirmed}); err != nil {
+		t.Fatalf("public host refused: %v", err)
+	}
+
+	// a name that resolves to loopback is refused at dial time
+	rcv := newReceiver(t)
+	sender := webhook.NewHTTPSender(2*time.Second, false)
+	for _, u := range []string{rcv.URL, strings.Replace(rcv.URL, "127.0.0.1", "localhost", 1)} {
+		_, err := sender.Send(context.Background(), u, "secret", usecase.WebhookDelivery{Payload: []byte("{}")})
+		if !errors.Is(err, webhook.ErrBlockedAddress) {
+			t.Fatalf("send %s: err = %v, want ErrBlockedAddress", u, err)
+		}
+	}
+	if got := rcv.received(); len(got) != 0 {
+		t.Fatalf("receiver got %d requests", len(got))
+	}
+}